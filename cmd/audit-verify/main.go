@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"engine/config"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/database"
+	sharedLogger "engine/pkg/shared/logger"
+)
+
+// audit-verify walks the audit_events hash chain and reports the first broken link.
+//
+//	go run ./cmd/audit-verify -checkpoints-out checkpoints.jsonl -every 1000
+//	go run ./cmd/audit-verify -checkpoints-in checkpoints.jsonl
+func main() {
+	checkpointsOut := flag.String("checkpoints-out", "", "write signed checkpoints as JSON Lines to this file")
+	checkpointsIn := flag.String("checkpoints-in", "", "verify previously exported checkpoints from this file")
+	every := flag.Int("every", 1000, "export a checkpoint every N events")
+	flag.Parse()
+
+	logger := sharedLogger.NewLogger()
+	config.LoadEnv(logger)
+
+	dbConn := config.LoadDB(logger)
+	defer database.CloseDB(dbConn, logger)
+
+	auditUsecase := usecases.NewAuditUsecase(repositories.NewAuditRepository(dbConn))
+
+	var onCheckpoint func(checkpoint dtos.AuditCheckpoint) error
+	if *checkpointsOut != "" {
+		file, err := os.Create(*checkpointsOut)
+		if err != nil {
+			logger.Fatalf("Fail to create checkpoint file: %v", err)
+		}
+		defer file.Close()
+
+		encoder := json.NewEncoder(file)
+		onCheckpoint = func(checkpoint dtos.AuditCheckpoint) error {
+			return encoder.Encode(checkpoint)
+		}
+	}
+
+	report, err := auditUsecase.VerifyChain(*every, onCheckpoint)
+	if err != nil {
+		logger.Fatalf("Fail to verify audit chain: %v", err)
+	}
+
+	valid := report.Valid
+	if *checkpointsIn != "" {
+		valid = verifyCheckpoints(*checkpointsIn, auditUsecase.VerifyCheckpoint, logger) && valid
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	os.Stdout.Write(append(output, '\n'))
+
+	if !valid {
+		logger.Error("Audit chain is broken")
+		os.Exit(1)
+	}
+	logger.Info("Audit chain is valid")
+}
+
+func verifyCheckpoints(path string, verify func(checkpoint dtos.AuditCheckpoint) error, logger *logrus.Logger) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		logger.Errorf("Fail to open checkpoint file: %v", err)
+		return false
+	}
+	defer file.Close()
+
+	valid := true
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		checkpoint := dtos.AuditCheckpoint{}
+		err = json.Unmarshal(scanner.Bytes(), &checkpoint)
+		if err != nil {
+			logger.Errorf("Fail to parse checkpoint: %v", err)
+			valid = false
+			continue
+		}
+
+		err = verify(checkpoint)
+		if err != nil {
+			logger.Errorf("%v", err)
+			valid = false
+		}
+	}
+
+	if scanner.Err() != nil {
+		logger.Errorf("Fail to read checkpoint file: %v", scanner.Err())
+		return false
+	}
+
+	return valid
+}
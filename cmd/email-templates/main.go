@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	sharedLogger "engine/pkg/shared/logger"
+	"engine/pkg/shared/template/emails"
+)
+
+// email-templates renders every email template in every locale with its
+// fixture data and fails on missing translations or variables. With -out the
+// rendered parts are written as <out>/<locale>/<name>.html and .txt.
+//
+//	go run ./cmd/email-templates
+//	go run ./cmd/email-templates -out rendered
+func main() {
+	out := flag.String("out", "", "write the rendered templates to this directory")
+	flag.Parse()
+
+	logger := sharedLogger.NewLogger()
+
+	err := emails.Check()
+	if err != nil {
+		os.Stdout.WriteString(err.Error() + "\n")
+		logger.Error("Email templates are broken")
+		os.Exit(1)
+	}
+
+	if *out != "" {
+		for _, locale := range emails.Locales {
+			err = os.MkdirAll(filepath.Join(*out, locale), 0o755)
+			if err != nil {
+				logger.Fatalf("Fail to create output directory: %v", err)
+			}
+
+			for _, name := range emails.Names() {
+				msg, err := emails.Preview(name, locale)
+				if err != nil {
+					logger.Fatalf("Fail to render %s/%s: %v", locale, name, err)
+				}
+
+				base := filepath.Join(*out, locale, name)
+				err = os.WriteFile(base+".html", []byte(msg.HTMLBody), 0o644)
+				if err == nil {
+					err = os.WriteFile(base+".txt", []byte("Subject: "+msg.Subject+"\n\n"+msg.TextBody), 0o644)
+				}
+				if err != nil {
+					logger.Fatalf("Fail to write %s: %v", base, err)
+				}
+			}
+		}
+	}
+
+	logger.Infof("%d email templates render in %d locales", len(emails.Names()), len(emails.Locales))
+}
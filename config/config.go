@@ -11,7 +11,10 @@ import (
 	"gorm.io/gorm"
 
 	"engine/internal/pkg/migrations"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/blob"
 	"engine/pkg/shared/database"
+	"engine/pkg/shared/sms"
 )
 
 type OAuthConfig struct {
@@ -33,6 +36,9 @@ func LoadConfig(logger *logrus.Logger) {
 	LoadEnv(logger)
 	LoadDB(logger)
 	LoadOAuthConfig()
+	LoadOIDCConfig(logger)
+	LoadSMSConfig(logger)
+	LoadBlobConfig(logger)
 }
 
 func LoadEnv(logger *logrus.Logger) {
@@ -72,6 +78,30 @@ func LoadDB(logger *logrus.Logger) *gorm.DB {
 	return dbConn
 }
 
+// LoadOIDCConfig stops the start when the OpenID Connect provider is not configured
+func LoadOIDCConfig(logger *logrus.Logger) {
+	err := auth.CheckOIDCConfig()
+	if err != nil {
+		logger.Fatalf("Fail to load OIDC config: %v", err)
+	}
+}
+
+// LoadSMSConfig stops the start when the configured SMS provider cannot work
+func LoadSMSConfig(logger *logrus.Logger) {
+	err := sms.CheckConfig()
+	if err != nil {
+		logger.Fatalf("Fail to load SMS config: %v", err)
+	}
+}
+
+// LoadBlobConfig stops the start when the configured blob store cannot work
+func LoadBlobConfig(logger *logrus.Logger) {
+	err := blob.CheckConfig()
+	if err != nil {
+		logger.Fatalf("Fail to load blob config: %v", err)
+	}
+}
+
 func LoadOAuthConfig() {
 	// Oauth configuration for Google
 	AppConfig.GoogleLoginConfig = oauth2.Config{
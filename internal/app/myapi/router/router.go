@@ -1,7 +1,10 @@
 package router
 
 import (
+	"encoding/json"
+	"expvar"
 	"net/http"
+	"os"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -10,6 +13,11 @@ import (
 
 	"engine/internal/pkg/domains/models/dtos"
 	"engine/internal/pkg/handlers"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/blob"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/middleware"
 )
 
 // Router is application struct
@@ -34,6 +42,35 @@ func (r *Router) InitializeRouter(logger *logrus.Logger) {
 
 func (r *Router) SetupHandler() {
 	authHandler := handlers.NewAuthHandler(r.DBConn)
+	userHandler := handlers.NewUserHandler(r.DBConn)
+	auditHandler := handlers.NewAuditHandler(r.DBConn)
+	sessionHandler := handlers.NewSessionHandler(r.DBConn)
+	accountHandler := handlers.NewAccountHandler(r.DBConn)
+	avatarHandler := handlers.NewAvatarHandler(r.DBConn)
+
+	tokenHandler := handlers.NewPersonalAccessTokenHandler(r.DBConn)
+	oauthHandler := handlers.NewOAuthHandler(r.DBConn)
+	organizationHandler := handlers.NewOrganizationHandler(r.DBConn)
+	samlHandler := handlers.NewSAMLHandler(r.DBConn)
+	scimHandler := handlers.NewSCIMHandler(r.DBConn)
+	emailOutboxHandler := handlers.NewEmailOutboxHandler(r.DBConn)
+	emailTemplateHandler := handlers.NewEmailTemplateHandler()
+	emailSuppressionHandler := handlers.NewEmailSuppressionHandler(r.DBConn)
+
+	tokenUsecase := usecases.NewPersonalAccessTokenUsecase(
+		repositories.NewUserRepository(r.DBConn),
+		repositories.NewPersonalAccessTokenRepository(r.DBConn),
+		usecases.NewAuditUsecase(repositories.NewAuditRepository(r.DBConn)),
+	)
+	checkAuthentication := middleware.CheckAuthentication(
+		repositories.NewSessionRepository(r.DBConn),
+		tokenUsecase,
+		repositories.NewOAuthClientRepository(r.DBConn),
+		repositories.NewOAuthRevokedTokenRepository(r.DBConn),
+	)
+	requireUser := middleware.RequireUser()
+	requireSession := middleware.RequireSession()
+	checkScopes := middleware.CheckScopes()
 
 	// ping
 	r.Engine.GET("/ping", func(c *gin.Context) {
@@ -45,6 +82,50 @@ func (r *Router) SetupHandler() {
 		c.JSON(http.StatusOK, data)
 	})
 
+	// openid connect discovery
+	r.Engine.GET("/.well-known/openid-configuration", oauthHandler.Discovery)
+	r.Engine.GET("/.well-known/jwks.json", oauthHandler.JWKS)
+
+	// oauth
+	oauthAPI := r.Engine.Group("/oauth")
+	{
+		oauthAPI.GET("/authorize", oauthHandler.Authorize)
+		oauthAPI.POST("/authorize", oauthHandler.AuthorizeDecision)
+		oauthAPI.POST("/token", oauthHandler.Token)
+		oauthAPI.POST("/device_authorization", oauthHandler.DeviceAuthorization)
+		oauthAPI.POST("/introspect", oauthHandler.Introspect)
+		oauthAPI.POST("/revoke", oauthHandler.Revoke)
+		oauthAPI.GET("/userinfo", checkAuthentication, requireUser, oauthHandler.UserInfo)
+		oauthAPI.POST("/userinfo", checkAuthentication, requireUser, oauthHandler.UserInfo)
+		oauthAPI.GET("/logout", oauthHandler.EndSession)
+		oauthAPI.POST("/logout", oauthHandler.EndSession)
+	}
+
+	// saml
+	samlAPI := r.Engine.Group("/saml/:slug")
+	{
+		samlAPI.GET("/metadata", samlHandler.Metadata)
+		samlAPI.GET("/login", samlHandler.Login)
+		samlAPI.POST("/acs", samlHandler.ACS)
+	}
+
+	// scim
+	scimAPI := r.Engine.Group("/scim/v2", middleware.CheckSCIMToken(repositories.NewSCIMTokenRepository(r.DBConn)))
+	{
+		scimAPI.GET("/Users", scimHandler.ListUsers)
+		scimAPI.POST("/Users", scimHandler.CreateUser)
+		scimAPI.GET("/Users/:id", scimHandler.GetUser)
+		scimAPI.PUT("/Users/:id", scimHandler.ReplaceUser)
+		scimAPI.PATCH("/Users/:id", scimHandler.PatchUser)
+		scimAPI.DELETE("/Users/:id", scimHandler.DeleteUser)
+		scimAPI.GET("/Groups", scimHandler.ListGroups)
+		scimAPI.POST("/Groups", scimHandler.CreateGroup)
+		scimAPI.GET("/Groups/:id", scimHandler.GetGroup)
+		scimAPI.PUT("/Groups/:id", scimHandler.ReplaceGroup)
+		scimAPI.PATCH("/Groups/:id", scimHandler.PatchGroup)
+		scimAPI.DELETE("/Groups/:id", scimHandler.DeleteGroup)
+	}
+
 	// router api
 	publicApi := r.Engine.Group("/api")
 	{
@@ -53,12 +134,118 @@ func (r *Router) SetupHandler() {
 		{
 			authAPI.POST("/signup", authHandler.SignUp)
 			authAPI.POST("/signin", authHandler.SignIn)
+			authAPI.POST("/signin/sms", authHandler.SignInSMS)
 			authAPI.GET("/google/signin", authHandler.SignInWithGoogle)
 			authAPI.GET("/google/redirect", authHandler.Redirect)
 			authAPI.POST("/forgot_password", authHandler.ForgotPassword)
 			authAPI.GET("/reset_password/:email/:token", authHandler.VerifyResetPasswordLink)
 			authAPI.PATCH("/reset_password/:email/:token", authHandler.PatchResetPassword)
 			authAPI.GET("/verify_email/:email/:token", authHandler.VerifyEmailAddress)
+			authAPI.POST("/not_me/:token", authHandler.NotMe)
+			authAPI.POST("/confirm_email_change/:token", accountHandler.ConfirmEmailChange)
+			authAPI.POST("/undo_email_change/:token", accountHandler.UndoEmailChange)
+		}
+
+		// signed URLs of the local blob store, BLOB_LOCAL_URL points here
+		if store, ok := blob.Default().(http.Handler); ok {
+			publicApi.GET("/blobs/*key", gin.WrapH(http.StripPrefix("/api/blobs", store)))
+		}
+
+		// webhooks of external providers, authenticated by a shared secret
+		webhooksAPI := publicApi.Group("/webhooks")
+		{
+			webhooksAPI.POST("/email_events", middleware.CheckWebhookSecret(os.Getenv("MAIL_WEBHOOK_SECRET")), emailSuppressionHandler.HandleEvents)
+		}
+
+		// me
+		meAPI := publicApi.Group("/me", checkAuthentication, requireUser, checkScopes)
+		{
+			meAPI.GET("", accountHandler.GetProfile)
+			meAPI.PATCH("", accountHandler.UpdateProfile)
+			meAPI.PUT("/avatar", avatarHandler.UploadAvatar)
+			meAPI.DELETE("/avatar", avatarHandler.DeleteAvatar)
+			meAPI.GET("/sessions", sessionHandler.ListSessions)
+			meAPI.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+			meAPI.POST("/email", accountHandler.ChangeEmail)
+			meAPI.POST("/password", accountHandler.ChangePassword)
+			meAPI.POST("/phone", accountHandler.ChangePhone)
+			meAPI.POST("/phone/verify", accountHandler.VerifyPhone)
+			meAPI.PUT("/sms_two_factor", accountHandler.SetSMSTwoFactor)
+			// a token must not be able to mint or revoke other tokens
+			meAPI.GET("/tokens", requireSession, tokenHandler.ListTokens)
+			meAPI.POST("/tokens", requireSession, tokenHandler.CreateToken)
+			meAPI.DELETE("/tokens/:id", requireSession, tokenHandler.RevokeToken)
+			meAPI.GET("/device/:user_code", oauthHandler.GetDeviceAuthorization)
+			meAPI.POST("/device/:user_code", requireSession, oauthHandler.DecideDeviceAuthorization)
+		}
+
+		// admin
+		adminAPI := publicApi.Group("/admin", checkAuthentication, middleware.CheckRole(constants.RoleAdmin))
+		{
+			usersAPI := adminAPI.Group("/users")
+			{
+				usersAPI.GET("", userHandler.ListUsers)
+				usersAPI.POST("", userHandler.CreateUser)
+				usersAPI.GET("/:id", userHandler.GetUser)
+				usersAPI.PATCH("/:id", userHandler.UpdateUser)
+				usersAPI.DELETE("/:id", userHandler.DeleteUser)
+				usersAPI.POST("/:id/suspend", userHandler.SuspendUser)
+				usersAPI.POST("/:id/reactivate", userHandler.ReactivateUser)
+				usersAPI.POST("/:id/force_password_reset", userHandler.ForcePasswordReset)
+			}
+
+			// only the per backend sign in counters, the rest of expvar
+			// (cmdline, memstats) is not for the API
+			adminAPI.GET("/metrics", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{
+					constants.AuthBackendMetrics: json.RawMessage(expvar.Get(constants.AuthBackendMetrics).String()),
+				})
+			})
+
+			auditAPI := adminAPI.Group("/audit_events")
+			{
+				auditAPI.GET("", auditHandler.ListAuditEvents)
+				auditAPI.GET("/export", auditHandler.ExportAuditEvents)
+			}
+
+			emailOutboxAPI := adminAPI.Group("/email_outbox")
+			{
+				emailOutboxAPI.GET("", emailOutboxHandler.ListEmails)
+				emailOutboxAPI.POST("/:id/resend", emailOutboxHandler.ResendEmail)
+			}
+
+			emailSuppressionsAPI := adminAPI.Group("/email_suppressions")
+			{
+				emailSuppressionsAPI.GET("", emailSuppressionHandler.ListSuppressions)
+				emailSuppressionsAPI.DELETE("/:id", emailSuppressionHandler.DeleteSuppression)
+			}
+
+			emailTemplatesAPI := adminAPI.Group("/email_templates")
+			{
+				emailTemplatesAPI.GET("", emailTemplateHandler.ListTemplates)
+				emailTemplatesAPI.GET("/:name/preview", emailTemplateHandler.PreviewTemplate)
+			}
+
+			oauthClientsAPI := adminAPI.Group("/oauth_clients")
+			{
+				oauthClientsAPI.GET("", oauthHandler.ListClients)
+				oauthClientsAPI.POST("", oauthHandler.CreateClient)
+				oauthClientsAPI.DELETE("/:id", oauthHandler.DeleteClient)
+			}
+
+			organizationsAPI := adminAPI.Group("/organizations")
+			{
+				organizationsAPI.GET("", organizationHandler.ListOrganizations)
+				organizationsAPI.POST("", organizationHandler.CreateOrganization)
+				organizationsAPI.GET("/:id/saml", samlHandler.GetConnection)
+				organizationsAPI.PUT("/:id/saml", samlHandler.ConfigureConnection)
+				organizationsAPI.GET("/:id/scim_tokens", scimHandler.ListTokens)
+				organizationsAPI.POST("/:id/scim_tokens", scimHandler.CreateToken)
+				organizationsAPI.DELETE("/:id/scim_tokens/:token_id", scimHandler.RevokeToken)
+				organizationsAPI.GET("/:id/attributes", organizationHandler.ListAttributes)
+				organizationsAPI.POST("/:id/attributes", organizationHandler.CreateAttribute)
+				organizationsAPI.DELETE("/:id/attributes/:attribute_id", organizationHandler.DeleteAttribute)
+			}
 		}
 	}
 }
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type EmailChangeRepository interface {
+	CreateEmailChange(change entities.EmailChange) (entities.EmailChange, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.EmailChange, error)
+	UpdateEmailChange(change entities.EmailChange, data map[string]interface{}) error
+}
+
+// AccountUsecase holds the self-service operations of a signed-in user
+type AccountUsecase interface {
+	GetProfile(userID uint) (entities.User, error)
+	UpdateProfile(userID uint, req dtos.UpdateProfileRequest, client dtos.ClientInfo) (entities.User, error)
+	ChangePassword(userID uint, sessionID uint, req dtos.ChangePasswordRequest, client dtos.ClientInfo) error
+	RequestEmailChange(userID uint, sessionID uint, req dtos.ChangeEmailRequest, client dtos.ClientInfo) error
+	ConfirmEmailChange(token string, client dtos.ClientInfo) error
+	UndoEmailChange(token string, client dtos.ClientInfo) error
+	ChangePhone(userID uint, sessionID uint, req dtos.ChangePhoneRequest, client dtos.ClientInfo) error
+	VerifyPhone(userID uint, req dtos.VerifyPhoneRequest, client dtos.ClientInfo) error
+	SetSMSTwoFactor(userID uint, sessionID uint, req dtos.SetSMSTwoFactorRequest, client dtos.ClientInfo) error
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type AuditRepository interface {
+	AppendAuditEvent(event entities.AuditEvent, hashFn func(event entities.AuditEvent) string) (entities.AuditEvent, error)
+	TakeAuditEvent(id uint) (entities.AuditEvent, error)
+	TakeChainHead() (entities.AuditChainHead, error)
+	PaginateByFilter(filter dtos.AuditEventFilter, offset int, limit int) ([]entities.AuditEvent, int64, error)
+	IterateByFilter(filter dtos.AuditEventFilter, fn func(event entities.AuditEvent) error) error
+}
+
+type AuditUsecase interface {
+	Record(action string, client dtos.ClientInfo, targetID *uint, err error, metadata map[string]interface{}) error
+	ListAuditEvents(filter dtos.AuditEventFilter) ([]entities.AuditEvent, dtos.PaginationResponse, error)
+	ExportAuditEvents(filter dtos.AuditEventFilter, fn func(event entities.AuditEvent) error) error
+	VerifyChain(checkpointEvery int, onCheckpoint func(checkpoint dtos.AuditCheckpoint) error) (dtos.AuditChainReport, error)
+	VerifyCheckpoint(checkpoint dtos.AuditCheckpoint) error
+}
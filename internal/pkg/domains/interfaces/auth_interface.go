@@ -8,9 +8,17 @@ import (
 type AuthUsecase interface {
 	FindByConditions(conditions map[string]interface{}) ([]entities.User, error)
 	TakeByConditions(conditions map[string]interface{}) (entities.User, error)
-	SignUp(req dtos.CreateUserRequest) (entities.User, error)
-	SignIn(req dtos.SignInRequest) (entities.User, string, error)
-	SendMailForgotPassword(req dtos.ForgotPasswordRequest) error
-	ActiveUser(userID uint) error
-	ResetPassword(userId uint, req dtos.ResetPasswordRequest) error
+	SignUp(req dtos.CreateUserRequest, client dtos.ClientInfo) (entities.User, error)
+	SignIn(req dtos.SignInRequest, client dtos.ClientInfo) (entities.User, string, error)
+	SignInSMS(req dtos.SMSSignInRequest, client dtos.ClientInfo) (entities.User, string, error)
+	SignInExternal(user entities.User, provider string, client dtos.ClientInfo) (entities.User, string, error)
+	Authenticate(req dtos.SignInRequest, client dtos.ClientInfo) (entities.User, error)
+	AuthenticateSMS(req dtos.SMSSignInRequest, client dtos.ClientInfo) (entities.User, error)
+	GenerateAccessToken(user entities.User, client dtos.ClientInfo) (string, error)
+	IssueAccessToken(user entities.User, client dtos.ClientInfo, extraClaims map[string]interface{}) (entities.Session, string, error)
+	SendMailForgotPassword(req dtos.ForgotPasswordRequest, client dtos.ClientInfo) error
+	ActiveUser(userID uint, client dtos.ClientInfo) error
+	ResetPassword(userId uint, req dtos.ResetPasswordRequest, client dtos.ClientInfo) error
+	NotMe(token string, client dtos.ClientInfo) error
+	VerifyLink(token string, purpose string) (entities.User, error)
 }
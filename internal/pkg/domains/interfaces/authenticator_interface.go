@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+// Authenticator checks the credentials of a sign in and returns the matching
+// user. Account status checks are left to AuthUsecase.
+type Authenticator interface {
+	Name() string
+	Authenticate(req dtos.SignInRequest) (entities.User, error)
+}
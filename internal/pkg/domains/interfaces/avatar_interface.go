@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type AvatarUsecase interface {
+	// UploadAvatar stores the renditions of an image and replaces the previous avatar
+	UploadAvatar(userID uint, data []byte, client dtos.ClientInfo) (entities.User, error)
+	DeleteAvatar(userID uint, client dtos.ClientInfo) (entities.User, error)
+}
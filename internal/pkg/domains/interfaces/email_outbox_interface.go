@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"time"
+
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type EmailOutboxRepository interface {
+	CreateEmail(email entities.EmailOutbox) (entities.EmailOutbox, error)
+	FindDue(now time.Time, limit int) ([]entities.EmailOutbox, error)
+	// ClaimEmail locks a pending email until lockUntil, it returns false when
+	// another worker holds it
+	ClaimEmail(email entities.EmailOutbox, lockUntil time.Time) (bool, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.EmailOutbox, error)
+	PaginateByConditions(conditions map[string]interface{}, offset int, limit int) ([]entities.EmailOutbox, int64, error)
+	UpdateEmail(email entities.EmailOutbox, data map[string]interface{}) error
+}
+
+type EmailOutboxUsecase interface {
+	ListEmails(req dtos.ListEmailOutboxRequest) ([]entities.EmailOutbox, dtos.PaginationResponse, error)
+	ResendEmail(emailID uint) error
+	// DeliverDue sends the emails that are due and returns how many were sent
+	DeliverDue() (int, error)
+	// RunWorker calls DeliverDue every interval until stop is closed
+	RunWorker(interval time.Duration, stop <-chan struct{})
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type EmailSuppressionRepository interface {
+	// SaveSuppression inserts the address or refreshes the reason of an existing one
+	SaveSuppression(suppression entities.EmailSuppression) (entities.EmailSuppression, error)
+	IsSuppressed(email string) (bool, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.EmailSuppression, error)
+	PaginateByConditions(conditions map[string]interface{}, offset int, limit int) ([]entities.EmailSuppression, int64, error)
+	DeleteSuppression(suppression entities.EmailSuppression) error
+}
+
+type EmailSuppressionUsecase interface {
+	// HandleEvents applies the bounce and complaint events of a provider webhook
+	HandleEvents(req dtos.EmailEventsRequest) (dtos.EmailEventsResponse, error)
+	ListSuppressions(req dtos.ListEmailSuppressionsRequest) ([]entities.EmailSuppression, dtos.PaginationResponse, error)
+	DeleteSuppression(suppressionID uint) error
+}
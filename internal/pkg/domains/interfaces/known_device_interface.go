@@ -0,0 +1,9 @@
+package interfaces
+
+import "engine/internal/pkg/domains/models/entities"
+
+type KnownDeviceRepository interface {
+	CreateKnownDevice(device entities.KnownDevice) (entities.KnownDevice, error)
+	FindByConditions(conditions map[string]interface{}) ([]entities.KnownDevice, error)
+	UpdateKnownDevice(device entities.KnownDevice, data map[string]interface{}) error
+}
@@ -0,0 +1,67 @@
+package interfaces
+
+import (
+	"time"
+
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OAuthClientRepository interface {
+	CreateClient(client entities.OAuthClient) (entities.OAuthClient, error)
+	FindByConditions(conditions map[string]interface{}) ([]entities.OAuthClient, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.OAuthClient, error)
+	DeleteClient(client entities.OAuthClient) error
+}
+
+type OAuthAuthorizationCodeRepository interface {
+	CreateCode(code entities.OAuthAuthorizationCode) (entities.OAuthAuthorizationCode, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.OAuthAuthorizationCode, error)
+	// ConsumeCode marks the code as used, it returns false when it was already used
+	ConsumeCode(code entities.OAuthAuthorizationCode) (bool, error)
+}
+
+type OAuthRefreshTokenRepository interface {
+	CreateToken(token entities.OAuthRefreshToken) (entities.OAuthRefreshToken, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.OAuthRefreshToken, error)
+	// RevokeToken returns false when the token was already revoked
+	RevokeToken(token entities.OAuthRefreshToken) (bool, error)
+	RevokeBySessionID(sessionID uint) error
+	RevokeByUserID(userID uint) error
+}
+
+type OAuthDeviceCodeRepository interface {
+	CreateDeviceCode(deviceCode entities.OAuthDeviceCode) (entities.OAuthDeviceCode, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.OAuthDeviceCode, error)
+	UpdateDeviceCode(deviceCode entities.OAuthDeviceCode, data map[string]interface{}) error
+	// DecideDeviceCode approves or denies a pending code, it returns false when it was already decided
+	DecideDeviceCode(deviceCode entities.OAuthDeviceCode, data map[string]interface{}) (bool, error)
+	// ConsumeDeviceCode marks an approved code as used, it returns false when it was already used
+	ConsumeDeviceCode(deviceCode entities.OAuthDeviceCode) (bool, error)
+}
+
+type OAuthRevokedTokenRepository interface {
+	// RevokeJTI does nothing when the jti was already revoked
+	RevokeJTI(token entities.OAuthRevokedToken) error
+	IsRevoked(jti string) (bool, error)
+	// DeleteExpired removes the jtis of tokens that expired before the given time
+	DeleteExpired(before time.Time) error
+}
+
+type OAuthUsecase interface {
+	RegisterClient(ownerID uint, req dtos.CreateOAuthClientRequest) (entities.OAuthClient, string, error)
+	ListClients() ([]entities.OAuthClient, error)
+	DeleteClient(id uint) error
+	ValidateAuthorizeRequest(req dtos.AuthorizeRequest) (entities.OAuthClient, error)
+	Authorize(req dtos.AuthorizeDecisionRequest, client dtos.ClientInfo) (string, error)
+	Token(req dtos.TokenRequest, client dtos.ClientInfo) (dtos.TokenResponse, error)
+	Discovery() dtos.OpenIDConfiguration
+	UserInfo(userID uint, scope string) (dtos.UserInfoResponse, error)
+	Introspect(req dtos.IntrospectionRequest) (dtos.IntrospectionResponse, error)
+	Revoke(req dtos.RevocationRequest) error
+	DeviceAuthorization(req dtos.DeviceAuthorizationRequest) (dtos.DeviceAuthorizationResponse, error)
+	GetDeviceAuthorization(userCode string) (entities.OAuthClient, entities.OAuthDeviceCode, error)
+	DecideDeviceAuthorization(userID uint, userCode string, req dtos.DeviceDecisionRequest) error
+	// EndSession returns the URL to redirect to, empty when the client gave none
+	EndSession(req dtos.EndSessionRequest) (string, error)
+}
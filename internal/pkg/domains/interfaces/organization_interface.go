@@ -0,0 +1,48 @@
+package interfaces
+
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OrganizationRepository interface {
+	CreateOrganization(org entities.Organization) (entities.Organization, error)
+	FindByConditions(conditions map[string]interface{}) ([]entities.Organization, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.Organization, error)
+	TakeMember(conditions map[string]interface{}) (entities.OrganizationMember, error)
+	// AddMember does nothing when the user is already a member
+	AddMember(member entities.OrganizationMember) error
+	PaginateMembers(conditions map[string]interface{}, offset int, limit int) ([]entities.OrganizationMember, int64, error)
+	UpdateMember(member entities.OrganizationMember, data map[string]interface{}) error
+	// RemoveMember also removes the user from the groups of the organization
+	RemoveMember(member entities.OrganizationMember) error
+}
+
+type OrganizationGroupRepository interface {
+	CreateGroup(group entities.OrganizationGroup) (entities.OrganizationGroup, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.OrganizationGroup, error)
+	PaginateByConditions(conditions map[string]interface{}, offset int, limit int) ([]entities.OrganizationGroup, int64, error)
+	UpdateGroup(group entities.OrganizationGroup, data map[string]interface{}) error
+	DeleteGroup(group entities.OrganizationGroup) error
+	FindMemberIDs(groupID uint) ([]uint, error)
+	AddMembers(groupID uint, userIDs []uint) error
+	RemoveMembers(groupID uint, userIDs []uint) error
+	ReplaceMembers(groupID uint, userIDs []uint) error
+}
+
+type OrganizationAttributeRepository interface {
+	CreateAttribute(attr entities.OrganizationAttribute) (entities.OrganizationAttribute, error)
+	FindByConditions(conditions map[string]interface{}) ([]entities.OrganizationAttribute, error)
+	// FindByUserID returns the attributes of every organization the user is a member of
+	FindByUserID(userID uint) ([]entities.OrganizationAttribute, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.OrganizationAttribute, error)
+	DeleteAttribute(attr entities.OrganizationAttribute) error
+}
+
+type OrganizationUsecase interface {
+	ListOrganizations() ([]entities.Organization, error)
+	CreateOrganization(req dtos.CreateOrganizationRequest) (entities.Organization, error)
+	ListAttributes(orgID uint) ([]entities.OrganizationAttribute, error)
+	CreateAttribute(orgID uint, req dtos.CreateOrganizationAttributeRequest) (entities.OrganizationAttribute, error)
+	DeleteAttribute(orgID uint, attrID uint) error
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type PersonalAccessTokenRepository interface {
+	CreateToken(token entities.PersonalAccessToken) (entities.PersonalAccessToken, error)
+	FindActiveByUserID(userID uint) ([]entities.PersonalAccessToken, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.PersonalAccessToken, error)
+	UpdateToken(token entities.PersonalAccessToken, data map[string]interface{}) error
+	RevokeTokens(userID uint) error
+}
+
+type PersonalAccessTokenUsecase interface {
+	ListTokens(userID uint) ([]entities.PersonalAccessToken, error)
+	CreateToken(userID uint, req dtos.CreatePersonalAccessTokenRequest, client dtos.ClientInfo) (entities.PersonalAccessToken, string, error)
+	RevokeToken(userID uint, tokenID uint, client dtos.ClientInfo) error
+	// AuthenticateToken resolves a plain token to the token and its user
+	AuthenticateToken(plainToken string) (entities.PersonalAccessToken, entities.User, error)
+}
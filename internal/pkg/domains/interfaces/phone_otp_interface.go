@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"time"
+
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type PhoneOTPRepository interface {
+	CreateOTP(otp entities.PhoneOTP) (entities.PhoneOTP, error)
+	// TakeByConditions returns the latest code matching conditions
+	TakeByConditions(conditions map[string]interface{}) (entities.PhoneOTP, error)
+	// CountSince counts the codes matching conditions created after since
+	CountSince(conditions map[string]interface{}, since time.Time) (int64, error)
+	// AddAttempt counts a try of the code, it returns false when the tries are
+	// used up or the code was consumed
+	AddAttempt(otp entities.PhoneOTP, maxAttempts int) (bool, error)
+	// ConsumeOTP marks a code as used, it returns false when it already was
+	ConsumeOTP(otp entities.PhoneOTP) (bool, error)
+}
@@ -0,0 +1,32 @@
+package interfaces
+
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type SAMLConnectionRepository interface {
+	TakeByConditions(conditions map[string]interface{}) (entities.SAMLConnection, error)
+	// SaveConnection creates or replaces the connection of an organization
+	SaveConnection(conn entities.SAMLConnection) (entities.SAMLConnection, error)
+}
+
+type SAMLRequestRepository interface {
+	CreateRequest(req entities.SAMLRequest) (entities.SAMLRequest, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.SAMLRequest, error)
+	// ConsumeRequest returns false when the request was already answered
+	ConsumeRequest(req entities.SAMLRequest) (bool, error)
+}
+
+type SAMLAssertionRepository interface {
+	// RecordAssertion returns false when the assertion ID was seen before
+	RecordAssertion(assertion entities.SAMLAssertion) (bool, error)
+}
+
+type SAMLUsecase interface {
+	GetConnection(orgID uint) (entities.SAMLConnection, error)
+	ConfigureConnection(orgID uint, req dtos.SAMLConnectionRequest) (entities.SAMLConnection, error)
+	Metadata(slug string) ([]byte, error)
+	Login(slug string) (dtos.SAMLLoginResponse, error)
+	ConsumeAssertion(slug string, req dtos.SAMLACSRequest, client dtos.ClientInfo) (entities.User, string, error)
+}
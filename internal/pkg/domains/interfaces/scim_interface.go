@@ -0,0 +1,33 @@
+package interfaces
+
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type SCIMTokenRepository interface {
+	CreateToken(token entities.SCIMToken) (entities.SCIMToken, error)
+	FindActiveByOrganizationID(orgID uint) ([]entities.SCIMToken, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.SCIMToken, error)
+	UpdateToken(token entities.SCIMToken, data map[string]interface{}) error
+}
+
+type SCIMUsecase interface {
+	ListTokens(orgID uint) ([]entities.SCIMToken, error)
+	CreateToken(orgID uint, req dtos.CreateSCIMTokenRequest) (entities.SCIMToken, string, error)
+	RevokeToken(orgID uint, tokenID uint) error
+
+	ListUsers(orgID uint, req dtos.SCIMListRequest) (dtos.SCIMListResponse, error)
+	GetUser(orgID uint, id string) (dtos.SCIMUser, error)
+	CreateUser(orgID uint, req dtos.SCIMUser) (dtos.SCIMUser, error)
+	ReplaceUser(orgID uint, id string, req dtos.SCIMUser) (dtos.SCIMUser, error)
+	PatchUser(orgID uint, id string, req dtos.SCIMPatchRequest) (dtos.SCIMUser, error)
+	DeleteUser(orgID uint, id string) error
+
+	ListGroups(orgID uint, req dtos.SCIMListRequest) (dtos.SCIMListResponse, error)
+	GetGroup(orgID uint, id string) (dtos.SCIMGroup, error)
+	CreateGroup(orgID uint, req dtos.SCIMGroup) (dtos.SCIMGroup, error)
+	ReplaceGroup(orgID uint, id string, req dtos.SCIMGroup) (dtos.SCIMGroup, error)
+	PatchGroup(orgID uint, id string, req dtos.SCIMPatchRequest) (dtos.SCIMGroup, error)
+	DeleteGroup(orgID uint, id string) error
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import "engine/internal/pkg/domains/models/entities"
+
+type SessionRepository interface {
+	CreateSession(session entities.Session) (entities.Session, error)
+	FindActiveByUserID(userID uint) ([]entities.Session, error)
+	TakeByConditions(conditions map[string]interface{}) (entities.Session, error)
+	UpdateSession(session entities.Session, data map[string]interface{}) error
+	RevokeSessions(userID uint, exceptSessionID uint) error
+}
+
+type SessionUsecase interface {
+	ListSessions(userID uint) ([]entities.Session, error)
+	RevokeSession(userID uint, sessionID uint) error
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+// Transactor runs fn with repositories bound to one database transaction,
+// an error returned by fn rolls the whole transaction back
+type Transactor interface {
+	WithinTransaction(fn func(repos TxRepositories) error) error
+}
+
+// TxRepositories are the repositories available inside a transaction
+type TxRepositories struct {
+	UserRepo        UserRepository
+	EmailChangeRepo EmailChangeRepository
+	EmailOutboxRepo EmailOutboxRepository
+}
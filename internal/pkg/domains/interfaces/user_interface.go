@@ -1,10 +1,28 @@
 package interfaces
 
-import "engine/internal/pkg/domains/models/entities"
+import (
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
 
 type UserRepository interface {
 	CreateUser(user entities.User) (entities.User, error)
 	FindByConditions(conditions map[string]interface{}) ([]entities.User, error)
 	TakeByConditions(conditions map[string]interface{}) (entities.User, error)
+	// FindByEmail matches the email case-insensitively
+	FindByEmail(email string) ([]entities.User, error)
+	PaginateByConditions(conditions map[string]interface{}, keyword string, order string, offset int, limit int) ([]entities.User, int64, error)
 	UpdateUser(user entities.User, data map[string]interface{}) error
-}
\ No newline at end of file
+	DeleteUser(user entities.User) error
+}
+
+type UserUsecase interface {
+	ListUsers(req dtos.ListUsersRequest) ([]entities.User, dtos.PaginationResponse, error)
+	GetUser(userID uint) (entities.User, error)
+	CreateUser(req dtos.AdminCreateUserRequest) (entities.User, error)
+	UpdateUser(userID uint, req dtos.AdminUpdateUserRequest) (entities.User, error)
+	SuspendUser(userID uint) error
+	ReactivateUser(userID uint) error
+	ForcePasswordReset(userID uint) error
+	DeleteUser(userID uint) error
+}
\ No newline at end of file
@@ -0,0 +1,25 @@
+package dtos
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+type ChangePhoneRequest struct {
+	Phone           string `json:"phone" binding:"required,e164"`
+	CurrentPassword string `json:"current_password"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" binding:"required,numeric"`
+}
+
+type SetSMSTwoFactorRequest struct {
+	Enabled         *bool  `json:"enabled" binding:"required"`
+	CurrentPassword string `json:"current_password"`
+}
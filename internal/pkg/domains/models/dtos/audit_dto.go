@@ -0,0 +1,55 @@
+package dtos
+
+import "time"
+
+// ClientInfo describes who sent the request
+type ClientInfo struct {
+	ActorID   *uint
+	IPAddress string
+	UserAgent string
+	// Locale is the supported locale asked by Accept-Language, if any
+	Locale string
+}
+
+type AuditEventFilter struct {
+	Page     int        `form:"page" binding:"omitempty,min=1"`
+	PageSize int        `form:"page_size" binding:"omitempty,min=1"`
+	ActorID  *uint      `form:"actor_id"`
+	TargetID *uint      `form:"target_id"`
+	Action   string     `form:"action"`
+	Outcome  string     `form:"outcome" binding:"omitempty,oneof=success failure"`
+	IP       string     `form:"ip_address"`
+	From     *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type AuditEventResponse struct {
+	ID        uint                   `json:"id"`
+	CreatedAt time.Time              `json:"created_at"`
+	ActorID   *uint                  `json:"actor_id"`
+	TargetID  *uint                  `json:"target_id"`
+	Action    string                 `json:"action"`
+	IPAddress string                 `json:"ip_address"`
+	UserAgent string                 `json:"user_agent"`
+	Outcome   string                 `json:"outcome"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// AuditChainReport is the result of walking the audit hash chain
+type AuditChainReport struct {
+	Valid         bool   `json:"valid"`
+	Checked       int    `json:"checked"`
+	LegacySkipped int    `json:"legacy_skipped"`
+	LastEventID   uint   `json:"last_event_id"`
+	LastHash      string `json:"last_hash"`
+	BrokenEventID uint   `json:"broken_event_id,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// AuditCheckpoint pins the chain hash at an event, signed with AUDIT_CHECKPOINT_KEY
+type AuditCheckpoint struct {
+	EventID   uint      `json:"event_id"`
+	Hash      string    `json:"hash"`
+	SignedAt  time.Time `json:"signed_at"`
+	Signature string    `json:"signature"`
+}
@@ -5,6 +5,12 @@ type SignInRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// SMSSignInRequest completes a sign in that answered with an sms_challenge
+type SMSSignInRequest struct {
+	Challenge string `json:"challenge" binding:"required"`
+	Code      string `json:"code" binding:"required,numeric"`
+}
+
 type ForgotPasswordRequest struct {
 	Email string `json:"email" binding:"required"`
 }
@@ -12,3 +18,35 @@ type ForgotPasswordRequest struct {
 type ResetPasswordRequest struct {
 	NewPassword string `json:"new_password" binding:"required"`
 }
+
+// AuthError is a failed sign in. Code is one of the constants.AuthError*
+// codes, it does not depend on the backend that failed.
+type AuthError struct {
+	Code    int
+	Message string
+	Backend string
+	Err     error
+	// Challenge is set with AuthErrorSMSCodeRequired, it is sent back with the code
+	Challenge string
+}
+
+func (e *AuthError) Error() string {
+	return e.Message
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPAuthHookRequest is posted to the external sign in hook
+type HTTPAuthHookRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// HTTPAuthHookResponse is the answer of the hook when the credentials are valid
+type HTTPAuthHookResponse struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
@@ -6,6 +6,13 @@ type BaseResponse struct {
 	Error  *ErrorResponse `json:"error,omitempty"`
 }
 
+// PaginationResponse struct
+type PaginationResponse struct {
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+	Total    int64 `json:"total"`
+}
+
 // ErrorResponse struct
 type ErrorResponse struct {
 	ErrorCode    int    `json:"error_code,omitempty"`
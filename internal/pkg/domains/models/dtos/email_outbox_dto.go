@@ -0,0 +1,60 @@
+package dtos
+
+import "time"
+
+type ListEmailOutboxRequest struct {
+	Page      int    `form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `form:"page_size" binding:"omitempty,min=1"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending sent dead"`
+	Recipient string `form:"recipient"`
+}
+
+// EmailOutboxResponse leaves the bodies out, they carry one-off link tokens
+type EmailOutboxResponse struct {
+	ID            uint       `json:"id"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// EmailEventsRequest is the generic bounce and complaint webhook payload,
+// provider specific formats are mapped to it by the sender's relay
+type EmailEventsRequest struct {
+	Events []EmailEvent `json:"events" binding:"required,min=1,dive"`
+}
+
+type EmailEvent struct {
+	// Type is bounce or complaint, BounceType is hard or soft
+	Type       string `json:"type" binding:"required,oneof=bounce complaint"`
+	BounceType string `json:"bounce_type" binding:"required_if=Type bounce,omitempty,oneof=hard soft"`
+	Email      string `json:"email" binding:"required,email"`
+	Reason     string `json:"reason"`
+	Provider   string `json:"provider"`
+}
+
+type EmailEventsResponse struct {
+	Suppressed int `json:"suppressed"`
+	Ignored    int `json:"ignored"`
+}
+
+type ListEmailSuppressionsRequest struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1"`
+	Reason   string `form:"reason" binding:"omitempty,oneof=bounce complaint"`
+	Email    string `form:"email"`
+}
+
+type EmailSuppressionResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,6 @@
+package dtos
+
+type PreviewEmailTemplateRequest struct {
+	Locale string `form:"locale" binding:"omitempty,oneof=en vi"`
+	Format string `form:"format" binding:"omitempty,oneof=html text"`
+}
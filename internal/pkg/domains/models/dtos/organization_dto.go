@@ -0,0 +1,33 @@
+package dtos
+
+import "time"
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+	Slug string `json:"slug" binding:"required,max=64"`
+}
+
+type OrganizationResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateOrganizationAttributeRequest struct {
+	Name      string   `json:"name" binding:"required,max=64"`
+	Type      string   `json:"type" binding:"required,oneof=string number boolean enum"`
+	Required  bool     `json:"required"`
+	Options   []string `json:"options" binding:"required_if=Type enum,omitempty,dive,required"`
+	MaxLength int      `json:"max_length" binding:"omitempty,min=0"`
+}
+
+type OrganizationAttributeResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Required  bool      `json:"required"`
+	Options   []string  `json:"options,omitempty"`
+	MaxLength int       `json:"max_length,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
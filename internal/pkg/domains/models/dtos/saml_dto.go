@@ -0,0 +1,33 @@
+package dtos
+
+type SAMLConnectionRequest struct {
+	IdPMetadataXML    string `json:"idp_metadata_xml" binding:"required"`
+	SSOBinding        string `json:"sso_binding" binding:"omitempty,oneof=redirect post"`
+	EmailAttribute    string `json:"email_attribute"`
+	UsernameAttribute string `json:"username_attribute"`
+	JITProvisioning   bool   `json:"jit_provisioning"`
+	DefaultRole       string `json:"default_role" binding:"omitempty,oneof=member"`
+	IsEnabled         *bool  `json:"is_enabled"`
+}
+
+type SAMLConnectionResponse struct {
+	OrganizationID    uint   `json:"organization_id"`
+	SSOBinding        string `json:"sso_binding"`
+	EmailAttribute    string `json:"email_attribute"`
+	UsernameAttribute string `json:"username_attribute"`
+	JITProvisioning   bool   `json:"jit_provisioning"`
+	DefaultRole       string `json:"default_role"`
+	IsEnabled         bool   `json:"is_enabled"`
+}
+
+// SAMLLoginResponse has RedirectURL for the redirect binding, PostForm for the POST binding
+type SAMLLoginResponse struct {
+	RedirectURL string
+	PostForm    []byte
+}
+
+// SAMLACSRequest is the form the identity provider posts to the ACS
+type SAMLACSRequest struct {
+	SAMLResponse string `form:"SAMLResponse" binding:"required"`
+	RelayState   string `form:"RelayState"`
+}
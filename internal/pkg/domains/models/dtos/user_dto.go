@@ -5,11 +5,62 @@ type CreateUserRequest struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
 	IsActive bool   `json:"is_active"`
+	Locale   string `json:"locale" binding:"omitempty,oneof=en vi"`
 }
 
 type UserResponse struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	IsActive bool   `json:"is_active"`
+	ID           uint   `json:"id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	IsActive     bool   `json:"is_active"`
+	Role         string `json:"role"`
+	IsSuspended  bool   `json:"is_suspended"`
+	Locale       string `json:"locale"`
+	EmailBounced bool   `json:"email_bounced"`
+
+	Phone         string `json:"phone,omitempty"`
+	PhoneVerified bool   `json:"phone_verified"`
+	SMSTwoFactor  bool   `json:"sms_two_factor"`
+
+	DisplayName string                 `json:"display_name"`
+	AvatarURL   string                 `json:"avatar_url"`
+	Timezone    string                 `json:"timezone"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	// AvatarThumbnails are the signed URLs of the smaller sizes of an uploaded avatar
+	AvatarThumbnails map[string]string `json:"avatar_thumbnails,omitempty"`
+}
+
+// UpdateProfileRequest changes the given fields only. Metadata is merged into
+// the current one, a null value removes the attribute.
+type UpdateProfileRequest struct {
+	DisplayName *string                `json:"display_name" binding:"omitempty,max=100"`
+	AvatarURL   *string                `json:"avatar_url" binding:"omitempty,url,max=2048"`
+	Locale      *string                `json:"locale" binding:"omitempty,oneof=en vi"`
+	Timezone    *string                `json:"timezone" binding:"omitempty,timezone"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+type ListUsersRequest struct {
+	Page        int    `form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `form:"page_size" binding:"omitempty,min=1"`
+	Keyword     string `form:"keyword"`
+	Role        string `form:"role" binding:"omitempty,oneof=user admin"`
+	IsActive    *bool  `form:"is_active"`
+	IsSuspended *bool  `form:"is_suspended"`
+	Sort        string `form:"sort"`
+}
+
+type AdminCreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"omitempty,oneof=user admin"`
+}
+
+type AdminUpdateUserRequest struct {
+	Username *string `json:"username" binding:"omitempty,min=1"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+	Role     *string `json:"role" binding:"omitempty,oneof=user admin"`
+	IsActive *bool   `json:"is_active"`
+	UpdateProfileRequest
 }
@@ -0,0 +1,42 @@
+package entities
+
+import "time"
+
+// AuditEventsTableName TableName
+var AuditEventsTableName = "audit_events"
+
+// AuditEvent is append only, so it does not embed BaseEntity
+type AuditEvent struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;index"`
+	ActorID   *uint     `gorm:"column:actor_id;index"`
+	TargetID  *uint     `gorm:"column:target_id;index"`
+	Action    string    `gorm:"column:action;not null;index"`
+	IPAddress string    `gorm:"column:ip_address"`
+	UserAgent string    `gorm:"column:user_agent"`
+	Outcome   string    `gorm:"column:outcome;not null"`
+	Metadata  string    `gorm:"column:metadata;type:text"`
+	PrevHash  string    `gorm:"column:prev_hash;not null;default:''"`
+	Hash      string    `gorm:"column:hash;not null;default:'';index"`
+}
+
+// TableName func
+func (i *AuditEvent) TableName() string {
+	return AuditEventsTableName
+}
+
+// AuditChainHeadsTableName TableName
+var AuditChainHeadsTableName = "audit_chain_heads"
+
+// AuditChainHead keeps the hash of the latest audit event, its single row
+// is locked while appending so concurrent writers cannot fork the chain
+type AuditChainHead struct {
+	ID          uint   `gorm:"primarykey"`
+	LastEventID uint   `gorm:"column:last_event_id"`
+	LastHash    string `gorm:"column:last_hash;not null;default:''"`
+}
+
+// TableName func
+func (i *AuditChainHead) TableName() string {
+	return AuditChainHeadsTableName
+}
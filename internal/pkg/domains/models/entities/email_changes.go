@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// EmailChangesTableName TableName
+var EmailChangesTableName = "email_changes"
+
+// EmailChange is a pending or finished change of User.Email
+type EmailChange struct {
+	BaseEntity
+	UserID      uint       `gorm:"column:user_id;not null;index"`
+	OldEmail    string     `gorm:"column:old_email;not null"`
+	NewEmail    string     `gorm:"column:new_email;not null"`
+	ExpiresAt   time.Time  `gorm:"column:expires_at;not null"`
+	ConfirmedAt *time.Time `gorm:"column:confirmed_at"`
+	CancelledAt *time.Time `gorm:"column:cancelled_at"`
+}
+
+// TableName func
+func (i *EmailChange) TableName() string {
+	return EmailChangesTableName
+}
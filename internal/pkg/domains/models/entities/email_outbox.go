@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+// EmailOutboxTableName TableName
+var EmailOutboxTableName = "email_outbox"
+
+// EmailOutbox is a rendered email waiting for the outbox worker. It is
+// written in the transaction of the change that triggers it.
+type EmailOutbox struct {
+	BaseEntity
+	Recipient     string     `gorm:"column:recipient;not null;index"`
+	Subject       string     `gorm:"column:subject;not null"`
+	HTMLBody      string     `gorm:"column:html_body;type:text"`
+	TextBody      string     `gorm:"column:text_body;type:text"`
+	Status        string     `gorm:"column:status;not null;index:idx_email_outbox_due,priority:1"`
+	Attempts      int        `gorm:"column:attempts;not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"column:next_attempt_at;not null;index:idx_email_outbox_due,priority:2"`
+	LockedUntil   *time.Time `gorm:"column:locked_until"`
+	LastError     string     `gorm:"column:last_error;type:text"`
+	SentAt        *time.Time `gorm:"column:sent_at"`
+}
+
+// TableName func
+func (i *EmailOutbox) TableName() string {
+	return EmailOutboxTableName
+}
@@ -0,0 +1,19 @@
+package entities
+
+// EmailSuppressionsTableName TableName
+var EmailSuppressionsTableName = "email_suppressions"
+
+// EmailSuppression is an address that hard bounced or complained, the outbox
+// worker never sends to it
+type EmailSuppression struct {
+	BaseEntity
+	Email  string `gorm:"column:email;not null;unique"`
+	Reason string `gorm:"column:reason;not null"`
+	Detail string `gorm:"column:detail;type:text"`
+	Source string `gorm:"column:source"`
+}
+
+// TableName func
+func (i *EmailSuppression) TableName() string {
+	return EmailSuppressionsTableName
+}
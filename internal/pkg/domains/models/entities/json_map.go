@@ -0,0 +1,98 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSONMap is a JSON object column, stored as JSONB on Postgres and JSON on MySQL
+type JSONMap map[string]interface{}
+
+// Value func
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+// Scan func
+func (m *JSONMap) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.New("unsupported type for JSONMap")
+	}
+
+	return json.Unmarshal(b, m)
+}
+
+// GormDataType func
+func (JSONMap) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType func
+func (JSONMap) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "JSONB"
+	}
+	return "JSON"
+}
+
+// JSONStrings is a JSON array of strings column
+type JSONStrings []string
+
+// Value func
+func (s JSONStrings) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Scan func
+func (s *JSONStrings) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.New("unsupported type for JSONStrings")
+	}
+
+	return json.Unmarshal(b, s)
+}
+
+// GormDataType func
+func (JSONStrings) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType func
+func (JSONStrings) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "JSONB"
+	}
+	return "JSON"
+}
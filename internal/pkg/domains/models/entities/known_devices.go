@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// KnownDevicesTableName TableName
+var KnownDevicesTableName = "known_devices"
+
+// KnownDevice is a device fingerprint and network a user has signed in from
+type KnownDevice struct {
+	BaseEntity
+	UserID      uint      `gorm:"column:user_id;not null;index"`
+	Fingerprint string    `gorm:"column:fingerprint;not null"`
+	IPRange     string    `gorm:"column:ip_range;not null"`
+	LastSeenAt  time.Time `gorm:"column:last_seen_at;not null"`
+}
+
+// TableName func
+func (i *KnownDevice) TableName() string {
+	return KnownDevicesTableName
+}
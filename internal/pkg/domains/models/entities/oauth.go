@@ -0,0 +1,107 @@
+package entities
+
+import "time"
+
+var (
+	// OAuthClientsTableName TableName
+	OAuthClientsTableName = "oauth_clients"
+	// OAuthAuthorizationCodesTableName TableName
+	OAuthAuthorizationCodesTableName = "oauth_authorization_codes"
+	// OAuthRefreshTokensTableName TableName
+	OAuthRefreshTokensTableName = "oauth_refresh_tokens"
+	// OAuthDeviceCodesTableName TableName
+	OAuthDeviceCodesTableName = "oauth_device_codes"
+	// OAuthRevokedTokensTableName TableName
+	OAuthRevokedTokensTableName = "oauth_revoked_tokens"
+)
+
+// OAuthClient is an application allowed to sign users in with the engine
+type OAuthClient struct {
+	BaseEntity
+	ClientID         string `gorm:"column:client_id;not null;uniqueIndex"`
+	ClientSecretHash string `gorm:"column:client_secret_hash"`
+	Name             string `gorm:"column:name;not null"`
+	RedirectURIs     string `gorm:"column:redirect_uris;type:text;not null"`
+	// PostLogoutRedirectURIs are the allowed targets of RP-initiated logout
+	PostLogoutRedirectURIs string `gorm:"column:post_logout_redirect_uris;type:text"`
+	// Audiences are the services a client_credentials token may be issued for
+	Audiences      string `gorm:"column:audiences;type:text"`
+	Scopes         string `gorm:"column:scopes;not null"`
+	IsConfidential bool   `gorm:"column:is_confidential;default:false"`
+	OwnerID        uint   `gorm:"column:owner_id"`
+}
+
+// TableName func
+func (i *OAuthClient) TableName() string {
+	return OAuthClientsTableName
+}
+
+type OAuthAuthorizationCode struct {
+	BaseEntity
+	CodeHash            string     `gorm:"column:code_hash;not null;uniqueIndex"`
+	ClientID            string     `gorm:"column:client_id;not null"`
+	UserID              uint       `gorm:"column:user_id;not null"`
+	RedirectURI         string     `gorm:"column:redirect_uri;not null"`
+	Scope               string     `gorm:"column:scope"`
+	CodeChallenge       string     `gorm:"column:code_challenge;not null"`
+	CodeChallengeMethod string     `gorm:"column:code_challenge_method;not null"`
+	Nonce               string     `gorm:"column:nonce"`
+	ExpiresAt           time.Time  `gorm:"column:expires_at;not null"`
+	UsedAt              *time.Time `gorm:"column:used_at"`
+}
+
+// TableName func
+func (i *OAuthAuthorizationCode) TableName() string {
+	return OAuthAuthorizationCodesTableName
+}
+
+type OAuthRefreshToken struct {
+	BaseEntity
+	TokenHash string     `gorm:"column:token_hash;not null;uniqueIndex"`
+	ClientID  string     `gorm:"column:client_id;not null;index"`
+	UserID    uint       `gorm:"column:user_id;not null;index"`
+	SessionID uint       `gorm:"column:session_id;not null"`
+	Scope     string     `gorm:"column:scope"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null"`
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+}
+
+// TableName func
+func (i *OAuthRefreshToken) TableName() string {
+	return OAuthRefreshTokensTableName
+}
+
+// OAuthDeviceCode is a pending device authorization (RFC 8628). UserCode is
+// stored without the dash that is shown to the user.
+type OAuthDeviceCode struct {
+	BaseEntity
+	DeviceCodeHash string     `gorm:"column:device_code_hash;not null;uniqueIndex"`
+	UserCode       string     `gorm:"column:user_code;not null;uniqueIndex"`
+	ClientID       string     `gorm:"column:client_id;not null"`
+	Scope          string     `gorm:"column:scope"`
+	UserID         *uint      `gorm:"column:user_id"`
+	Interval       int        `gorm:"column:poll_interval;not null"`
+	ExpiresAt      time.Time  `gorm:"column:expires_at;not null"`
+	LastPolledAt   *time.Time `gorm:"column:last_polled_at"`
+	ApprovedAt     *time.Time `gorm:"column:approved_at"`
+	DeniedAt       *time.Time `gorm:"column:denied_at"`
+	UsedAt         *time.Time `gorm:"column:used_at"`
+}
+
+// TableName func
+func (i *OAuthDeviceCode) TableName() string {
+	return OAuthDeviceCodesTableName
+}
+
+// OAuthRevokedToken is the jti of a revoked client_credentials token. User
+// tokens are revoked through their session instead.
+type OAuthRevokedToken struct {
+	BaseEntity
+	JTI       string    `gorm:"column:jti;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null"`
+}
+
+// TableName func
+func (i *OAuthRevokedToken) TableName() string {
+	return OAuthRevokedTokensTableName
+}
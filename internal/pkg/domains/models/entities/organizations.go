@@ -0,0 +1,88 @@
+package entities
+
+import "time"
+
+var (
+	// OrganizationsTableName TableName
+	OrganizationsTableName = "organizations"
+	// OrganizationMembersTableName TableName
+	OrganizationMembersTableName = "organization_members"
+	// OrganizationGroupsTableName TableName
+	OrganizationGroupsTableName = "organization_groups"
+	// OrganizationGroupMembersTableName TableName
+	OrganizationGroupMembersTableName = "organization_group_members"
+	// OrganizationAttributesTableName TableName
+	OrganizationAttributesTableName = "organization_attributes"
+)
+
+// Organization is an enterprise customer, its SSO settings hang off it
+type Organization struct {
+	BaseEntity
+	Name string `gorm:"column:name;not null"`
+	Slug string `gorm:"column:slug;not null;uniqueIndex"`
+}
+
+// TableName func
+func (i *Organization) TableName() string {
+	return OrganizationsTableName
+}
+
+type OrganizationMember struct {
+	BaseEntity
+	OrganizationID uint   `gorm:"column:organization_id;not null;uniqueIndex:idx_organization_member"`
+	UserID         uint   `gorm:"column:user_id;not null;uniqueIndex:idx_organization_member"`
+	Role           string `gorm:"column:role;not null;default:member"`
+	// ExternalID is the id of the user in the customer directory, set by SCIM
+	ExternalID string `gorm:"column:external_id"`
+	// DeprovisionedAt is set when the directory deactivated the member, it
+	// blocks the SSO sign in to the organization
+	DeprovisionedAt *time.Time `gorm:"column:deprovisioned_at"`
+}
+
+// TableName func
+func (i *OrganizationMember) TableName() string {
+	return OrganizationMembersTableName
+}
+
+// OrganizationGroup is a directory group pushed by SCIM
+type OrganizationGroup struct {
+	BaseEntity
+	OrganizationID uint   `gorm:"column:organization_id;not null;index"`
+	DisplayName    string `gorm:"column:display_name;not null"`
+	ExternalID     string `gorm:"column:external_id"`
+}
+
+// TableName func
+func (i *OrganizationGroup) TableName() string {
+	return OrganizationGroupsTableName
+}
+
+type OrganizationGroupMember struct {
+	BaseEntity
+	GroupID uint `gorm:"column:group_id;not null;uniqueIndex:idx_organization_group_member"`
+	UserID  uint `gorm:"column:user_id;not null;uniqueIndex:idx_organization_group_member"`
+}
+
+// TableName func
+func (i *OrganizationGroupMember) TableName() string {
+	return OrganizationGroupMembersTableName
+}
+
+// OrganizationAttribute declares a custom attribute that the members of the
+// organization can hold in User.Metadata
+type OrganizationAttribute struct {
+	BaseEntity
+	OrganizationID uint   `gorm:"column:organization_id;not null;uniqueIndex:idx_organization_attribute"`
+	Name           string `gorm:"column:name;not null;uniqueIndex:idx_organization_attribute"`
+	Type           string `gorm:"column:type;not null"`
+	Required       bool   `gorm:"column:required;default:false"`
+	// Options are the allowed values of an enum attribute
+	Options JSONStrings `gorm:"column:options"`
+	// MaxLength limits a string attribute, 0 means no limit
+	MaxLength int `gorm:"column:max_length;default:0"`
+}
+
+// TableName func
+func (i *OrganizationAttribute) TableName() string {
+	return OrganizationAttributesTableName
+}
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// PersonalAccessTokensTableName TableName
+var PersonalAccessTokensTableName = "personal_access_tokens"
+
+type PersonalAccessToken struct {
+	BaseEntity
+	UserID      uint       `gorm:"column:user_id;not null;index"`
+	Name        string     `gorm:"column:name;not null"`
+	TokenPrefix string     `gorm:"column:token_prefix;not null"`
+	TokenHash   string     `gorm:"column:token_hash;not null;uniqueIndex"`
+	Scopes      string     `gorm:"column:scopes;not null"`
+	ExpiresAt   time.Time  `gorm:"column:expires_at;not null"`
+	LastUsedAt  *time.Time `gorm:"column:last_used_at"`
+	RevokedAt   *time.Time `gorm:"column:revoked_at"`
+}
+
+// TableName func
+func (i *PersonalAccessToken) TableName() string {
+	return PersonalAccessTokensTableName
+}
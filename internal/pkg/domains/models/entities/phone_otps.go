@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// PhoneOTPsTableName TableName
+var PhoneOTPsTableName = "phone_otps"
+
+// PhoneOTP is a one-time code sent by SMS, to verify a number or as the
+// second factor of a sign in. Only the keyed hash of the code is stored.
+type PhoneOTP struct {
+	BaseEntity
+	UserID     uint       `gorm:"column:user_id;not null;index"`
+	Phone      string     `gorm:"column:phone;not null;index"`
+	Purpose    string     `gorm:"column:purpose;not null"`
+	CodeHash   string     `gorm:"column:code_hash;not null"`
+	Attempts   int        `gorm:"column:attempts;not null;default:0"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;not null"`
+	ConsumedAt *time.Time `gorm:"column:consumed_at"`
+}
+
+// TableName func
+func (i *PhoneOTP) TableName() string {
+	return PhoneOTPsTableName
+}
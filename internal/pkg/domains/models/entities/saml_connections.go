@@ -0,0 +1,59 @@
+package entities
+
+import "time"
+
+var (
+	// SAMLConnectionsTableName TableName
+	SAMLConnectionsTableName = "saml_connections"
+	// SAMLRequestsTableName TableName
+	SAMLRequestsTableName = "saml_requests"
+	// SAMLAssertionsTableName TableName
+	SAMLAssertionsTableName = "saml_assertions"
+)
+
+// SAMLConnection is the identity provider of an organization. Empty attribute
+// names fall back to the NameID for the email and the email for the username.
+type SAMLConnection struct {
+	BaseEntity
+	OrganizationID    uint   `gorm:"column:organization_id;not null;uniqueIndex"`
+	IdPMetadataXML    string `gorm:"column:idp_metadata_xml;type:text;not null"`
+	SSOBinding        string `gorm:"column:sso_binding;not null"`
+	EmailAttribute    string `gorm:"column:email_attribute"`
+	UsernameAttribute string `gorm:"column:username_attribute"`
+	JITProvisioning   bool   `gorm:"column:jit_provisioning;default:false"`
+	// DefaultRole is the organization role of just-in-time provisioned members
+	DefaultRole string `gorm:"column:default_role;not null;default:member"`
+	IsEnabled   bool   `gorm:"column:is_enabled;default:true"`
+}
+
+// TableName func
+func (i *SAMLConnection) TableName() string {
+	return SAMLConnectionsTableName
+}
+
+// SAMLRequest is an AuthnRequest waiting for its response, its ID is also the RelayState
+type SAMLRequest struct {
+	BaseEntity
+	RequestID      string     `gorm:"column:request_id;not null;uniqueIndex"`
+	OrganizationID uint       `gorm:"column:organization_id;not null"`
+	ExpiresAt      time.Time  `gorm:"column:expires_at;not null"`
+	UsedAt         *time.Time `gorm:"column:used_at"`
+}
+
+// TableName func
+func (i *SAMLRequest) TableName() string {
+	return SAMLRequestsTableName
+}
+
+// SAMLAssertion remembers consumed assertion IDs until they expire, to stop replays
+type SAMLAssertion struct {
+	BaseEntity
+	AssertionID    string    `gorm:"column:assertion_id;not null;uniqueIndex"`
+	OrganizationID uint      `gorm:"column:organization_id;not null"`
+	ExpiresAt      time.Time `gorm:"column:expires_at;not null"`
+}
+
+// TableName func
+func (i *SAMLAssertion) TableName() string {
+	return SAMLAssertionsTableName
+}
@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// SCIMTokensTableName TableName
+var SCIMTokensTableName = "scim_tokens"
+
+// SCIMToken is the bearer token a customer directory uses to provision an organization
+type SCIMToken struct {
+	BaseEntity
+	OrganizationID uint       `gorm:"column:organization_id;not null;index"`
+	Name           string     `gorm:"column:name;not null"`
+	TokenPrefix    string     `gorm:"column:token_prefix;not null"`
+	TokenHash      string     `gorm:"column:token_hash;not null;uniqueIndex"`
+	LastUsedAt     *time.Time `gorm:"column:last_used_at"`
+	RevokedAt      *time.Time `gorm:"column:revoked_at"`
+}
+
+// TableName func
+func (i *SCIMToken) TableName() string {
+	return SCIMTokensTableName
+}
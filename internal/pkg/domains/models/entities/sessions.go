@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// SessionsTableName TableName
+var SessionsTableName = "sessions"
+
+type Session struct {
+	BaseEntity
+	UserID     uint       `gorm:"column:user_id;not null;index"`
+	Device     string     `gorm:"column:device"`
+	UserAgent  string     `gorm:"column:user_agent"`
+	IPAddress  string     `gorm:"column:ip_address"`
+	LastSeenAt time.Time  `gorm:"column:last_seen_at;not null"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;not null"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+}
+
+// TableName func
+func (i *Session) TableName() string {
+	return SessionsTableName
+}
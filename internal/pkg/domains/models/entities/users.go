@@ -1,5 +1,7 @@
 package entities
 
+import "time"
+
 // UsersTableName TableName
 var UsersTableName = "users"
 
@@ -9,6 +11,34 @@ type User struct {
 	Email    string `gorm:"column:email;not null;unique"`
 	Password string `gorm:"column:password;not null"`
 	IsActive bool   `gorm:"column:is_active;default:false"`
+	Role     string `gorm:"column:role;not null;default:user"`
+	// Locale is the preferred language of emails, empty follows the request
+	Locale string `gorm:"column:locale"`
+
+	IsSuspended       bool `gorm:"column:is_suspended;default:false"`
+	MustResetPassword bool `gorm:"column:must_reset_password;default:false"`
+	// EmailBounced is set when the address hard bounced, the user has to change it
+	EmailBounced bool `gorm:"column:email_bounced;default:false"`
+	// SCIMOrganizationID is the organization whose directory created the
+	// account, only that directory can change or deprovision it
+	SCIMOrganizationID *uint `gorm:"column:scim_organization_id;index"`
+	// DeprovisionedAt is set when that directory disabled the account, it
+	// blocks every sign in whatever the email verification state
+	DeprovisionedAt *time.Time `gorm:"column:deprovisioned_at"`
+
+	// Phone is an E.164 number, SMS codes are only sent once it is verified
+	Phone         string `gorm:"column:phone"`
+	PhoneVerified bool   `gorm:"column:phone_verified;default:false"`
+	SMSTwoFactor  bool   `gorm:"column:sms_two_factor;default:false"`
+
+	DisplayName string `gorm:"column:display_name"`
+	AvatarURL   string `gorm:"column:avatar_url"`
+	// AvatarKey is the blob key of the uploaded avatar, it wins over AvatarURL
+	AvatarKey string `gorm:"column:avatar_key"`
+	// Timezone is an IANA name such as Asia/Ho_Chi_Minh
+	Timezone string `gorm:"column:timezone"`
+	// Metadata holds the custom attributes declared by the organizations of the user
+	Metadata JSONMap `gorm:"column:metadata"`
 }
 
 // TableName func
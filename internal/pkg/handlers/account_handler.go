@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/sms"
+	"engine/pkg/shared/utils"
+)
+
+type AccountHandler struct {
+	AccountUsecase interfaces.AccountUsecase
+}
+
+func NewAccountHandler(dbConn *gorm.DB) *AccountHandler {
+	userRepo := repositories.NewUserRepository(dbConn)
+	sessionRepo := repositories.NewSessionRepository(dbConn)
+	tokenRepo := repositories.NewPersonalAccessTokenRepository(dbConn)
+	emailChangeRepo := repositories.NewEmailChangeRepository(dbConn)
+	outboxMailer := usecases.NewOutboxMailer(repositories.NewEmailOutboxRepository(dbConn))
+	auditUsecase := usecases.NewAuditUsecase(repositories.NewAuditRepository(dbConn))
+	accountUsecase := usecases.NewAccountUsecase(userRepo, sessionRepo, tokenRepo, repositories.NewOAuthRefreshTokenRepository(dbConn), emailChangeRepo, repositories.NewPhoneOTPRepository(dbConn), repositories.NewOrganizationAttributeRepository(dbConn), outboxMailer, sms.Default(), repositories.NewTransactor(dbConn), auditUsecase)
+	return &AccountHandler{
+		AccountUsecase: accountUsecase,
+	}
+}
+
+func (ah *AccountHandler) GetProfile(c *gin.Context) {
+	user, err := ah.AccountUsecase.GetProfile(c.GetUint(constants.ContextUserIDKey))
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"user_info": utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
+}
+
+func (ah *AccountHandler) UpdateProfile(c *gin.Context) {
+	req := dtos.UpdateProfileRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	user, err := ah.AccountUsecase.UpdateProfile(c.GetUint(constants.ContextUserIDKey), req, utils.GetClientInfo(c))
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"user_info": utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
+}
+
+func (ah *AccountHandler) ChangePassword(c *gin.Context) {
+	req := dtos.ChangePasswordRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	err = ah.AccountUsecase.ChangePassword(
+		c.GetUint(constants.ContextUserIDKey),
+		c.GetUint(constants.ContextSessionIDKey),
+		req,
+		utils.GetClientInfo(c),
+	)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "change password success"},
+	})
+}
+
+func (ah *AccountHandler) ChangeEmail(c *gin.Context) {
+	req := dtos.ChangeEmailRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	err = ah.AccountUsecase.RequestEmailChange(
+		c.GetUint(constants.ContextUserIDKey),
+		c.GetUint(constants.ContextSessionIDKey),
+		req,
+		utils.GetClientInfo(c),
+	)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "check your new email address to confirm the change"},
+	})
+}
+
+func (ah *AccountHandler) ConfirmEmailChange(c *gin.Context) {
+	err := ah.AccountUsecase.ConfirmEmailChange(c.Param("token"), utils.GetClientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "change email success"},
+	})
+}
+
+func (ah *AccountHandler) UndoEmailChange(c *gin.Context) {
+	err := ah.AccountUsecase.UndoEmailChange(c.Param("token"), utils.GetClientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "undo email change success"},
+	})
+}
+
+func (ah *AccountHandler) ChangePhone(c *gin.Context) {
+	req := dtos.ChangePhoneRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	err = ah.AccountUsecase.ChangePhone(
+		c.GetUint(constants.ContextUserIDKey),
+		c.GetUint(constants.ContextSessionIDKey),
+		req,
+		utils.GetClientInfo(c),
+	)
+	if err != nil {
+		c.JSON(phoneOTPErrorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "a code was sent to the new phone number"},
+	})
+}
+
+func (ah *AccountHandler) VerifyPhone(c *gin.Context) {
+	req := dtos.VerifyPhoneRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	err = ah.AccountUsecase.VerifyPhone(
+		c.GetUint(constants.ContextUserIDKey),
+		req,
+		utils.GetClientInfo(c),
+	)
+	if err != nil {
+		c.JSON(phoneOTPErrorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "phone number verified"},
+	})
+}
+
+func (ah *AccountHandler) SetSMSTwoFactor(c *gin.Context) {
+	req := dtos.SetSMSTwoFactorRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	err = ah.AccountUsecase.SetSMSTwoFactor(
+		c.GetUint(constants.ContextUserIDKey),
+		c.GetUint(constants.ContextSessionIDKey),
+		req,
+		utils.GetClientInfo(c),
+	)
+	if err != nil {
+		c.JSON(phoneOTPErrorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "sms two factor updated"},
+	})
+}
+
+func phoneOTPErrorStatus(err error) int {
+	if errors.Is(err, usecases.ErrPhoneOTPRateLimited) {
+		return http.StatusTooManyRequests
+	}
+	if errors.Is(err, sms.ErrDisabled) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
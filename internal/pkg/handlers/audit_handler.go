@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/utils"
+)
+
+type AuditHandler struct {
+	AuditUsecase interfaces.AuditUsecase
+}
+
+func NewAuditHandler(dbConn *gorm.DB) *AuditHandler {
+	auditRepo := repositories.NewAuditRepository(dbConn)
+	auditUsecase := usecases.NewAuditUsecase(auditRepo)
+	return &AuditHandler{
+		AuditUsecase: auditUsecase,
+	}
+}
+
+func (ah *AuditHandler) ListAuditEvents(c *gin.Context) {
+	filter := dtos.AuditEventFilter{}
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	events, pagination, err := ah.AuditUsecase.ListAuditEvents(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"audit_events": utils.ConvertAuditEventEntitiesToResponses(events),
+			"pagination":   pagination,
+		},
+	})
+}
+
+// ExportAuditEvents streams matching events as JSON Lines
+func (ah *AuditHandler) ExportAuditEvents(c *gin.Context) {
+	filter := dtos.AuditEventFilter{}
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit_events.jsonl"`)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	count := 0
+	err = ah.AuditUsecase.ExportAuditEvents(filter, func(event entities.AuditEvent) error {
+		err := encoder.Encode(utils.ConvertAuditEventEntityToResponse(event))
+		if err != nil {
+			return err
+		}
+
+		count++
+		if count%100 == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// headers are already sent, the only thing left is to stop the stream
+		c.Error(err)
+		return
+	}
+
+	c.Writer.Flush()
+}
@@ -4,12 +4,11 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 	"gorm.io/gorm"
 
 	"engine/config"
@@ -18,8 +17,8 @@ import (
 	"engine/internal/pkg/domains/models/entities"
 	"engine/internal/pkg/repositories"
 	"engine/internal/pkg/usecases"
-	"engine/pkg/shared/auth"
 	"engine/pkg/shared/constants"
+	"engine/pkg/shared/sms"
 	"engine/pkg/shared/utils"
 )
 
@@ -29,7 +28,12 @@ type AuthHandler struct {
 
 func NewAuthHandler(dbConn *gorm.DB) *AuthHandler {
 	authRepo := repositories.NewUserRepository(dbConn)
-	authUsecase := usecases.NewAuthUsecase(authRepo)
+	sessionRepo := repositories.NewSessionRepository(dbConn)
+	knownDeviceRepo := repositories.NewKnownDeviceRepository(dbConn)
+	auditRepo := repositories.NewAuditRepository(dbConn)
+	outboxMailer := usecases.NewOutboxMailer(repositories.NewEmailOutboxRepository(dbConn))
+	auditUsecase := usecases.NewAuditUsecase(auditRepo)
+	authUsecase := usecases.NewAuthUsecase(authRepo, sessionRepo, knownDeviceRepo, repositories.NewPhoneOTPRepository(dbConn), usecases.NewAuthenticator(authRepo), outboxMailer, sms.Default(), repositories.NewTransactor(dbConn), auditUsecase)
 	return &AuthHandler{
 		AuthUsecase: authUsecase,
 	}
@@ -48,7 +52,7 @@ func (ah *AuthHandler) SignUp(c *gin.Context) {
 		return
 	}
 
-	user, err := ah.AuthUsecase.SignUp(req)
+	user, err := ah.AuthUsecase.SignUp(req, utils.GetClientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
 			Status: "failed",
@@ -82,27 +86,42 @@ func (ah *AuthHandler) SignIn(c *gin.Context) {
 		return
 	}
 
-	user, token, err := ah.AuthUsecase.SignIn(req)
+	user, token, err := ah.AuthUsecase.SignIn(req, utils.GetClientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
-			Status: "failed",
-			Error: &dtos.ErrorResponse{
-				ErrorMessage: err.Error(),
-			},
-		})
+		respondSignInError(c, err)
 		return
 	}
 
-	if !user.IsActive {
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"access_token": token,
+			"user_info":    utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
+}
+
+// SignInSMS completes a sign in with the code texted to the user
+func (ah *AuthHandler) SignInSMS(c *gin.Context) {
+	req := dtos.SMSSignInRequest{}
+
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
 			Status: "failed",
 			Error: &dtos.ErrorResponse{
-				ErrorMessage: "user is not active",
+				ErrorMessage: err.Error(),
 			},
 		})
 		return
 	}
 
+	user, token, err := ah.AuthUsecase.SignInSMS(req, utils.GetClientInfo(c))
+	if err != nil {
+		respondSignInError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, dtos.BaseResponse{
 		Status: "success",
 		Data: gin.H{
@@ -207,7 +226,7 @@ func (ah *AuthHandler) Redirect(c *gin.Context) {
 			IsActive: true,
 		}
 
-		user, err = ah.AuthUsecase.SignUp(req)
+		user, err = ah.AuthUsecase.SignUp(req, utils.GetClientInfo(c))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, dtos.BaseResponse{
 				Status: "failed",
@@ -217,30 +236,7 @@ func (ah *AuthHandler) Redirect(c *gin.Context) {
 			})
 			return
 		}
-
-		jwtToken, err := auth.GenerateHS256JWT(map[string]interface{}{
-			"email":    config.GoogleUser.Email,
-			"username": config.GoogleUser.Name,
-			"exp":      time.Now().Add(time.Hour * 72).Unix(),
-		})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
-				Status: "failed",
-				Error: &dtos.ErrorResponse{
-					ErrorMessage: "failed while generate token: " + err.Error(),
-				},
-			})
-			return
-		}
-
-		c.JSON(http.StatusOK, dtos.BaseResponse{
-			Status: "success",
-			Data: gin.H{
-				"access_token": jwtToken,
-				"user_info":    utils.ConvertUserEntityToUserResponse(user),
-			},
-		})
-	} else if err != nil && err != gorm.ErrRecordNotFound {
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
 			Status: "failed",
 			Error: &dtos.ErrorResponse{
@@ -248,30 +244,23 @@ func (ah *AuthHandler) Redirect(c *gin.Context) {
 			},
 		})
 		return
-	} else {
-		jwtToken, err := auth.GenerateHS256JWT(map[string]interface{}{
-			"email":    config.GoogleUser.Email,
-			"username": config.GoogleUser.Name,
-			"exp":      time.Now().Add(time.Hour * 72).Unix(),
-		})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
-				Status: "failed",
-				Error: &dtos.ErrorResponse{
-					ErrorMessage: "failed while generate token: " + err.Error(),
-				},
-			})
-			return
-		}
+	}
 
-		c.JSON(http.StatusOK, dtos.BaseResponse{
-			Status: "success",
-			Data: gin.H{
-				"access_token": jwtToken,
-				"user_info":    utils.ConvertUserEntityToUserResponse(user),
-			},
-		})
+	// Google only replaces the password, the account checks and the SMS
+	// second factor of a password sign in still apply
+	user, jwtToken, err := ah.AuthUsecase.SignInExternal(user, constants.SignInProviderGoogle, utils.GetClientInfo(c))
+	if err != nil {
+		respondSignInError(c, err)
+		return
 	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"access_token": jwtToken,
+			"user_info":    utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
 }
 
 func (ah *AuthHandler) ForgotPassword(c *gin.Context) {
@@ -288,7 +277,7 @@ func (ah *AuthHandler) ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	err = ah.AuthUsecase.SendMailForgotPassword(req)
+	err = ah.AuthUsecase.SendMailForgotPassword(req, utils.GetClientInfo(c))
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
@@ -307,7 +296,7 @@ func (ah *AuthHandler) ForgotPassword(c *gin.Context) {
 }
 
 func (ah *AuthHandler) VerifyResetPasswordLink(c *gin.Context) {
-	_, ok := ah.VerifyParam(c)
+	_, ok := ah.VerifyParam(c, constants.TokenPurposeResetPassword)
 	if !ok {
 		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
 			Status: "failed",
@@ -315,6 +304,7 @@ func (ah *AuthHandler) VerifyResetPasswordLink(c *gin.Context) {
 				ErrorMessage: "verify param error",
 			},
 		})
+		return
 	}
 
 	c.JSON(http.StatusOK, dtos.BaseResponse{
@@ -324,7 +314,7 @@ func (ah *AuthHandler) VerifyResetPasswordLink(c *gin.Context) {
 }
 
 func (ah *AuthHandler) VerifyEmailAddress(c *gin.Context) {
-	user, ok := ah.VerifyParam(c)
+	user, ok := ah.VerifyParam(c, constants.TokenPurposeVerifyEmail)
 	if !ok {
 		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
 			Status: "failed",
@@ -332,9 +322,10 @@ func (ah *AuthHandler) VerifyEmailAddress(c *gin.Context) {
 				ErrorMessage: "verify param error",
 			},
 		})
+		return
 	}
 
-	err := ah.AuthUsecase.ActiveUser(user.ID)
+	err := ah.AuthUsecase.ActiveUser(user.ID, utils.GetClientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
 			Status: "failed",
@@ -342,6 +333,7 @@ func (ah *AuthHandler) VerifyEmailAddress(c *gin.Context) {
 				ErrorMessage: "failed to verify email address",
 			},
 		})
+		return
 	}
 
 	c.JSON(http.StatusOK, dtos.BaseResponse{
@@ -353,7 +345,7 @@ func (ah *AuthHandler) VerifyEmailAddress(c *gin.Context) {
 }
 
 func (ah *AuthHandler) PatchResetPassword(c *gin.Context) {
-	user, ok := ah.VerifyParam(c)
+	user, ok := ah.VerifyParam(c, constants.TokenPurposeResetPassword)
 	if !ok {
 		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
 			Status: "failed",
@@ -361,6 +353,7 @@ func (ah *AuthHandler) PatchResetPassword(c *gin.Context) {
 				ErrorMessage: "verify param error",
 			},
 		})
+		return
 	}
 
 	req := dtos.ResetPasswordRequest{}
@@ -375,7 +368,7 @@ func (ah *AuthHandler) PatchResetPassword(c *gin.Context) {
 		return
 	}
 
-	err = ah.AuthUsecase.ResetPassword(user.ID, req)
+	err = ah.AuthUsecase.ResetPassword(user.ID, req, utils.GetClientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
 			Status: "failed",
@@ -383,6 +376,7 @@ func (ah *AuthHandler) PatchResetPassword(c *gin.Context) {
 				ErrorMessage: "failed to reset password",
 			},
 		})
+		return
 	}
 
 	c.JSON(http.StatusOK, dtos.BaseResponse{
@@ -393,7 +387,29 @@ func (ah *AuthHandler) PatchResetPassword(c *gin.Context) {
 	})
 }
 
-func (ah *AuthHandler) VerifyParam(c *gin.Context) (entities.User, bool) {
+func (ah *AuthHandler) NotMe(c *gin.Context) {
+	err := ah.AuthUsecase.NotMe(c.Param("token"), utils.GetClientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"message": "session revoked, check your email to reset your password",
+		},
+	})
+}
+
+// VerifyParam checks the email and token of an emailed link, the token must
+// have been issued for purpose
+func (ah *AuthHandler) VerifyParam(c *gin.Context, purpose string) (entities.User, bool) {
 	email := c.Param("email")
 	if email == "" {
 		return entities.User{}, false
@@ -409,26 +425,68 @@ func (ah *AuthHandler) VerifyParam(c *gin.Context) (entities.User, bool) {
 		return entities.User{}, false
 	}
 
-	decodedToken, err := auth.DecodeJWT(token)
+	user, err := ah.AuthUsecase.VerifyLink(token, purpose)
 	if err != nil {
 		return entities.User{}, false
 	}
 
-	user, err := ah.AuthUsecase.TakeByConditions(map[string]interface{}{
-		"email": decodedToken.Claims.(jwt.MapClaims)["email"],
-	})
-	if err != nil {
+	// a link sent before an email change is no longer valid
+	if string(decodedEmail) != user.Email {
 		return entities.User{}, false
 	}
 
-	if string(decodedEmail) != user.Email {
-		return entities.User{}, false
+	return user, true
+}
+
+// respondSignInError writes a failed sign in, with the SMS challenge when a
+// code is required
+func respondSignInError(c *gin.Context, err error) {
+	authErr := &dtos.AuthError{}
+	if !errors.As(err, &authErr) {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, usecases.ErrPhoneOTPRateLimited):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, usecases.ErrPhoneOTPInvalid):
+			status = http.StatusUnauthorized
+		case errors.Is(err, sms.ErrDisabled):
+			status = http.StatusServiceUnavailable
+		}
+
+		c.JSON(status, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
 	}
 
-	verifyToken := auth.VerifyJWT(token)
-	if !verifyToken {
-		return entities.User{}, false
+	var data gin.H
+	if authErr.Challenge != "" {
+		data = gin.H{"sms_challenge": authErr.Challenge}
 	}
 
-	return user, true
+	c.JSON(authErrorStatus(authErr), dtos.BaseResponse{
+		Status: "failed",
+		Data:   data,
+		Error: &dtos.ErrorResponse{
+			ErrorCode:    authErr.Code,
+			ErrorMessage: authErr.Message,
+		},
+	})
+}
+
+// authErrorStatus maps sign in error codes to HTTP status codes
+func authErrorStatus(err *dtos.AuthError) int {
+	switch err.Code {
+	case constants.AuthErrorInvalidCredentials, constants.AuthErrorSMSCodeRequired:
+		return http.StatusUnauthorized
+	case constants.AuthErrorUserNotActive:
+		return http.StatusBadRequest
+	case constants.AuthErrorBackendUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusForbidden
+	}
 }
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/avatar"
+	"engine/pkg/shared/blob"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/utils"
+)
+
+// avatarFormOverhead leaves room for the multipart boundaries and headers
+const avatarFormOverhead = 64 << 10
+
+var avatarTooLargeMessage = fmt.Sprintf("avatar must not exceed %d MB", constants.AvatarMaxBytes>>20)
+
+type AvatarHandler struct {
+	AvatarUsecase interfaces.AvatarUsecase
+}
+
+func NewAvatarHandler(dbConn *gorm.DB) *AvatarHandler {
+	auditUsecase := usecases.NewAuditUsecase(repositories.NewAuditRepository(dbConn))
+	avatarUsecase := usecases.NewAvatarUsecase(repositories.NewUserRepository(dbConn), blob.Default(), auditUsecase)
+	return &AvatarHandler{
+		AvatarUsecase: avatarUsecase,
+	}
+}
+
+// UploadAvatar takes the image in the "avatar" field of a multipart form
+func (ah *AvatarHandler) UploadAvatar(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, constants.AvatarMaxBytes+avatarFormOverhead)
+
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		status, message := http.StatusBadRequest, "avatar file is required"
+		maxBytesErr := &http.MaxBytesError{}
+		if errors.As(err, &maxBytesErr) {
+			status, message = http.StatusRequestEntityTooLarge, avatarTooLargeMessage
+		}
+		c.JSON(status, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: message,
+			},
+		})
+		return
+	}
+
+	if header.Size > constants.AvatarMaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: avatarTooLargeMessage,
+			},
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, constants.AvatarMaxBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	user, err := ah.AvatarUsecase.UploadAvatar(c.GetUint(constants.ContextUserIDKey), data, utils.GetClientInfo(c))
+	if err != nil {
+		c.JSON(avatarErrorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"user_info": utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
+}
+
+func (ah *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	user, err := ah.AvatarUsecase.DeleteAvatar(c.GetUint(constants.ContextUserIDKey), utils.GetClientInfo(c))
+	if err != nil {
+		c.JSON(avatarErrorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"user_info": utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
+}
+
+func avatarErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, avatar.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, avatar.ErrInvalidImage), errors.Is(err, avatar.ErrTooManyPixels):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrNoAvatar):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
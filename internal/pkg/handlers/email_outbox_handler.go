@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/mailer"
+	"engine/pkg/shared/utils"
+)
+
+type EmailOutboxHandler struct {
+	EmailOutboxUsecase interfaces.EmailOutboxUsecase
+}
+
+func NewEmailOutboxHandler(dbConn *gorm.DB) *EmailOutboxHandler {
+	emailOutboxRepo := repositories.NewEmailOutboxRepository(dbConn)
+	outboxMailer := mailer.NewSuppressingMailer(mailer.Default(), repositories.NewEmailSuppressionRepository(dbConn))
+	emailOutboxUsecase := usecases.NewEmailOutboxUsecase(emailOutboxRepo, outboxMailer)
+	return &EmailOutboxHandler{
+		EmailOutboxUsecase: emailOutboxUsecase,
+	}
+}
+
+func (eh *EmailOutboxHandler) ListEmails(c *gin.Context) {
+	req := dtos.ListEmailOutboxRequest{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	emails, pagination, err := eh.EmailOutboxUsecase.ListEmails(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"emails":     utils.ConvertEmailOutboxEntitiesToResponses(emails),
+			"pagination": pagination,
+		},
+	})
+}
+
+func (eh *EmailOutboxHandler) ResendEmail(c *gin.Context) {
+	emailID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := eh.EmailOutboxUsecase.ResendEmail(emailID)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "email queued for delivery"},
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/utils"
+)
+
+type EmailSuppressionHandler struct {
+	EmailSuppressionUsecase interfaces.EmailSuppressionUsecase
+}
+
+func NewEmailSuppressionHandler(dbConn *gorm.DB) *EmailSuppressionHandler {
+	emailSuppressionUsecase := usecases.NewEmailSuppressionUsecase(
+		repositories.NewEmailSuppressionRepository(dbConn),
+		repositories.NewUserRepository(dbConn),
+	)
+	return &EmailSuppressionHandler{
+		EmailSuppressionUsecase: emailSuppressionUsecase,
+	}
+}
+
+// HandleEvents is the bounce and complaint webhook of the email provider
+func (eh *EmailSuppressionHandler) HandleEvents(c *gin.Context) {
+	req := dtos.EmailEventsRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	res, err := eh.EmailSuppressionUsecase.HandleEvents(req)
+	if err != nil {
+		// the provider retries the delivery of the webhook
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"suppressed": res.Suppressed,
+			"ignored":    res.Ignored,
+		},
+	})
+}
+
+func (eh *EmailSuppressionHandler) ListSuppressions(c *gin.Context) {
+	req := dtos.ListEmailSuppressionsRequest{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	suppressions, pagination, err := eh.EmailSuppressionUsecase.ListSuppressions(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"suppressions": utils.ConvertEmailSuppressionEntitiesToResponses(suppressions),
+			"pagination":   pagination,
+		},
+	})
+}
+
+func (eh *EmailSuppressionHandler) DeleteSuppression(c *gin.Context) {
+	suppressionID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := eh.EmailSuppressionUsecase.DeleteSuppression(suppressionID)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "delete suppression success"},
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/pkg/shared/template/emails"
+)
+
+// EmailTemplateHandler lets designers look at the emails without triggering them
+type EmailTemplateHandler struct{}
+
+func NewEmailTemplateHandler() *EmailTemplateHandler {
+	return &EmailTemplateHandler{}
+}
+
+func (eh *EmailTemplateHandler) ListTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"templates": emails.Names(),
+			"locales":   emails.Locales,
+		},
+	})
+}
+
+// PreviewTemplate renders a template with fixture data, as the HTML page or
+// the text/plain part. The subject is returned in the X-Email-Subject header.
+func (eh *EmailTemplateHandler) PreviewTemplate(c *gin.Context) {
+	req := dtos.PreviewEmailTemplateRequest{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	msg, err := emails.Preview(c.Param("name"), req.Locale)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, emails.ErrTemplateNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.Header("X-Email-Subject", msg.Subject)
+	if req.Format == "text" {
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(msg.TextBody))
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(msg.HTMLBody))
+}
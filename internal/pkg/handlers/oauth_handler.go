@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/sms"
+	"engine/pkg/shared/utils"
+)
+
+const oauthConsentTemplatePath = "pkg/shared/template/oauth_consent_template.html"
+
+type OAuthHandler struct {
+	OAuthUsecase interfaces.OAuthUsecase
+}
+
+// consentPage is the data rendered by the consent template
+type consentPage struct {
+	ClientName string
+	Scopes     []string
+	Request    dtos.AuthorizeRequest
+	Email      string
+	// Challenge asks for the SMS code instead of the password
+	Challenge string
+	Error     string
+}
+
+func NewOAuthHandler(dbConn *gorm.DB) *OAuthHandler {
+	userRepo := repositories.NewUserRepository(dbConn)
+	sessionRepo := repositories.NewSessionRepository(dbConn)
+	knownDeviceRepo := repositories.NewKnownDeviceRepository(dbConn)
+	outboxMailer := usecases.NewOutboxMailer(repositories.NewEmailOutboxRepository(dbConn))
+	auditUsecase := usecases.NewAuditUsecase(repositories.NewAuditRepository(dbConn))
+	authUsecase := usecases.NewAuthUsecase(userRepo, sessionRepo, knownDeviceRepo, repositories.NewPhoneOTPRepository(dbConn), usecases.NewAuthenticator(userRepo), outboxMailer, sms.Default(), repositories.NewTransactor(dbConn), auditUsecase)
+	oauthUsecase := usecases.NewOAuthUsecase(
+		authUsecase,
+		userRepo,
+		sessionRepo,
+		repositories.NewOAuthClientRepository(dbConn),
+		repositories.NewOAuthAuthorizationCodeRepository(dbConn),
+		repositories.NewOAuthRefreshTokenRepository(dbConn),
+		repositories.NewOAuthDeviceCodeRepository(dbConn),
+		repositories.NewOAuthRevokedTokenRepository(dbConn),
+		repositories.NewPersonalAccessTokenRepository(dbConn),
+	)
+	return &OAuthHandler{
+		OAuthUsecase: oauthUsecase,
+	}
+}
+
+func (oh *OAuthHandler) Authorize(c *gin.Context) {
+	req := dtos.AuthorizeRequest{}
+	_ = c.ShouldBindQuery(&req)
+
+	client, err := oh.OAuthUsecase.ValidateAuthorizeRequest(req)
+	if err != nil {
+		// the redirect_uri can not be trusted, so the error is shown here
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	renderConsent(c, http.StatusOK, consentPage{
+		ClientName: client.Name,
+		Scopes:     scopeList(client.Scopes, req.Scope),
+		Request:    req,
+	})
+}
+
+func (oh *OAuthHandler) AuthorizeDecision(c *gin.Context) {
+	req := dtos.AuthorizeDecisionRequest{}
+	_ = c.ShouldBind(&req)
+
+	redirectURL, err := oh.OAuthUsecase.Authorize(req, utils.GetClientInfo(c))
+	if err != nil {
+		var oauthErr *dtos.OAuthError
+		if errors.As(err, &oauthErr) {
+			c.JSON(http.StatusBadRequest, oauthErr)
+			return
+		}
+
+		client, clientErr := oh.OAuthUsecase.ValidateAuthorizeRequest(req.AuthorizeRequest)
+		if clientErr != nil {
+			c.JSON(http.StatusBadRequest, clientErr)
+			return
+		}
+
+		page := consentPage{
+			ClientName: client.Name,
+			Scopes:     scopeList(client.Scopes, req.Scope),
+			Request:    req.AuthorizeRequest,
+			Email:      req.Email,
+			Error:      err.Error(),
+		}
+
+		// a wrong code can be typed again, the challenge is still valid
+		authErr := &dtos.AuthError{}
+		switch {
+		case errors.As(err, &authErr) && authErr.Code == constants.AuthErrorSMSCodeRequired:
+			page.Challenge = authErr.Challenge
+			page.Error = ""
+		case errors.Is(err, usecases.ErrPhoneOTPInvalid):
+			page.Challenge = req.Challenge
+		}
+
+		renderConsent(c, http.StatusUnauthorized, page)
+		return
+	}
+
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+func (oh *OAuthHandler) Token(c *gin.Context) {
+	req := dtos.TokenRequest{}
+	_ = c.ShouldBind(&req)
+
+	// client_secret_basic takes precedence over client_secret_post
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	res, err := oh.OAuthUsecase.Token(req, utils.GetClientInfo(c))
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (oh *OAuthHandler) Introspect(c *gin.Context) {
+	req := dtos.IntrospectionRequest{}
+	_ = c.ShouldBind(&req)
+
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	c.Header("Cache-Control", "no-store")
+
+	res, err := oh.OAuthUsecase.Introspect(req)
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (oh *OAuthHandler) Revoke(c *gin.Context) {
+	req := dtos.RevocationRequest{}
+	_ = c.ShouldBind(&req)
+
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	err := oh.OAuthUsecase.Revoke(req)
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (oh *OAuthHandler) DeviceAuthorization(c *gin.Context) {
+	req := dtos.DeviceAuthorizationRequest{}
+	_ = c.ShouldBind(&req)
+
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	c.Header("Cache-Control", "no-store")
+
+	res, err := oh.OAuthUsecase.DeviceAuthorization(req)
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetDeviceAuthorization shows the signed-in user what a user code would grant
+func (oh *OAuthHandler) GetDeviceAuthorization(c *gin.Context) {
+	client, deviceCode, err := oh.OAuthUsecase.GetDeviceAuthorization(c.Param("user_code"))
+	if err != nil {
+		c.JSON(deviceErrorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"device_authorization": dtos.DeviceAuthorizationInfoResponse{
+				UserCode:   c.Param("user_code"),
+				ClientName: client.Name,
+				Scopes:     strings.Fields(deviceCode.Scope),
+				ExpiresAt:  deviceCode.ExpiresAt,
+			},
+		},
+	})
+}
+
+func (oh *OAuthHandler) DecideDeviceAuthorization(c *gin.Context) {
+	req := dtos.DeviceDecisionRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	err = oh.OAuthUsecase.DecideDeviceAuthorization(c.GetUint(constants.ContextUserIDKey), c.Param("user_code"), req)
+	if err != nil {
+		c.JSON(deviceErrorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": req.Decision + " device success"},
+	})
+}
+
+func (oh *OAuthHandler) Discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, oh.OAuthUsecase.Discovery())
+}
+
+func (oh *OAuthHandler) JWKS(c *gin.Context) {
+	keys, err := auth.PublicJWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.OAuthError{Code: "server_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+// UserInfo must be used after CheckAuthentication
+func (oh *OAuthHandler) UserInfo(c *gin.Context) {
+	claims := c.GetStringMap(constants.ContextClaimsKey)
+	scope, _ := claims["scope"].(string)
+
+	res, err := oh.OAuthUsecase.UserInfo(c.GetUint(constants.ContextUserIDKey), scope)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.OAuthError{Code: "invalid_token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (oh *OAuthHandler) EndSession(c *gin.Context) {
+	req := dtos.EndSessionRequest{}
+	_ = c.ShouldBind(&req)
+
+	redirectURL, err := oh.OAuthUsecase.EndSession(req)
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	if redirectURL != "" {
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "sign out success"},
+	})
+}
+
+func (oh *OAuthHandler) ListClients(c *gin.Context) {
+	clients, err := oh.OAuthUsecase.ListClients()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"clients": utils.ConvertOAuthClientEntitiesToResponses(clients),
+		},
+	})
+}
+
+func (oh *OAuthHandler) CreateClient(c *gin.Context) {
+	req := dtos.CreateOAuthClientRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	client, plainSecret, err := oh.OAuthUsecase.RegisterClient(c.GetUint(constants.ContextUserIDKey), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			// the plain secret is never shown again
+			"client_secret": plainSecret,
+			"client_info":   utils.ConvertOAuthClientEntityToResponse(client),
+		},
+	})
+}
+
+func (oh *OAuthHandler) DeleteClient(c *gin.Context) {
+	clientID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := oh.OAuthUsecase.DeleteClient(clientID)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "delete client success"},
+	})
+}
+
+func renderConsent(c *gin.Context, status int, page consentPage) {
+	t, err := template.ParseFiles(oauthConsentTemplatePath)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("X-Frame-Options", "DENY")
+	_ = t.Execute(c.Writer, page)
+}
+
+// scopeList is the scope shown on the consent page, every client scope when none was requested
+func scopeList(clientScopes string, requested string) []string {
+	if requested == "" {
+		return strings.Fields(clientScopes)
+	}
+	return strings.Fields(requested)
+}
+
+// deviceErrorStatus maps an unknown user code to 404 and a used or expired one to 400
+func deviceErrorStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusBadRequest
+}
+
+// writeOAuthError writes the RFC 6749 error body, invalid_client is a 401
+func writeOAuthError(c *gin.Context, err error) {
+	var oauthErr *dtos.OAuthError
+	if !errors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, dtos.OAuthError{Code: "server_error"})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == "invalid_client" {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		status = http.StatusUnauthorized
+	}
+	c.JSON(status, oauthErr)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/utils"
+)
+
+type OrganizationHandler struct {
+	OrganizationUsecase interfaces.OrganizationUsecase
+}
+
+func NewOrganizationHandler(dbConn *gorm.DB) *OrganizationHandler {
+	orgRepo := repositories.NewOrganizationRepository(dbConn)
+	organizationUsecase := usecases.NewOrganizationUsecase(orgRepo, repositories.NewOrganizationAttributeRepository(dbConn))
+	return &OrganizationHandler{
+		OrganizationUsecase: organizationUsecase,
+	}
+}
+
+func (oh *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	orgs, err := oh.OrganizationUsecase.ListOrganizations()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"organizations": utils.ConvertOrganizationEntitiesToResponses(orgs),
+		},
+	})
+}
+
+func (oh *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	req := dtos.CreateOrganizationRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	org, err := oh.OrganizationUsecase.CreateOrganization(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"organization": utils.ConvertOrganizationEntityToResponse(org),
+		},
+	})
+}
+
+func (oh *OrganizationHandler) ListAttributes(c *gin.Context) {
+	orgID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	attrs, err := oh.OrganizationUsecase.ListAttributes(orgID)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"attributes": utils.ConvertOrganizationAttributeEntitiesToResponses(attrs),
+		},
+	})
+}
+
+func (oh *OrganizationHandler) CreateAttribute(c *gin.Context) {
+	orgID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	req := dtos.CreateOrganizationAttributeRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	attr, err := oh.OrganizationUsecase.CreateAttribute(orgID, req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"attribute": utils.ConvertOrganizationAttributeEntityToResponse(attr),
+		},
+	})
+}
+
+func (oh *OrganizationHandler) DeleteAttribute(c *gin.Context) {
+	orgID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	attrID, err := strconv.ParseUint(c.Param("attribute_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: "invalid attribute id",
+			},
+		})
+		return
+	}
+
+	err = oh.OrganizationUsecase.DeleteAttribute(orgID, uint(attrID))
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "delete attribute success"},
+	})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/utils"
+)
+
+type PersonalAccessTokenHandler struct {
+	TokenUsecase interfaces.PersonalAccessTokenUsecase
+}
+
+func NewPersonalAccessTokenHandler(dbConn *gorm.DB) *PersonalAccessTokenHandler {
+	userRepo := repositories.NewUserRepository(dbConn)
+	tokenRepo := repositories.NewPersonalAccessTokenRepository(dbConn)
+	auditUsecase := usecases.NewAuditUsecase(repositories.NewAuditRepository(dbConn))
+	tokenUsecase := usecases.NewPersonalAccessTokenUsecase(userRepo, tokenRepo, auditUsecase)
+	return &PersonalAccessTokenHandler{
+		TokenUsecase: tokenUsecase,
+	}
+}
+
+func (ph *PersonalAccessTokenHandler) ListTokens(c *gin.Context) {
+	tokens, err := ph.TokenUsecase.ListTokens(c.GetUint(constants.ContextUserIDKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"tokens": utils.ConvertPersonalAccessTokenEntitiesToResponses(tokens),
+		},
+	})
+}
+
+func (ph *PersonalAccessTokenHandler) CreateToken(c *gin.Context) {
+	req := dtos.CreatePersonalAccessTokenRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	token, plainToken, err := ph.TokenUsecase.CreateToken(c.GetUint(constants.ContextUserIDKey), req, utils.GetClientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			// the plain token is never shown again
+			"token":      plainToken,
+			"token_info": utils.ConvertPersonalAccessTokenEntityToResponse(token),
+		},
+	})
+}
+
+func (ph *PersonalAccessTokenHandler) RevokeToken(c *gin.Context) {
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: "invalid id",
+			},
+		})
+		return
+	}
+
+	err = ph.TokenUsecase.RevokeToken(c.GetUint(constants.ContextUserIDKey), uint(tokenID), utils.GetClientInfo(c))
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "revoke token success"},
+	})
+}
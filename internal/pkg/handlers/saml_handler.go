@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/sms"
+	"engine/pkg/shared/utils"
+)
+
+type SAMLHandler struct {
+	SAMLUsecase interfaces.SAMLUsecase
+}
+
+func NewSAMLHandler(dbConn *gorm.DB) *SAMLHandler {
+	userRepo := repositories.NewUserRepository(dbConn)
+	sessionRepo := repositories.NewSessionRepository(dbConn)
+	knownDeviceRepo := repositories.NewKnownDeviceRepository(dbConn)
+	outboxMailer := usecases.NewOutboxMailer(repositories.NewEmailOutboxRepository(dbConn))
+	auditUsecase := usecases.NewAuditUsecase(repositories.NewAuditRepository(dbConn))
+	authUsecase := usecases.NewAuthUsecase(userRepo, sessionRepo, knownDeviceRepo, repositories.NewPhoneOTPRepository(dbConn), usecases.NewAuthenticator(userRepo), outboxMailer, sms.Default(), repositories.NewTransactor(dbConn), auditUsecase)
+	samlUsecase := usecases.NewSAMLUsecase(
+		authUsecase,
+		userRepo,
+		repositories.NewOrganizationRepository(dbConn),
+		repositories.NewSAMLConnectionRepository(dbConn),
+		repositories.NewSAMLRequestRepository(dbConn),
+		repositories.NewSAMLAssertionRepository(dbConn),
+		auditUsecase,
+	)
+	return &SAMLHandler{
+		SAMLUsecase: samlUsecase,
+	}
+}
+
+func (sh *SAMLHandler) Metadata(c *gin.Context) {
+	metadata, err := sh.SAMLUsecase.Metadata(c.Param("slug"))
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+func (sh *SAMLHandler) Login(c *gin.Context) {
+	res, err := sh.SAMLUsecase.Login(c.Param("slug"))
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	if res.PostForm != nil {
+		page := append([]byte("<!DOCTYPE html><html><body>"), res.PostForm...)
+		page = append(page, []byte("</body></html>")...)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+		return
+	}
+
+	c.Redirect(http.StatusFound, res.RedirectURL)
+}
+
+// ACS is the Assertion Consumer Service the identity provider posts to
+func (sh *SAMLHandler) ACS(c *gin.Context) {
+	req := dtos.SAMLACSRequest{}
+	err := c.ShouldBind(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	user, token, err := sh.SAMLUsecase.ConsumeAssertion(c.Param("slug"), req, utils.GetClientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"access_token": token,
+			"user_info":    utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
+}
+
+func (sh *SAMLHandler) GetConnection(c *gin.Context) {
+	orgID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	conn, err := sh.SAMLUsecase.GetConnection(orgID)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"saml_connection": utils.ConvertSAMLConnectionEntityToResponse(conn),
+		},
+	})
+}
+
+func (sh *SAMLHandler) ConfigureConnection(c *gin.Context) {
+	orgID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	req := dtos.SAMLConnectionRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	conn, err := sh.SAMLUsecase.ConfigureConnection(orgID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"saml_connection": utils.ConvertSAMLConnectionEntityToResponse(conn),
+		},
+	})
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/utils"
+)
+
+type SCIMHandler struct {
+	SCIMUsecase interfaces.SCIMUsecase
+}
+
+func NewSCIMHandler(dbConn *gorm.DB) *SCIMHandler {
+	scimUsecase := usecases.NewSCIMUsecase(
+		repositories.NewUserRepository(dbConn),
+		repositories.NewSessionRepository(dbConn),
+		repositories.NewPersonalAccessTokenRepository(dbConn),
+		repositories.NewOrganizationRepository(dbConn),
+		repositories.NewOrganizationGroupRepository(dbConn),
+		repositories.NewSCIMTokenRepository(dbConn),
+	)
+	return &SCIMHandler{
+		SCIMUsecase: scimUsecase,
+	}
+}
+
+func (sh *SCIMHandler) ListTokens(c *gin.Context) {
+	orgID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := sh.SCIMUsecase.ListTokens(orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"scim_tokens": utils.ConvertSCIMTokenEntitiesToResponses(tokens),
+		},
+	})
+}
+
+func (sh *SCIMHandler) CreateToken(c *gin.Context) {
+	orgID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	req := dtos.CreateSCIMTokenRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	token, plainToken, err := sh.SCIMUsecase.CreateToken(orgID, req)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			// the plain token is never shown again
+			"token":      plainToken,
+			"token_info": utils.ConvertSCIMTokenEntityToResponse(token),
+		},
+	})
+}
+
+func (sh *SCIMHandler) RevokeToken(c *gin.Context) {
+	orgID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("token_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: "invalid token id",
+			},
+		})
+		return
+	}
+
+	err = sh.SCIMUsecase.RevokeToken(orgID, uint(tokenID))
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "revoke token success"},
+	})
+}
+
+func (sh *SCIMHandler) ListUsers(c *gin.Context) {
+	req := dtos.SCIMListRequest{}
+	if !bindSCIMQuery(c, &req) {
+		return
+	}
+
+	res, err := sh.SCIMUsecase.ListUsers(c.GetUint(constants.ContextOrgIDKey), req)
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, res)
+}
+
+func (sh *SCIMHandler) GetUser(c *gin.Context) {
+	res, err := sh.SCIMUsecase.GetUser(c.GetUint(constants.ContextOrgIDKey), c.Param("id"))
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, res)
+}
+
+func (sh *SCIMHandler) CreateUser(c *gin.Context) {
+	req := dtos.SCIMUser{}
+	if !bindSCIMJSON(c, &req) {
+		return
+	}
+
+	res, err := sh.SCIMUsecase.CreateUser(c.GetUint(constants.ContextOrgIDKey), req)
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusCreated, res)
+}
+
+func (sh *SCIMHandler) ReplaceUser(c *gin.Context) {
+	req := dtos.SCIMUser{}
+	if !bindSCIMJSON(c, &req) {
+		return
+	}
+
+	res, err := sh.SCIMUsecase.ReplaceUser(c.GetUint(constants.ContextOrgIDKey), c.Param("id"), req)
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, res)
+}
+
+func (sh *SCIMHandler) PatchUser(c *gin.Context) {
+	req := dtos.SCIMPatchRequest{}
+	if !bindSCIMJSON(c, &req) {
+		return
+	}
+
+	res, err := sh.SCIMUsecase.PatchUser(c.GetUint(constants.ContextOrgIDKey), c.Param("id"), req)
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, res)
+}
+
+func (sh *SCIMHandler) DeleteUser(c *gin.Context) {
+	err := sh.SCIMUsecase.DeleteUser(c.GetUint(constants.ContextOrgIDKey), c.Param("id"))
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (sh *SCIMHandler) ListGroups(c *gin.Context) {
+	req := dtos.SCIMListRequest{}
+	if !bindSCIMQuery(c, &req) {
+		return
+	}
+
+	res, err := sh.SCIMUsecase.ListGroups(c.GetUint(constants.ContextOrgIDKey), req)
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, res)
+}
+
+func (sh *SCIMHandler) GetGroup(c *gin.Context) {
+	res, err := sh.SCIMUsecase.GetGroup(c.GetUint(constants.ContextOrgIDKey), c.Param("id"))
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, res)
+}
+
+func (sh *SCIMHandler) CreateGroup(c *gin.Context) {
+	req := dtos.SCIMGroup{}
+	if !bindSCIMJSON(c, &req) {
+		return
+	}
+
+	res, err := sh.SCIMUsecase.CreateGroup(c.GetUint(constants.ContextOrgIDKey), req)
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusCreated, res)
+}
+
+func (sh *SCIMHandler) ReplaceGroup(c *gin.Context) {
+	req := dtos.SCIMGroup{}
+	if !bindSCIMJSON(c, &req) {
+		return
+	}
+
+	res, err := sh.SCIMUsecase.ReplaceGroup(c.GetUint(constants.ContextOrgIDKey), c.Param("id"), req)
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, res)
+}
+
+func (sh *SCIMHandler) PatchGroup(c *gin.Context) {
+	req := dtos.SCIMPatchRequest{}
+	if !bindSCIMJSON(c, &req) {
+		return
+	}
+
+	res, err := sh.SCIMUsecase.PatchGroup(c.GetUint(constants.ContextOrgIDKey), c.Param("id"), req)
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, res)
+}
+
+func (sh *SCIMHandler) DeleteGroup(c *gin.Context) {
+	err := sh.SCIMUsecase.DeleteGroup(c.GetUint(constants.ContextOrgIDKey), c.Param("id"))
+	if err != nil {
+		writeSCIMError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func bindSCIMJSON(c *gin.Context, req interface{}) bool {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		writeSCIMError(c, &dtos.SCIMError{
+			Status:   strconv.Itoa(http.StatusBadRequest),
+			ScimType: "invalidSyntax",
+			Detail:   err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func bindSCIMQuery(c *gin.Context, req interface{}) bool {
+	err := c.ShouldBindQuery(req)
+	if err != nil {
+		writeSCIMError(c, &dtos.SCIMError{
+			Status: strconv.Itoa(http.StatusBadRequest),
+			Detail: err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func writeSCIM(c *gin.Context, status int, res interface{}) {
+	c.Header("Content-Type", constants.SCIMContentType)
+	c.JSON(status, res)
+}
+
+// writeSCIMError answers with the SCIM error body, errors that are not a
+// *dtos.SCIMError are internal
+func writeSCIMError(c *gin.Context, err error) {
+	scimErr := &dtos.SCIMError{}
+	if !errors.As(err, &scimErr) {
+		scimErr = &dtos.SCIMError{
+			Status: strconv.Itoa(http.StatusInternalServerError),
+			Detail: err.Error(),
+		}
+	}
+	scimErr.Schemas = []string{constants.SCIMSchemaError}
+
+	status, convErr := strconv.Atoi(scimErr.Status)
+	if convErr != nil {
+		status = http.StatusInternalServerError
+	}
+	writeSCIM(c, status, scimErr)
+}
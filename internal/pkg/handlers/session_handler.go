@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/utils"
+)
+
+type SessionHandler struct {
+	SessionUsecase interfaces.SessionUsecase
+}
+
+func NewSessionHandler(dbConn *gorm.DB) *SessionHandler {
+	sessionRepo := repositories.NewSessionRepository(dbConn)
+	sessionUsecase := usecases.NewSessionUsecase(sessionRepo)
+	return &SessionHandler{
+		SessionUsecase: sessionUsecase,
+	}
+}
+
+func (sh *SessionHandler) ListSessions(c *gin.Context) {
+	sessions, err := sh.SessionUsecase.ListSessions(c.GetUint(constants.ContextUserIDKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"sessions": utils.ConvertSessionEntitiesToResponses(sessions, c.GetUint(constants.ContextSessionIDKey)),
+		},
+	})
+}
+
+func (sh *SessionHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: "invalid id",
+			},
+		})
+		return
+	}
+
+	err = sh.SessionUsecase.RevokeSession(c.GetUint(constants.ContextUserIDKey), uint(sessionID))
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": "revoke session success"},
+	})
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/utils"
+)
+
+type UserHandler struct {
+	UserUsecase interfaces.UserUsecase
+}
+
+func NewUserHandler(dbConn *gorm.DB) *UserHandler {
+	userRepo := repositories.NewUserRepository(dbConn)
+	userUsecase := usecases.NewUserUsecase(
+		userRepo,
+		repositories.NewSessionRepository(dbConn),
+		repositories.NewOAuthRefreshTokenRepository(dbConn),
+		repositories.NewOrganizationAttributeRepository(dbConn),
+		usecases.NewOutboxMailer(repositories.NewEmailOutboxRepository(dbConn)),
+	)
+	return &UserHandler{
+		UserUsecase: userUsecase,
+	}
+}
+
+func (uh *UserHandler) ListUsers(c *gin.Context) {
+	req := dtos.ListUsersRequest{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	users, pagination, err := uh.UserUsecase.ListUsers(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"users":      utils.ConvertUserEntitiesToUserResponses(users),
+			"pagination": pagination,
+		},
+	})
+}
+
+func (uh *UserHandler) GetUser(c *gin.Context) {
+	userID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := uh.UserUsecase.GetUser(userID)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"user_info": utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
+}
+
+func (uh *UserHandler) CreateUser(c *gin.Context) {
+	req := dtos.AdminCreateUserRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	user, err := uh.UserUsecase.CreateUser(req)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"user_info": utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
+}
+
+func (uh *UserHandler) UpdateUser(c *gin.Context) {
+	userID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	req := dtos.AdminUpdateUserRequest{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	user, err := uh.UserUsecase.UpdateUser(userID, req)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data: gin.H{
+			"user_info": utils.ConvertUserEntityToUserResponse(user),
+		},
+	})
+}
+
+func (uh *UserHandler) SuspendUser(c *gin.Context) {
+	uh.handleUserAction(c, uh.UserUsecase.SuspendUser, "suspend user success")
+}
+
+func (uh *UserHandler) ReactivateUser(c *gin.Context) {
+	uh.handleUserAction(c, uh.UserUsecase.ReactivateUser, "reactivate user success")
+}
+
+func (uh *UserHandler) ForcePasswordReset(c *gin.Context) {
+	uh.handleUserAction(c, uh.UserUsecase.ForcePasswordReset, "send reset password email success")
+}
+
+func (uh *UserHandler) DeleteUser(c *gin.Context) {
+	uh.handleUserAction(c, uh.UserUsecase.DeleteUser, "delete user success")
+}
+
+func (uh *UserHandler) handleUserAction(c *gin.Context, action func(userID uint) error, message string) {
+	userID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := action(userID)
+	if err != nil {
+		c.JSON(errorStatus(err), dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"message": message},
+	})
+}
+
+// parseIDParam reads the :id path param, writes a 400 response when invalid
+func parseIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dtos.BaseResponse{
+			Status: "failed",
+			Error: &dtos.ErrorResponse{
+				ErrorMessage: "invalid id",
+			},
+		})
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// errorStatus maps usecase errors to HTTP status codes
+func errorStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, usecases.ErrInvalidMetadata) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, usecases.ErrEmailExists) {
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
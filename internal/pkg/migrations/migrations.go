@@ -7,7 +7,39 @@ import (
 )
 
 func Migrate(dbConn *gorm.DB) error {
-	err := dbConn.AutoMigrate(entities.User{})
+	err := dbConn.AutoMigrate(
+		entities.User{},
+		entities.AuditEvent{},
+		entities.AuditChainHead{},
+		entities.Session{},
+		entities.KnownDevice{},
+		entities.EmailChange{},
+		entities.PersonalAccessToken{},
+		entities.OAuthClient{},
+		entities.OAuthAuthorizationCode{},
+		entities.OAuthRefreshToken{},
+		entities.OAuthDeviceCode{},
+		entities.OAuthRevokedToken{},
+		entities.Organization{},
+		entities.OrganizationMember{},
+		entities.OrganizationGroup{},
+		entities.OrganizationGroupMember{},
+		entities.SCIMToken{},
+		entities.SAMLConnection{},
+		entities.SAMLRequest{},
+		entities.SAMLAssertion{},
+		entities.EmailOutbox{},
+		entities.EmailSuppression{},
+		entities.PhoneOTP{},
+		entities.OrganizationAttribute{},
+	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// group members used to be soft deleted, those rows still hold the unique
+	// index and keep the user out of the group
+	return dbConn.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Delete(&entities.OrganizationGroupMember{}).Error
 }
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type AuditRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewAuditRepository(dbConn *gorm.DB) interfaces.AuditRepository {
+	return &AuditRepository{
+		DBConn: dbConn,
+	}
+}
+
+// AppendAuditEvent links the event to the chain head and stores it, hashFn
+// is called after PrevHash is set
+func (ar *AuditRepository) AppendAuditEvent(event entities.AuditEvent, hashFn func(event entities.AuditEvent) string) (entities.AuditEvent, error) {
+	err := ar.DBConn.Transaction(func(tx *gorm.DB) error {
+		head := entities.AuditChainHead{ID: 1}
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&head).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&head, head.ID).Error
+		if err != nil {
+			return err
+		}
+
+		event.PrevHash = head.LastHash
+		event.Hash = hashFn(event)
+		err = tx.Create(&event).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&head).Updates(map[string]interface{}{
+			"last_event_id": event.ID,
+			"last_hash":     event.Hash,
+		}).Error
+	})
+
+	return event, err
+}
+
+func (ar *AuditRepository) TakeAuditEvent(id uint) (entities.AuditEvent, error) {
+	event := entities.AuditEvent{}
+	result := ar.DBConn.Where("id = ?", id).Take(&event)
+
+	return event, result.Error
+}
+
+func (ar *AuditRepository) TakeChainHead() (entities.AuditChainHead, error) {
+	head := entities.AuditChainHead{}
+	result := ar.DBConn.Where("id = ?", 1).Take(&head)
+
+	return head, result.Error
+}
+
+func (ar *AuditRepository) PaginateByFilter(filter dtos.AuditEventFilter, offset int, limit int) ([]entities.AuditEvent, int64, error) {
+	events := []entities.AuditEvent{}
+	var total int64
+
+	query := ar.filterQuery(filter)
+	result := query.Count(&total)
+	if result.Error != nil {
+		return events, 0, result.Error
+	}
+
+	result = query.Order("id desc").Offset(offset).Limit(limit).Find(&events)
+	return events, total, result.Error
+}
+
+func (ar *AuditRepository) IterateByFilter(filter dtos.AuditEventFilter, fn func(event entities.AuditEvent) error) error {
+	rows, err := ar.filterQuery(filter).Order("id asc").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event := entities.AuditEvent{}
+		err = ar.DBConn.ScanRows(rows, &event)
+		if err != nil {
+			return err
+		}
+
+		err = fn(event)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (ar *AuditRepository) filterQuery(filter dtos.AuditEventFilter) *gorm.DB {
+	query := ar.DBConn.Model(&entities.AuditEvent{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip_address = ?", filter.IP)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	return query
+}
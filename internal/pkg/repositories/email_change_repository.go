@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type EmailChangeRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewEmailChangeRepository(dbConn *gorm.DB) interfaces.EmailChangeRepository {
+	return &EmailChangeRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (er *EmailChangeRepository) CreateEmailChange(change entities.EmailChange) (entities.EmailChange, error) {
+	result := er.DBConn.Create(&change)
+
+	return change, result.Error
+}
+
+func (er *EmailChangeRepository) TakeByConditions(conditions map[string]interface{}) (entities.EmailChange, error) {
+	change := entities.EmailChange{}
+	result := er.DBConn.Where(conditions).Take(&change)
+
+	return change, result.Error
+}
+
+func (er *EmailChangeRepository) UpdateEmailChange(change entities.EmailChange, data map[string]interface{}) error {
+	result := er.DBConn.Model(&change).Where("id = ?", change.ID).Updates(data)
+
+	return result.Error
+}
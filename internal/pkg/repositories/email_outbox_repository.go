@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+)
+
+type EmailOutboxRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewEmailOutboxRepository(dbConn *gorm.DB) interfaces.EmailOutboxRepository {
+	return &EmailOutboxRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (eor *EmailOutboxRepository) CreateEmail(email entities.EmailOutbox) (entities.EmailOutbox, error) {
+	result := eor.DBConn.Create(&email)
+
+	return email, result.Error
+}
+
+func (eor *EmailOutboxRepository) FindDue(now time.Time, limit int) ([]entities.EmailOutbox, error) {
+	emails := []entities.EmailOutbox{}
+	result := eor.DBConn.
+		Where("status = ? AND next_attempt_at <= ?", constants.EmailStatusPending, now).
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&emails)
+
+	return emails, result.Error
+}
+
+func (eor *EmailOutboxRepository) ClaimEmail(email entities.EmailOutbox, lockUntil time.Time) (bool, error) {
+	now := time.Now()
+	result := eor.DBConn.Model(&entities.EmailOutbox{}).
+		Where("id = ? AND status = ?", email.ID, constants.EmailStatusPending).
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Update("locked_until", lockUntil)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (eor *EmailOutboxRepository) TakeByConditions(conditions map[string]interface{}) (entities.EmailOutbox, error) {
+	email := entities.EmailOutbox{}
+	result := eor.DBConn.Where(conditions).Take(&email)
+
+	return email, result.Error
+}
+
+func (eor *EmailOutboxRepository) PaginateByConditions(conditions map[string]interface{}, offset int, limit int) ([]entities.EmailOutbox, int64, error) {
+	emails := []entities.EmailOutbox{}
+	var total int64
+
+	query := eor.DBConn.Model(&entities.EmailOutbox{}).Where(conditions)
+	result := query.Count(&total)
+	if result.Error != nil {
+		return emails, 0, result.Error
+	}
+
+	result = query.Order("id desc").Offset(offset).Limit(limit).Find(&emails)
+	return emails, total, result.Error
+}
+
+func (eor *EmailOutboxRepository) UpdateEmail(email entities.EmailOutbox, data map[string]interface{}) error {
+	result := eor.DBConn.Model(&email).Updates(data)
+
+	return result.Error
+}
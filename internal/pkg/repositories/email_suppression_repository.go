@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/mailer"
+)
+
+type EmailSuppressionRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewEmailSuppressionRepository(dbConn *gorm.DB) interfaces.EmailSuppressionRepository {
+	return &EmailSuppressionRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (esr *EmailSuppressionRepository) SaveSuppression(suppression entities.EmailSuppression) (entities.EmailSuppression, error) {
+	suppression.Email = mailer.NormalizeAddress(suppression.Email)
+	result := esr.DBConn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "detail", "source", "updated_at"}),
+	}).Create(&suppression)
+	if result.Error != nil {
+		return suppression, result.Error
+	}
+
+	return esr.TakeByConditions(map[string]interface{}{
+		"email": suppression.Email,
+	})
+}
+
+func (esr *EmailSuppressionRepository) IsSuppressed(email string) (bool, error) {
+	var count int64
+	result := esr.DBConn.Model(&entities.EmailSuppression{}).
+		Where("email = ?", mailer.NormalizeAddress(email)).
+		Count(&count)
+
+	return count > 0, result.Error
+}
+
+func (esr *EmailSuppressionRepository) TakeByConditions(conditions map[string]interface{}) (entities.EmailSuppression, error) {
+	suppression := entities.EmailSuppression{}
+	result := esr.DBConn.Where(conditions).Take(&suppression)
+
+	return suppression, result.Error
+}
+
+func (esr *EmailSuppressionRepository) PaginateByConditions(conditions map[string]interface{}, offset int, limit int) ([]entities.EmailSuppression, int64, error) {
+	suppressions := []entities.EmailSuppression{}
+	var total int64
+
+	query := esr.DBConn.Model(&entities.EmailSuppression{}).Where(conditions)
+	result := query.Count(&total)
+	if result.Error != nil {
+		return suppressions, 0, result.Error
+	}
+
+	result = query.Order("id desc").Offset(offset).Limit(limit).Find(&suppressions)
+	return suppressions, total, result.Error
+}
+
+func (esr *EmailSuppressionRepository) DeleteSuppression(suppression entities.EmailSuppression) error {
+	// unscoped, a soft deleted row would still hold the unique email
+	result := esr.DBConn.Unscoped().Delete(&suppression)
+
+	return result.Error
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"testing"
+
+	"engine/internal/pkg/domains/models/entities"
+	"engine/internal/pkg/repositories/repotest"
+)
+
+func TestEmailSuppressionCaseInsensitive(t *testing.T) {
+	dbConn := repotest.NewDB(t)
+	esr := &EmailSuppressionRepository{DBConn: dbConn}
+	ur := &UserRepository{DBConn: dbConn}
+
+	_, err := esr.SaveSuppression(entities.EmailSuppression{Email: "Jane.Doe@Acme.test", Reason: "bounce"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	suppressed, err := esr.IsSuppressed("JANE.DOE@acme.test")
+	if err != nil || !suppressed {
+		t.Errorf("IsSuppressed() = %v, %v, want true", suppressed, err)
+	}
+
+	if _, err := ur.CreateUser(entities.User{Email: "Jane.Doe@Acme.test", Username: "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+	users, err := ur.FindByEmail("jane.doe@acme.test")
+	if err != nil || len(users) != 1 {
+		t.Errorf("FindByEmail() = %v, %v, want the user", users, err)
+	}
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type KnownDeviceRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewKnownDeviceRepository(dbConn *gorm.DB) interfaces.KnownDeviceRepository {
+	return &KnownDeviceRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (kr *KnownDeviceRepository) CreateKnownDevice(device entities.KnownDevice) (entities.KnownDevice, error) {
+	result := kr.DBConn.Create(&device)
+
+	return device, result.Error
+}
+
+func (kr *KnownDeviceRepository) FindByConditions(conditions map[string]interface{}) ([]entities.KnownDevice, error) {
+	devices := []entities.KnownDevice{}
+
+	result := kr.DBConn.Where(conditions).Find(&devices)
+	return devices, result.Error
+}
+
+func (kr *KnownDeviceRepository) UpdateKnownDevice(device entities.KnownDevice, data map[string]interface{}) error {
+	result := kr.DBConn.Model(&device).Where("id = ?", device.ID).Updates(data)
+
+	return result.Error
+}
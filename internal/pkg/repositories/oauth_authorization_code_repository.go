@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OAuthAuthorizationCodeRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewOAuthAuthorizationCodeRepository(dbConn *gorm.DB) interfaces.OAuthAuthorizationCodeRepository {
+	return &OAuthAuthorizationCodeRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (acr *OAuthAuthorizationCodeRepository) CreateCode(code entities.OAuthAuthorizationCode) (entities.OAuthAuthorizationCode, error) {
+	result := acr.DBConn.Create(&code)
+
+	return code, result.Error
+}
+
+func (acr *OAuthAuthorizationCodeRepository) TakeByConditions(conditions map[string]interface{}) (entities.OAuthAuthorizationCode, error) {
+	code := entities.OAuthAuthorizationCode{}
+	result := acr.DBConn.Where(conditions).Take(&code)
+
+	return code, result.Error
+}
+
+func (acr *OAuthAuthorizationCodeRepository) ConsumeCode(code entities.OAuthAuthorizationCode) (bool, error) {
+	result := acr.DBConn.Model(&entities.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", time.Now())
+
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OAuthClientRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewOAuthClientRepository(dbConn *gorm.DB) interfaces.OAuthClientRepository {
+	return &OAuthClientRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (cr *OAuthClientRepository) CreateClient(client entities.OAuthClient) (entities.OAuthClient, error) {
+	result := cr.DBConn.Create(&client)
+
+	return client, result.Error
+}
+
+func (cr *OAuthClientRepository) FindByConditions(conditions map[string]interface{}) ([]entities.OAuthClient, error) {
+	clients := []entities.OAuthClient{}
+
+	result := cr.DBConn.Where(conditions).Find(&clients)
+	return clients, result.Error
+}
+
+func (cr *OAuthClientRepository) TakeByConditions(conditions map[string]interface{}) (entities.OAuthClient, error) {
+	client := entities.OAuthClient{}
+	result := cr.DBConn.Where(conditions).Take(&client)
+
+	return client, result.Error
+}
+
+func (cr *OAuthClientRepository) DeleteClient(client entities.OAuthClient) error {
+	result := cr.DBConn.Delete(&client)
+
+	return result.Error
+}
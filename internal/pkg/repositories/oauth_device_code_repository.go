@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OAuthDeviceCodeRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewOAuthDeviceCodeRepository(dbConn *gorm.DB) interfaces.OAuthDeviceCodeRepository {
+	return &OAuthDeviceCodeRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (dcr *OAuthDeviceCodeRepository) CreateDeviceCode(deviceCode entities.OAuthDeviceCode) (entities.OAuthDeviceCode, error) {
+	result := dcr.DBConn.Create(&deviceCode)
+
+	return deviceCode, result.Error
+}
+
+func (dcr *OAuthDeviceCodeRepository) TakeByConditions(conditions map[string]interface{}) (entities.OAuthDeviceCode, error) {
+	deviceCode := entities.OAuthDeviceCode{}
+	result := dcr.DBConn.Where(conditions).Take(&deviceCode)
+
+	return deviceCode, result.Error
+}
+
+func (dcr *OAuthDeviceCodeRepository) UpdateDeviceCode(deviceCode entities.OAuthDeviceCode, data map[string]interface{}) error {
+	result := dcr.DBConn.Model(&deviceCode).Updates(data)
+
+	return result.Error
+}
+
+func (dcr *OAuthDeviceCodeRepository) DecideDeviceCode(deviceCode entities.OAuthDeviceCode, data map[string]interface{}) (bool, error) {
+	result := dcr.DBConn.Model(&entities.OAuthDeviceCode{}).
+		Where("id = ? AND approved_at IS NULL AND denied_at IS NULL", deviceCode.ID).
+		Updates(data)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (dcr *OAuthDeviceCodeRepository) ConsumeDeviceCode(deviceCode entities.OAuthDeviceCode) (bool, error) {
+	result := dcr.DBConn.Model(&entities.OAuthDeviceCode{}).
+		Where("id = ? AND approved_at IS NOT NULL AND used_at IS NULL", deviceCode.ID).
+		Update("used_at", time.Now())
+
+	return result.RowsAffected == 1, result.Error
+}
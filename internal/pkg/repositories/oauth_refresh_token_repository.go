@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OAuthRefreshTokenRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewOAuthRefreshTokenRepository(dbConn *gorm.DB) interfaces.OAuthRefreshTokenRepository {
+	return &OAuthRefreshTokenRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (rtr *OAuthRefreshTokenRepository) CreateToken(token entities.OAuthRefreshToken) (entities.OAuthRefreshToken, error) {
+	result := rtr.DBConn.Create(&token)
+
+	return token, result.Error
+}
+
+func (rtr *OAuthRefreshTokenRepository) TakeByConditions(conditions map[string]interface{}) (entities.OAuthRefreshToken, error) {
+	token := entities.OAuthRefreshToken{}
+	result := rtr.DBConn.Where(conditions).Take(&token)
+
+	return token, result.Error
+}
+
+func (rtr *OAuthRefreshTokenRepository) RevokeToken(token entities.OAuthRefreshToken) (bool, error) {
+	result := rtr.DBConn.Model(&entities.OAuthRefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", token.ID).
+		Update("revoked_at", time.Now())
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (rtr *OAuthRefreshTokenRepository) RevokeBySessionID(sessionID uint) error {
+	result := rtr.DBConn.Model(&entities.OAuthRefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now())
+
+	return result.Error
+}
+
+func (rtr *OAuthRefreshTokenRepository) RevokeByUserID(userID uint) error {
+	result := rtr.DBConn.Model(&entities.OAuthRefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+
+	return result.Error
+}
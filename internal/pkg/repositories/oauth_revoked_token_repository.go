@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OAuthRevokedTokenRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewOAuthRevokedTokenRepository(dbConn *gorm.DB) interfaces.OAuthRevokedTokenRepository {
+	return &OAuthRevokedTokenRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (rvr *OAuthRevokedTokenRepository) RevokeJTI(token entities.OAuthRevokedToken) error {
+	result := rvr.DBConn.Clauses(clause.OnConflict{DoNothing: true}).Create(&token)
+
+	return result.Error
+}
+
+func (rvr *OAuthRevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	var count int64
+	result := rvr.DBConn.Model(&entities.OAuthRevokedToken{}).
+		Where("jti = ?", jti).
+		Count(&count)
+
+	return count > 0, result.Error
+}
+
+func (rvr *OAuthRevokedTokenRepository) DeleteExpired(before time.Time) error {
+	result := rvr.DBConn.Unscoped().
+		Where("expires_at < ?", before).
+		Delete(&entities.OAuthRevokedToken{})
+
+	return result.Error
+}
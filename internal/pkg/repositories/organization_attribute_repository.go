@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OrganizationAttributeRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewOrganizationAttributeRepository(dbConn *gorm.DB) interfaces.OrganizationAttributeRepository {
+	return &OrganizationAttributeRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (oar *OrganizationAttributeRepository) CreateAttribute(attr entities.OrganizationAttribute) (entities.OrganizationAttribute, error) {
+	result := oar.DBConn.Create(&attr)
+
+	return attr, result.Error
+}
+
+func (oar *OrganizationAttributeRepository) FindByConditions(conditions map[string]interface{}) ([]entities.OrganizationAttribute, error) {
+	attrs := []entities.OrganizationAttribute{}
+	result := oar.DBConn.Where(conditions).Order("name asc").Find(&attrs)
+
+	return attrs, result.Error
+}
+
+func (oar *OrganizationAttributeRepository) FindByUserID(userID uint) ([]entities.OrganizationAttribute, error) {
+	attrs := []entities.OrganizationAttribute{}
+	result := oar.DBConn.
+		Joins("JOIN organization_members ON organization_members.organization_id = organization_attributes.organization_id AND organization_members.deleted_at IS NULL").
+		Where("organization_members.user_id = ?", userID).
+		Order("organization_attributes.name asc").
+		Find(&attrs)
+
+	return attrs, result.Error
+}
+
+func (oar *OrganizationAttributeRepository) TakeByConditions(conditions map[string]interface{}) (entities.OrganizationAttribute, error) {
+	attr := entities.OrganizationAttribute{}
+	result := oar.DBConn.Where(conditions).Take(&attr)
+
+	return attr, result.Error
+}
+
+func (oar *OrganizationAttributeRepository) DeleteAttribute(attr entities.OrganizationAttribute) error {
+	// unscoped, so that the name can be declared again
+	result := oar.DBConn.Unscoped().Delete(&attr)
+
+	return result.Error
+}
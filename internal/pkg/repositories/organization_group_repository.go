@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OrganizationGroupRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewOrganizationGroupRepository(dbConn *gorm.DB) interfaces.OrganizationGroupRepository {
+	return &OrganizationGroupRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (gr *OrganizationGroupRepository) CreateGroup(group entities.OrganizationGroup) (entities.OrganizationGroup, error) {
+	result := gr.DBConn.Create(&group)
+
+	return group, result.Error
+}
+
+func (gr *OrganizationGroupRepository) TakeByConditions(conditions map[string]interface{}) (entities.OrganizationGroup, error) {
+	group := entities.OrganizationGroup{}
+	result := gr.DBConn.Where(conditions).Take(&group)
+
+	return group, result.Error
+}
+
+func (gr *OrganizationGroupRepository) PaginateByConditions(conditions map[string]interface{}, offset int, limit int) ([]entities.OrganizationGroup, int64, error) {
+	groups := []entities.OrganizationGroup{}
+	var total int64
+
+	query := gr.DBConn.Model(&entities.OrganizationGroup{}).Where(conditions)
+	result := query.Count(&total)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	result = query.Order("id asc").Offset(offset).Limit(limit).Find(&groups)
+
+	return groups, total, result.Error
+}
+
+func (gr *OrganizationGroupRepository) UpdateGroup(group entities.OrganizationGroup, data map[string]interface{}) error {
+	result := gr.DBConn.Model(&group).Updates(data)
+
+	return result.Error
+}
+
+func (gr *OrganizationGroupRepository) DeleteGroup(group entities.OrganizationGroup) error {
+	return gr.DBConn.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("group_id = ?", group.ID).Delete(&entities.OrganizationGroupMember{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&group).Error
+	})
+}
+
+func (gr *OrganizationGroupRepository) FindMemberIDs(groupID uint) ([]uint, error) {
+	userIDs := []uint{}
+	result := gr.DBConn.Model(&entities.OrganizationGroupMember{}).
+		Where("group_id = ?", groupID).
+		Order("user_id asc").
+		Pluck("user_id", &userIDs)
+
+	return userIDs, result.Error
+}
+
+func (gr *OrganizationGroupRepository) AddMembers(groupID uint, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	members := make([]entities.OrganizationGroupMember, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, entities.OrganizationGroupMember{GroupID: groupID, UserID: userID})
+	}
+
+	result := gr.DBConn.Clauses(clause.OnConflict{DoNothing: true}).Create(&members)
+
+	return result.Error
+}
+
+func (gr *OrganizationGroupRepository) RemoveMembers(groupID uint, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	// hard delete, a soft deleted row would keep the unique index and make
+	// AddMembers skip the user
+	result := gr.DBConn.Unscoped().Where("group_id = ? AND user_id IN ?", groupID, userIDs).
+		Delete(&entities.OrganizationGroupMember{})
+
+	return result.Error
+}
+
+func (gr *OrganizationGroupRepository) ReplaceMembers(groupID uint, userIDs []uint) error {
+	return gr.DBConn.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("group_id = ?", groupID).Delete(&entities.OrganizationGroupMember{}).Error
+		if err != nil {
+			return err
+		}
+
+		txRepo := &OrganizationGroupRepository{DBConn: tx}
+		return txRepo.AddMembers(groupID, userIDs)
+	})
+}
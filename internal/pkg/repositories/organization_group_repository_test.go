@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/models/entities"
+	"engine/internal/pkg/migrations"
+	"engine/internal/pkg/repositories/repotest"
+)
+
+func newTestGroup(t *testing.T, dbConn *gorm.DB, userIDs ...uint) (*OrganizationGroupRepository, entities.OrganizationGroup) {
+	t.Helper()
+
+	gr := &OrganizationGroupRepository{DBConn: dbConn}
+	group, err := gr.CreateGroup(entities.OrganizationGroup{OrganizationID: 1, DisplayName: "Engineering"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gr.AddMembers(group.ID, userIDs); err != nil {
+		t.Fatal(err)
+	}
+
+	return gr, group
+}
+
+func assertGroupMembers(t *testing.T, gr *OrganizationGroupRepository, groupID uint, want []uint) {
+	t.Helper()
+
+	got, err := gr.FindMemberIDs(groupID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("members = %v, want %v", got, want)
+	}
+}
+
+func TestReplaceMembersSameList(t *testing.T) {
+	gr, group := newTestGroup(t, repotest.NewDB(t), 1, 2)
+
+	if err := gr.ReplaceMembers(group.ID, []uint{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	assertGroupMembers(t, gr, group.ID, []uint{1, 2})
+
+	if err := gr.ReplaceMembers(group.ID, []uint{2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	assertGroupMembers(t, gr, group.ID, []uint{2, 3})
+}
+
+func TestRemovedGroupMemberCanBeAddedBack(t *testing.T) {
+	gr, group := newTestGroup(t, repotest.NewDB(t), 1, 2)
+
+	if err := gr.RemoveMembers(group.ID, []uint{1}); err != nil {
+		t.Fatal(err)
+	}
+	assertGroupMembers(t, gr, group.ID, []uint{2})
+
+	if err := gr.AddMembers(group.ID, []uint{1}); err != nil {
+		t.Fatal(err)
+	}
+	assertGroupMembers(t, gr, group.ID, []uint{1, 2})
+}
+
+func TestRemovedOrganizationMemberCanRejoinGroups(t *testing.T) {
+	dbConn := repotest.NewDB(t)
+	gr, group := newTestGroup(t, dbConn, 1, 2)
+	orgr := &OrganizationRepository{DBConn: dbConn}
+
+	member := entities.OrganizationMember{OrganizationID: group.OrganizationID, UserID: 1, Role: "member"}
+	if err := dbConn.Create(&member).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := orgr.RemoveMember(member); err != nil {
+		t.Fatal(err)
+	}
+	assertGroupMembers(t, gr, group.ID, []uint{2})
+
+	if err := gr.AddMembers(group.ID, []uint{1}); err != nil {
+		t.Fatal(err)
+	}
+	assertGroupMembers(t, gr, group.ID, []uint{1, 2})
+}
+
+func TestDeleteGroupDropsMembers(t *testing.T) {
+	dbConn := repotest.NewDB(t)
+	gr, group := newTestGroup(t, dbConn, 1, 2)
+
+	if err := gr.DeleteGroup(group); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int64
+	dbConn.Unscoped().Model(&entities.OrganizationGroupMember{}).Where("group_id = ?", group.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("%d member rows left behind", count)
+	}
+}
+
+func TestMigratePurgesSoftDeletedGroupMembers(t *testing.T) {
+	dbConn := repotest.NewDB(t)
+	gr, group := newTestGroup(t, dbConn, 1, 2)
+
+	// rows removed before the fix were soft deleted
+	err := dbConn.Where("group_id = ? AND user_id = ?", group.ID, 1).Delete(&entities.OrganizationGroupMember{}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrations.Migrate(dbConn); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gr.AddMembers(group.ID, []uint{1}); err != nil {
+		t.Fatal(err)
+	}
+	assertGroupMembers(t, gr, group.ID, []uint{1, 2})
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type OrganizationRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewOrganizationRepository(dbConn *gorm.DB) interfaces.OrganizationRepository {
+	return &OrganizationRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (orgr *OrganizationRepository) CreateOrganization(org entities.Organization) (entities.Organization, error) {
+	result := orgr.DBConn.Create(&org)
+
+	return org, result.Error
+}
+
+func (orgr *OrganizationRepository) FindByConditions(conditions map[string]interface{}) ([]entities.Organization, error) {
+	orgs := []entities.Organization{}
+	result := orgr.DBConn.Where(conditions).Order("id asc").Find(&orgs)
+
+	return orgs, result.Error
+}
+
+func (orgr *OrganizationRepository) TakeByConditions(conditions map[string]interface{}) (entities.Organization, error) {
+	org := entities.Organization{}
+	result := orgr.DBConn.Where(conditions).Take(&org)
+
+	return org, result.Error
+}
+
+func (orgr *OrganizationRepository) TakeMember(conditions map[string]interface{}) (entities.OrganizationMember, error) {
+	member := entities.OrganizationMember{}
+	result := orgr.DBConn.Where(conditions).Take(&member)
+
+	return member, result.Error
+}
+
+func (orgr *OrganizationRepository) AddMember(member entities.OrganizationMember) error {
+	result := orgr.DBConn.Clauses(clause.OnConflict{DoNothing: true}).Create(&member)
+
+	return result.Error
+}
+
+func (orgr *OrganizationRepository) PaginateMembers(conditions map[string]interface{}, offset int, limit int) ([]entities.OrganizationMember, int64, error) {
+	members := []entities.OrganizationMember{}
+	var total int64
+
+	query := orgr.DBConn.Model(&entities.OrganizationMember{}).Where(conditions)
+	result := query.Count(&total)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	result = query.Order("id asc").Offset(offset).Limit(limit).Find(&members)
+
+	return members, total, result.Error
+}
+
+func (orgr *OrganizationRepository) UpdateMember(member entities.OrganizationMember, data map[string]interface{}) error {
+	result := orgr.DBConn.Model(&member).Updates(data)
+
+	return result.Error
+}
+
+func (orgr *OrganizationRepository) RemoveMember(member entities.OrganizationMember) error {
+	return orgr.DBConn.Transaction(func(tx *gorm.DB) error {
+		groupIDs := tx.Model(&entities.OrganizationGroup{}).
+			Select("id").
+			Where("organization_id = ?", member.OrganizationID)
+		err := tx.Unscoped().Where("user_id = ? AND group_id IN (?)", member.UserID, groupIDs).
+			Delete(&entities.OrganizationGroupMember{}).Error
+		if err != nil {
+			return err
+		}
+
+		// hard delete, a soft deleted row would keep the unique index and
+		// block adding the user again
+		return tx.Unscoped().Delete(&member).Error
+	})
+}
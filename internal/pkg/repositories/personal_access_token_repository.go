@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type PersonalAccessTokenRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(dbConn *gorm.DB) interfaces.PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (pr *PersonalAccessTokenRepository) CreateToken(token entities.PersonalAccessToken) (entities.PersonalAccessToken, error) {
+	result := pr.DBConn.Create(&token)
+
+	return token, result.Error
+}
+
+func (pr *PersonalAccessTokenRepository) FindActiveByUserID(userID uint) ([]entities.PersonalAccessToken, error) {
+	tokens := []entities.PersonalAccessToken{}
+
+	result := pr.DBConn.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("id desc").
+		Find(&tokens)
+	return tokens, result.Error
+}
+
+func (pr *PersonalAccessTokenRepository) TakeByConditions(conditions map[string]interface{}) (entities.PersonalAccessToken, error) {
+	token := entities.PersonalAccessToken{}
+	result := pr.DBConn.Where(conditions).Take(&token)
+
+	return token, result.Error
+}
+
+func (pr *PersonalAccessTokenRepository) UpdateToken(token entities.PersonalAccessToken, data map[string]interface{}) error {
+	result := pr.DBConn.Model(&token).Where("id = ?", token.ID).Updates(data)
+
+	return result.Error
+}
+
+func (pr *PersonalAccessTokenRepository) RevokeTokens(userID uint) error {
+	result := pr.DBConn.Model(&entities.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+
+	return result.Error
+}
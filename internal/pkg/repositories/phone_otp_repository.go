@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type PhoneOTPRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewPhoneOTPRepository(dbConn *gorm.DB) interfaces.PhoneOTPRepository {
+	return &PhoneOTPRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (por *PhoneOTPRepository) CreateOTP(otp entities.PhoneOTP) (entities.PhoneOTP, error) {
+	result := por.DBConn.Create(&otp)
+
+	return otp, result.Error
+}
+
+func (por *PhoneOTPRepository) TakeByConditions(conditions map[string]interface{}) (entities.PhoneOTP, error) {
+	otp := entities.PhoneOTP{}
+	result := por.DBConn.Where(conditions).Order("id desc").Take(&otp)
+
+	return otp, result.Error
+}
+
+func (por *PhoneOTPRepository) CountSince(conditions map[string]interface{}, since time.Time) (int64, error) {
+	var count int64
+	result := por.DBConn.Model(&entities.PhoneOTP{}).
+		Where(conditions).
+		Where("created_at > ?", since).
+		Count(&count)
+
+	return count, result.Error
+}
+
+func (por *PhoneOTPRepository) AddAttempt(otp entities.PhoneOTP, maxAttempts int) (bool, error) {
+	result := por.DBConn.Model(&entities.PhoneOTP{}).
+		Where("id = ? AND consumed_at IS NULL AND attempts < ?", otp.ID, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (por *PhoneOTPRepository) ConsumeOTP(otp entities.PhoneOTP) (bool, error) {
+	result := por.DBConn.Model(&entities.PhoneOTP{}).
+		Where("id = ? AND consumed_at IS NULL", otp.ID).
+		Update("consumed_at", time.Now())
+
+	return result.RowsAffected == 1, result.Error
+}
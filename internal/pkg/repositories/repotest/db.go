@@ -0,0 +1,41 @@
+// Package repotest opens a migrated in-memory database for the tests of the
+// repositories and of the usecases that depend on their SQL.
+package repotest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"engine/internal/pkg/migrations"
+)
+
+// NewDB opens a database of its own for the test, it is closed on cleanup
+func NewDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	dbConn, err := gorm.Open(sqlite.Open("file:"+name+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+
+	sqlDB, err := dbConn.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a single connection, transactions and plain queries see the same data
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := migrations.Migrate(dbConn); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return dbConn
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type SAMLAssertionRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewSAMLAssertionRepository(dbConn *gorm.DB) interfaces.SAMLAssertionRepository {
+	return &SAMLAssertionRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (sar *SAMLAssertionRepository) RecordAssertion(assertion entities.SAMLAssertion) (bool, error) {
+	result := sar.DBConn.Clauses(clause.OnConflict{DoNothing: true}).Create(&assertion)
+
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type SAMLConnectionRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewSAMLConnectionRepository(dbConn *gorm.DB) interfaces.SAMLConnectionRepository {
+	return &SAMLConnectionRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (scr *SAMLConnectionRepository) TakeByConditions(conditions map[string]interface{}) (entities.SAMLConnection, error) {
+	conn := entities.SAMLConnection{}
+	result := scr.DBConn.Where(conditions).Take(&conn)
+
+	return conn, result.Error
+}
+
+func (scr *SAMLConnectionRepository) SaveConnection(conn entities.SAMLConnection) (entities.SAMLConnection, error) {
+	result := scr.DBConn.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"idp_metadata_xml", "sso_binding", "email_attribute", "username_attribute",
+			"jit_provisioning", "default_role", "is_enabled", "updated_at",
+		}),
+	}).Create(&conn)
+	if result.Error != nil {
+		return conn, result.Error
+	}
+
+	return scr.TakeByConditions(map[string]interface{}{
+		"organization_id": conn.OrganizationID,
+	})
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type SAMLRequestRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewSAMLRequestRepository(dbConn *gorm.DB) interfaces.SAMLRequestRepository {
+	return &SAMLRequestRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (srr *SAMLRequestRepository) CreateRequest(req entities.SAMLRequest) (entities.SAMLRequest, error) {
+	result := srr.DBConn.Create(&req)
+
+	return req, result.Error
+}
+
+func (srr *SAMLRequestRepository) TakeByConditions(conditions map[string]interface{}) (entities.SAMLRequest, error) {
+	req := entities.SAMLRequest{}
+	result := srr.DBConn.Where(conditions).Take(&req)
+
+	return req, result.Error
+}
+
+func (srr *SAMLRequestRepository) ConsumeRequest(req entities.SAMLRequest) (bool, error) {
+	result := srr.DBConn.Model(&entities.SAMLRequest{}).
+		Where("id = ? AND used_at IS NULL", req.ID).
+		Update("used_at", time.Now())
+
+	return result.RowsAffected == 1, result.Error
+}
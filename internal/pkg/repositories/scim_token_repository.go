@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type SCIMTokenRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewSCIMTokenRepository(dbConn *gorm.DB) interfaces.SCIMTokenRepository {
+	return &SCIMTokenRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (str *SCIMTokenRepository) CreateToken(token entities.SCIMToken) (entities.SCIMToken, error) {
+	result := str.DBConn.Create(&token)
+
+	return token, result.Error
+}
+
+func (str *SCIMTokenRepository) FindActiveByOrganizationID(orgID uint) ([]entities.SCIMToken, error) {
+	tokens := []entities.SCIMToken{}
+	result := str.DBConn.Where("organization_id = ? AND revoked_at IS NULL", orgID).
+		Order("id desc").
+		Find(&tokens)
+
+	return tokens, result.Error
+}
+
+func (str *SCIMTokenRepository) TakeByConditions(conditions map[string]interface{}) (entities.SCIMToken, error) {
+	token := entities.SCIMToken{}
+	result := str.DBConn.Where(conditions).Take(&token)
+
+	return token, result.Error
+}
+
+func (str *SCIMTokenRepository) UpdateToken(token entities.SCIMToken, data map[string]interface{}) error {
+	result := str.DBConn.Model(&token).Updates(data)
+
+	return result.Error
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type SessionRepository struct {
+	DBConn *gorm.DB
+}
+
+func NewSessionRepository(dbConn *gorm.DB) interfaces.SessionRepository {
+	return &SessionRepository{
+		DBConn: dbConn,
+	}
+}
+
+func (sr *SessionRepository) CreateSession(session entities.Session) (entities.Session, error) {
+	result := sr.DBConn.Create(&session)
+
+	return session, result.Error
+}
+
+func (sr *SessionRepository) FindActiveByUserID(userID uint) ([]entities.Session, error) {
+	sessions := []entities.Session{}
+
+	result := sr.DBConn.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at desc").
+		Find(&sessions)
+	return sessions, result.Error
+}
+
+func (sr *SessionRepository) TakeByConditions(conditions map[string]interface{}) (entities.Session, error) {
+	session := entities.Session{}
+	result := sr.DBConn.Where(conditions).Take(&session)
+
+	return session, result.Error
+}
+
+func (sr *SessionRepository) UpdateSession(session entities.Session, data map[string]interface{}) error {
+	result := sr.DBConn.Model(&session).Where("id = ?", session.ID).Updates(data)
+
+	return result.Error
+}
+
+// RevokeSessions revokes every active session of the user except exceptSessionID (0 revokes all)
+func (sr *SessionRepository) RevokeSessions(userID uint, exceptSessionID uint) error {
+	result := sr.DBConn.Model(&entities.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptSessionID).
+		Update("revoked_at", time.Now())
+
+	return result.Error
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+)
+
+type Transactor struct {
+	DBConn *gorm.DB
+}
+
+func NewTransactor(dbConn *gorm.DB) interfaces.Transactor {
+	return &Transactor{
+		DBConn: dbConn,
+	}
+}
+
+func (t *Transactor) WithinTransaction(fn func(repos interfaces.TxRepositories) error) error {
+	return t.DBConn.Transaction(func(tx *gorm.DB) error {
+		return fn(interfaces.TxRepositories{
+			UserRepo:        NewUserRepository(tx),
+			EmailChangeRepo: NewEmailChangeRepository(tx),
+			EmailOutboxRepo: NewEmailOutboxRepository(tx),
+		})
+	})
+}
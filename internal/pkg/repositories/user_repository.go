@@ -1,12 +1,18 @@
 package repositories
 
 import (
+	"strings"
+
 	"gorm.io/gorm"
 
 	"engine/internal/pkg/domains/interfaces"
 	"engine/internal/pkg/domains/models/entities"
 )
 
+// likeEscaper escapes the LIKE wildcards in user input, '!' is used as the
+// escape character since it behaves the same on MySQL and Postgres
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
 type UserRepository struct {
 	DBConn *gorm.DB
 }
@@ -37,8 +43,40 @@ func (ur *UserRepository) TakeByConditions(conditions map[string]interface{}) (e
 	return user, result.Error
 }
 
+func (ur *UserRepository) FindByEmail(email string) ([]entities.User, error) {
+	users := []entities.User{}
+
+	result := ur.DBConn.Where("LOWER(email) = ?", strings.ToLower(email)).Find(&users)
+	return users, result.Error
+}
+
+func (ur *UserRepository) PaginateByConditions(conditions map[string]interface{}, keyword string, order string, offset int, limit int) ([]entities.User, int64, error) {
+	users := []entities.User{}
+	var total int64
+
+	query := ur.DBConn.Model(&entities.User{}).Where(conditions)
+	if keyword != "" {
+		like := "%" + likeEscaper.Replace(keyword) + "%"
+		query = query.Where("username LIKE ? ESCAPE '!' OR email LIKE ? ESCAPE '!'", like, like)
+	}
+
+	result := query.Count(&total)
+	if result.Error != nil {
+		return users, 0, result.Error
+	}
+
+	result = query.Order(order).Offset(offset).Limit(limit).Find(&users)
+	return users, total, result.Error
+}
+
 func (ur *UserRepository) UpdateUser(user entities.User, data map[string]interface{}) error {
 	result := ur.DBConn.Model(&user).Where("id = ?", user.ID).Updates(data)
 
 	return result.Error
 }
+
+func (ur *UserRepository) DeleteUser(user entities.User) error {
+	result := ur.DBConn.Delete(&user)
+
+	return result.Error
+}
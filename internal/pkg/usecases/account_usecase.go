@@ -0,0 +1,526 @@
+package usecases
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/mailer"
+	"engine/pkg/shared/sms"
+	"engine/pkg/shared/template/emails"
+	"engine/pkg/shared/utils"
+)
+
+type AccountUsecase struct {
+	UserRepo         interfaces.UserRepository
+	SessionRepo      interfaces.SessionRepository
+	TokenRepo        interfaces.PersonalAccessTokenRepository
+	RefreshTokenRepo interfaces.OAuthRefreshTokenRepository
+	EmailChangeRepo  interfaces.EmailChangeRepository
+	PhoneOTPRepo     interfaces.PhoneOTPRepository
+	AttributeRepo    interfaces.OrganizationAttributeRepository
+	Mailer           mailer.Mailer
+	SMSSender        sms.SMSSender
+	Transactor       interfaces.Transactor
+	AuditUsecase     interfaces.AuditUsecase
+}
+
+func NewAccountUsecase(
+	ur interfaces.UserRepository,
+	sr interfaces.SessionRepository,
+	tr interfaces.PersonalAccessTokenRepository,
+	rtr interfaces.OAuthRefreshTokenRepository,
+	ecr interfaces.EmailChangeRepository,
+	otpr interfaces.PhoneOTPRepository,
+	oar interfaces.OrganizationAttributeRepository,
+	m mailer.Mailer,
+	sender sms.SMSSender,
+	tx interfaces.Transactor,
+	auditUsecase interfaces.AuditUsecase,
+) interfaces.AccountUsecase {
+	return &AccountUsecase{
+		UserRepo:         ur,
+		SessionRepo:      sr,
+		TokenRepo:        tr,
+		RefreshTokenRepo: rtr,
+		EmailChangeRepo:  ecr,
+		PhoneOTPRepo:     otpr,
+		AttributeRepo:    oar,
+		Mailer:           m,
+		SMSSender:        sender,
+		Transactor:       tx,
+		AuditUsecase:     auditUsecase,
+	}
+}
+
+func (au *AccountUsecase) GetProfile(userID uint) (entities.User, error) {
+	return au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+}
+
+func (au *AccountUsecase) UpdateProfile(userID uint, req dtos.UpdateProfileRequest, client dtos.ClientInfo) (entities.User, error) {
+	user, err := au.updateProfile(userID, req)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionProfileUpdated, client, userID, err, nil)
+
+	return user, err
+}
+
+func (au *AccountUsecase) ChangePassword(userID uint, sessionID uint, req dtos.ChangePasswordRequest, client dtos.ClientInfo) error {
+	err := au.changePassword(userID, sessionID, req, client.Locale)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionPasswordChanged, client, userID, err, nil)
+
+	return err
+}
+
+func (au *AccountUsecase) RequestEmailChange(userID uint, sessionID uint, req dtos.ChangeEmailRequest, client dtos.ClientInfo) error {
+	err := au.requestEmailChange(userID, sessionID, req, client.Locale)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionEmailChangeRequested, client, userID, err, map[string]interface{}{
+		"new_email": req.NewEmail,
+	})
+
+	return err
+}
+
+func (au *AccountUsecase) ConfirmEmailChange(token string, client dtos.ClientInfo) error {
+	userID, err := au.confirmEmailChange(token)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionEmailChanged, client, userID, err, nil)
+
+	return err
+}
+
+func (au *AccountUsecase) UndoEmailChange(token string, client dtos.ClientInfo) error {
+	userID, err := au.undoEmailChange(token, client.Locale)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionEmailChangeUndone, client, userID, err, nil)
+
+	return err
+}
+
+func (au *AccountUsecase) ChangePhone(userID uint, sessionID uint, req dtos.ChangePhoneRequest, client dtos.ClientInfo) error {
+	err := au.changePhone(userID, sessionID, req)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionPhoneChangeRequested, client, userID, err, map[string]interface{}{
+		"phone": req.Phone,
+	})
+
+	return err
+}
+
+func (au *AccountUsecase) VerifyPhone(userID uint, req dtos.VerifyPhoneRequest, client dtos.ClientInfo) error {
+	err := au.verifyPhone(userID, req)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionPhoneVerified, client, userID, err, nil)
+
+	return err
+}
+
+func (au *AccountUsecase) SetSMSTwoFactor(userID uint, sessionID uint, req dtos.SetSMSTwoFactorRequest, client dtos.ClientInfo) error {
+	err := au.setSMSTwoFactor(userID, sessionID, req)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionSMSTwoFactorChanged, client, userID, err, map[string]interface{}{
+		"enabled": req.Enabled != nil && *req.Enabled,
+	})
+
+	return err
+}
+
+// checkStepUp accepts the current password, or a session that has just signed in
+func (au *AccountUsecase) checkStepUp(user entities.User, sessionID uint, currentPassword string) error {
+	if currentPassword != "" {
+		if !utils.CheckHashPassword(currentPassword, user.Password) {
+			return errors.New("password is incorrect")
+		}
+		return nil
+	}
+
+	session, err := au.SessionRepo.TakeByConditions(map[string]interface{}{
+		"id":      sessionID,
+		"user_id": user.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if time.Since(session.CreatedAt) > constants.StepUpWindow {
+		return errors.New("current password is required")
+	}
+
+	return nil
+}
+
+func (au *AccountUsecase) checkEmailAvailable(email string) error {
+	_, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"email": email,
+	})
+	if err == nil {
+		return errors.New("email already exists")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (au *AccountUsecase) changePassword(userID uint, sessionID uint, req dtos.ChangePasswordRequest, locale string) error {
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !utils.CheckHashPassword(req.CurrentPassword, user.Password) {
+		return errors.New("password is incorrect")
+	}
+
+	err = utils.ValidatePassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	newHashPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return errors.New("error while hash password")
+	}
+
+	err = au.UserRepo.UpdateUser(user, map[string]interface{}{
+		"password": newHashPassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	// everything but the device that made the change has to sign in again
+	err = au.SessionRepo.RevokeSessions(user.ID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	err = au.TokenRepo.RevokeTokens(user.ID)
+	if err != nil {
+		return err
+	}
+
+	// refresh tokens could mint new access tokens for the kept session too
+	err = au.RefreshTokenRepo.RevokeByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+
+	return utils.SendTemplateEmail(au.Mailer, utils.TemplateData{
+		Template: constants.EmailTemplatePasswordChanged,
+		Locale:   emails.ResolveLocale(user.Locale, locale),
+		To:       user.Email,
+		Username: user.Username,
+		Email:    user.Email,
+		Url:      os.Getenv("BASE_URL") + "auth/forgot-password",
+	})
+}
+
+func (au *AccountUsecase) requestEmailChange(userID uint, sessionID uint, req dtos.ChangeEmailRequest, locale string) error {
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = au.checkStepUp(user, sessionID, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+
+	if req.NewEmail == user.Email {
+		return errors.New("new email is the same as the current one")
+	}
+
+	err = au.checkEmailAvailable(req.NewEmail)
+	if err != nil {
+		return err
+	}
+
+	// the change and both emails are committed together
+	return au.Transactor.WithinTransaction(func(repos interfaces.TxRepositories) error {
+		change, err := repos.EmailChangeRepo.CreateEmailChange(entities.EmailChange{
+			UserID:    user.ID,
+			OldEmail:  user.Email,
+			NewEmail:  req.NewEmail,
+			ExpiresAt: time.Now().Add(constants.EmailChangeLinkTTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		return sendEmailChangeMails(NewOutboxMailer(repos.EmailOutboxRepo), user, change, locale)
+	})
+}
+
+// sendEmailChangeMails asks the new address to confirm and tells the old one how to undo
+func sendEmailChangeMails(m mailer.Mailer, user entities.User, change entities.EmailChange, locale string) error {
+	locale = emails.ResolveLocale(user.Locale, locale)
+
+	confirmToken, err := auth.GenerateHS256JWT(map[string]interface{}{
+		"typ": constants.TokenPurposeEmailChange,
+		"sub": user.ID,
+		"cid": change.ID,
+		"exp": change.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	undoToken, err := auth.GenerateHS256JWT(map[string]interface{}{
+		"typ": constants.TokenPurposeEmailChangeUndo,
+		"sub": user.ID,
+		"cid": change.ID,
+		"exp": time.Now().Add(constants.EmailChangeUndoTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = utils.SendTemplateEmail(m, utils.TemplateData{
+		Template:  constants.EmailTemplateChangeEmail,
+		Locale:    locale,
+		To:        change.NewEmail,
+		Username:  user.Username,
+		Email:     change.NewEmail,
+		Url:       os.Getenv("BASE_URL") + "auth/confirm-email-change/" + confirmToken,
+		ExpiresIn: constants.EmailChangeLinkTTL,
+	})
+	if err != nil {
+		return err
+	}
+
+	return utils.SendTemplateEmail(m, utils.TemplateData{
+		Template:  constants.EmailTemplateEmailChangeNotice,
+		Locale:    locale,
+		To:        change.OldEmail,
+		Username:  user.Username,
+		Email:     change.OldEmail,
+		Url:       os.Getenv("BASE_URL") + "auth/undo-email-change/" + undoToken,
+		ExpiresIn: constants.EmailChangeUndoTTL,
+		Extra: map[string]string{
+			"NewEmail": change.NewEmail,
+		},
+	})
+}
+
+func (au *AccountUsecase) takeEmailChange(token string, purpose string) (entities.EmailChange, error) {
+	claims, err := parsePurposeToken(token, purpose, "sub", "cid")
+	if err != nil {
+		return entities.EmailChange{}, err
+	}
+
+	return au.EmailChangeRepo.TakeByConditions(map[string]interface{}{
+		"id":      claims["cid"],
+		"user_id": claims["sub"],
+	})
+}
+
+func (au *AccountUsecase) confirmEmailChange(token string) (uint, error) {
+	change, err := au.takeEmailChange(token, constants.TokenPurposeEmailChange)
+	if err != nil {
+		return 0, err
+	}
+
+	if change.ConfirmedAt != nil || change.CancelledAt != nil || time.Now().After(change.ExpiresAt) {
+		return change.UserID, errors.New("email change is no longer pending")
+	}
+
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": change.UserID,
+	})
+	if err != nil {
+		return change.UserID, err
+	}
+
+	if user.Email != change.OldEmail {
+		return user.ID, errors.New("email was changed in the meantime")
+	}
+
+	err = au.checkEmailAvailable(change.NewEmail)
+	if err != nil {
+		return user.ID, err
+	}
+
+	err = au.UserRepo.UpdateUser(user, map[string]interface{}{
+		"email":         change.NewEmail,
+		"email_bounced": false,
+	})
+	if err != nil {
+		return user.ID, err
+	}
+
+	return user.ID, au.EmailChangeRepo.UpdateEmailChange(change, map[string]interface{}{
+		"confirmed_at": time.Now(),
+	})
+}
+
+// undoEmailChange cancels a pending change, or reverts a confirmed one and
+// locks the account down since the owner did not ask for it
+func (au *AccountUsecase) undoEmailChange(token string, locale string) (uint, error) {
+	change, err := au.takeEmailChange(token, constants.TokenPurposeEmailChangeUndo)
+	if err != nil {
+		return 0, err
+	}
+
+	if change.CancelledAt != nil {
+		return change.UserID, errors.New("email change already undone")
+	}
+
+	if change.ConfirmedAt != nil {
+		user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+			"id": change.UserID,
+		})
+		if err != nil {
+			return change.UserID, err
+		}
+
+		if user.Email != change.NewEmail {
+			return user.ID, errors.New("email was changed in the meantime")
+		}
+
+		err = au.checkEmailAvailable(change.OldEmail)
+		if err != nil {
+			return user.ID, err
+		}
+
+		err = au.UserRepo.UpdateUser(user, map[string]interface{}{
+			"email":               change.OldEmail,
+			"must_reset_password": true,
+		})
+		if err != nil {
+			return user.ID, err
+		}
+
+		err = au.SessionRepo.RevokeSessions(user.ID, 0)
+		if err != nil {
+			return user.ID, err
+		}
+
+		err = au.TokenRepo.RevokeTokens(user.ID)
+		if err != nil {
+			return user.ID, err
+		}
+
+		user.Email = change.OldEmail
+		err = sendMailResetPassword(au.Mailer, user, locale)
+		if err != nil {
+			return user.ID, err
+		}
+	}
+
+	return change.UserID, au.EmailChangeRepo.UpdateEmailChange(change, map[string]interface{}{
+		"cancelled_at": time.Now(),
+	})
+}
+
+// changePhone sends a code to the new number, the user keeps the current one
+// until the code is confirmed with verifyPhone
+func (au *AccountUsecase) changePhone(userID uint, sessionID uint, req dtos.ChangePhoneRequest) error {
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = au.checkStepUp(user, sessionID, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+
+	if user.PhoneVerified && req.Phone == user.Phone {
+		return errors.New("phone is already verified")
+	}
+
+	_, err = sendPhoneOTP(au.PhoneOTPRepo, au.SMSSender, user.ID, req.Phone, constants.PhoneOTPPurposeVerify)
+	return err
+}
+
+func (au *AccountUsecase) verifyPhone(userID uint, req dtos.VerifyPhoneRequest) error {
+	otp, err := au.PhoneOTPRepo.TakeByConditions(map[string]interface{}{
+		"user_id": userID,
+		"purpose": constants.PhoneOTPPurposeVerify,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPhoneOTPInvalid
+	}
+	if err != nil {
+		return err
+	}
+
+	err = checkPhoneOTP(au.PhoneOTPRepo, otp, req.Code)
+	if err != nil {
+		return err
+	}
+
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// a new number has to be proven again before it is used for sign in
+	return au.UserRepo.UpdateUser(user, map[string]interface{}{
+		"phone":          otp.Phone,
+		"phone_verified": true,
+		"sms_two_factor": user.SMSTwoFactor && user.Phone == otp.Phone,
+	})
+}
+
+func (au *AccountUsecase) setSMSTwoFactor(userID uint, sessionID uint, req dtos.SetSMSTwoFactorRequest) error {
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = au.checkStepUp(user, sessionID, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+
+	// without SMS the user could not sign in anymore
+	if *req.Enabled && !sms.Enabled(au.SMSSender) {
+		return sms.ErrDisabled
+	}
+	if *req.Enabled && (!user.PhoneVerified || user.Phone == "") {
+		return errors.New("a verified phone is required")
+	}
+
+	return au.UserRepo.UpdateUser(user, map[string]interface{}{
+		"sms_two_factor": *req.Enabled,
+	})
+}
+
+func (au *AccountUsecase) updateProfile(userID uint, req dtos.UpdateProfileRequest) (entities.User, error) {
+	user, err := au.GetProfile(userID)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	data, err := profileChanges(au.AttributeRepo, user, req)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	if len(data) == 0 {
+		return user, nil
+	}
+
+	err = au.UserRepo.UpdateUser(user, data)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return au.GetProfile(userID)
+}
@@ -0,0 +1,236 @@
+package usecases
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+)
+
+type AuditUsecase struct {
+	AuditRepo interfaces.AuditRepository
+}
+
+func NewAuditUsecase(ar interfaces.AuditRepository) interfaces.AuditUsecase {
+	return &AuditUsecase{
+		AuditRepo: ar,
+	}
+}
+
+// Record stores one audit event, err is the result of the audited operation
+func (au *AuditUsecase) Record(action string, client dtos.ClientInfo, targetID *uint, err error, metadata map[string]interface{}) error {
+	outcome := constants.AuditOutcomeSuccess
+	if err != nil {
+		outcome = constants.AuditOutcomeFailure
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		metadata["error"] = err.Error()
+	}
+
+	encodedMetadata := ""
+	if len(metadata) > 0 {
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		encodedMetadata = string(data)
+	}
+
+	_, err = au.AuditRepo.AppendAuditEvent(entities.AuditEvent{
+		// both databases keep at least milliseconds, so the hash survives a round trip
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+		ActorID:   client.ActorID,
+		TargetID:  targetID,
+		Action:    action,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Outcome:   outcome,
+		Metadata:  encodedMetadata,
+	}, computeAuditEventHash)
+
+	return err
+}
+
+func (au *AuditUsecase) ListAuditEvents(filter dtos.AuditEventFilter) ([]entities.AuditEvent, dtos.PaginationResponse, error) {
+	page := filter.Page
+	if page == 0 {
+		page = 1
+	}
+
+	pageSize := filter.PageSize
+	if pageSize == 0 {
+		pageSize = constants.DefaultPageSize
+	}
+	if pageSize > constants.MaxPageSize {
+		pageSize = constants.MaxPageSize
+	}
+
+	events, total, err := au.AuditRepo.PaginateByFilter(filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, dtos.PaginationResponse{}, err
+	}
+
+	return events, dtos.PaginationResponse{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+func (au *AuditUsecase) ExportAuditEvents(filter dtos.AuditEventFilter, fn func(event entities.AuditEvent) error) error {
+	return au.AuditRepo.IterateByFilter(filter, fn)
+}
+
+// VerifyChain walks every event in id order and stops at the first broken link.
+// Every checkpointEvery events (and at the end) a signed checkpoint is passed to onCheckpoint.
+func (au *AuditUsecase) VerifyChain(checkpointEvery int, onCheckpoint func(checkpoint dtos.AuditCheckpoint) error) (dtos.AuditChainReport, error) {
+	report := dtos.AuditChainReport{Valid: true}
+	errBrokenChain := errors.New("broken chain")
+	prevHash := ""
+	chained := false
+
+	err := au.AuditRepo.IterateByFilter(dtos.AuditEventFilter{}, func(event entities.AuditEvent) error {
+		// events written before the chain existed have no hash
+		if !chained && event.Hash == "" {
+			report.LegacySkipped++
+			return nil
+		}
+		chained = true
+		report.Checked++
+
+		if event.PrevHash != prevHash {
+			report.Valid = false
+			report.BrokenEventID = event.ID
+			report.Reason = "prev_hash does not match the previous event"
+			return errBrokenChain
+		}
+
+		if computeAuditEventHash(event) != event.Hash {
+			report.Valid = false
+			report.BrokenEventID = event.ID
+			report.Reason = "hash does not match the event content"
+			return errBrokenChain
+		}
+
+		prevHash = event.Hash
+		report.LastEventID = event.ID
+		report.LastHash = event.Hash
+
+		if onCheckpoint != nil && checkpointEvery > 0 && report.Checked%checkpointEvery == 0 {
+			return au.emitCheckpoint(event, onCheckpoint)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBrokenChain) {
+		return report, err
+	}
+	if !report.Valid {
+		return report, nil
+	}
+
+	// rows removed from the end of the table only show up against the head
+	head, err := au.AuditRepo.TakeChainHead()
+	if err == nil && head.LastHash != report.LastHash {
+		report.Valid = false
+		report.BrokenEventID = head.LastEventID
+		report.Reason = "chain head does not match the last event"
+		return report, nil
+	}
+
+	if onCheckpoint != nil && report.LastEventID != 0 && (checkpointEvery <= 0 || report.Checked%checkpointEvery != 0) {
+		err = au.emitCheckpoint(entities.AuditEvent{ID: report.LastEventID, Hash: report.LastHash}, onCheckpoint)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// VerifyCheckpoint checks the signature and that the pinned event still has the same hash
+func (au *AuditUsecase) VerifyCheckpoint(checkpoint dtos.AuditCheckpoint) error {
+	signature, err := signAuditCheckpoint(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(checkpoint.Signature)) {
+		return fmt.Errorf("checkpoint for event %d has an invalid signature", checkpoint.EventID)
+	}
+
+	event, err := au.AuditRepo.TakeAuditEvent(checkpoint.EventID)
+	if err != nil {
+		return fmt.Errorf("checkpoint event %d: %w", checkpoint.EventID, err)
+	}
+
+	if event.Hash != checkpoint.Hash {
+		return fmt.Errorf("checkpoint event %d hash does not match", checkpoint.EventID)
+	}
+
+	return nil
+}
+
+func (au *AuditUsecase) emitCheckpoint(event entities.AuditEvent, onCheckpoint func(checkpoint dtos.AuditCheckpoint) error) error {
+	checkpoint := dtos.AuditCheckpoint{
+		EventID:  event.ID,
+		Hash:     event.Hash,
+		SignedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	signature, err := signAuditCheckpoint(checkpoint)
+	if err != nil {
+		return err
+	}
+	checkpoint.Signature = signature
+
+	return onCheckpoint(checkpoint)
+}
+
+// computeAuditEventHash hashes the previous hash together with the event content
+func computeAuditEventHash(event entities.AuditEvent) string {
+	payload, _ := json.Marshal(struct {
+		PrevHash  string `json:"prev_hash"`
+		CreatedAt string `json:"created_at"`
+		ActorID   *uint  `json:"actor_id"`
+		TargetID  *uint  `json:"target_id"`
+		Action    string `json:"action"`
+		IPAddress string `json:"ip_address"`
+		UserAgent string `json:"user_agent"`
+		Outcome   string `json:"outcome"`
+		Metadata  string `json:"metadata"`
+	}{
+		PrevHash:  event.PrevHash,
+		CreatedAt: event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ActorID:   event.ActorID,
+		TargetID:  event.TargetID,
+		Action:    event.Action,
+		IPAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+		Outcome:   event.Outcome,
+		Metadata:  event.Metadata,
+	})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+func signAuditCheckpoint(checkpoint dtos.AuditCheckpoint) (string, error) {
+	key := os.Getenv("AUDIT_CHECKPOINT_KEY")
+	if key == "" {
+		return "", errors.New("AUDIT_CHECKPOINT_KEY is not set")
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(fmt.Sprintf("%d:%s:%s", checkpoint.EventID, checkpoint.Hash, checkpoint.SignedAt.UTC().Format(time.RFC3339))))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
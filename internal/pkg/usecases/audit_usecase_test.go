@@ -0,0 +1,244 @@
+package usecases
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/repositories/repotest"
+	"engine/pkg/shared/constants"
+)
+
+// newTestAuditChain records count events on a real audit repository
+func newTestAuditChain(t *testing.T, count int) (*AuditUsecase, *gorm.DB) {
+	t.Helper()
+	t.Setenv("AUDIT_CHECKPOINT_KEY", "checkpoint-secret")
+
+	dbConn := repotest.NewDB(t)
+	au := &AuditUsecase{AuditRepo: repositories.NewAuditRepository(dbConn)}
+	for i := 0; i < count; i++ {
+		err := au.Record(constants.AuditActionSignIn, dtos.ClientInfo{IPAddress: "203.0.113.7"}, nil, nil, map[string]interface{}{"n": i})
+		if err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	return au, dbConn
+}
+
+func verifyTestChain(t *testing.T, au *AuditUsecase, checkpointEvery int) (dtos.AuditChainReport, []dtos.AuditCheckpoint) {
+	t.Helper()
+
+	checkpoints := []dtos.AuditCheckpoint{}
+	report, err := au.VerifyChain(checkpointEvery, func(checkpoint dtos.AuditCheckpoint) error {
+		checkpoints = append(checkpoints, checkpoint)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("VerifyChain() error = %v", err)
+	}
+
+	return report, checkpoints
+}
+
+func TestVerifyChainIntact(t *testing.T) {
+	au, _ := newTestAuditChain(t, 5)
+
+	report, checkpoints := verifyTestChain(t, au, 2)
+	if !report.Valid || report.Checked != 5 || report.LastEventID != 5 {
+		t.Fatalf("VerifyChain() = %+v", report)
+	}
+
+	// every second event and the last one
+	if len(checkpoints) != 3 || checkpoints[0].EventID != 2 || checkpoints[1].EventID != 4 || checkpoints[2].EventID != 5 {
+		t.Fatalf("checkpoints = %+v", checkpoints)
+	}
+	for _, checkpoint := range checkpoints {
+		if err := au.VerifyCheckpoint(checkpoint); err != nil {
+			t.Errorf("VerifyCheckpoint(%d) error = %v", checkpoint.EventID, err)
+		}
+	}
+}
+
+func TestVerifyChainConcurrentAppends(t *testing.T) {
+	au, _ := newTestAuditChain(t, 0)
+
+	// the locked chain head keeps every event linked to the one before
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := au.Record(constants.AuditActionSignIn, dtos.ClientInfo{}, nil, nil, nil); err != nil {
+				t.Errorf("Record() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	report, _ := verifyTestChain(t, au, 0)
+	if !report.Valid || report.Checked != 20 {
+		t.Fatalf("VerifyChain() = %+v", report)
+	}
+}
+
+func TestVerifyChainTampering(t *testing.T) {
+	tests := []struct {
+		name       string
+		tamper     func(dbConn *gorm.DB) error
+		wantBroken uint
+	}{
+		{
+			name: "modified row",
+			tamper: func(dbConn *gorm.DB) error {
+				return dbConn.Model(&entities.AuditEvent{ID: 3}).Update("action", constants.AuditActionNotMe).Error
+			},
+			wantBroken: 3,
+		},
+		{
+			name: "modified row with a recomputed hash",
+			tamper: func(dbConn *gorm.DB) error {
+				event := entities.AuditEvent{}
+				if err := dbConn.Take(&event, 3).Error; err != nil {
+					return err
+				}
+				event.Outcome = constants.AuditOutcomeFailure
+				return dbConn.Model(&event).Updates(map[string]interface{}{
+					"outcome": event.Outcome,
+					"hash":    computeAuditEventHash(event),
+				}).Error
+			},
+			wantBroken: 4,
+		},
+		{
+			name: "deleted row",
+			tamper: func(dbConn *gorm.DB) error {
+				return dbConn.Delete(&entities.AuditEvent{}, 2).Error
+			},
+			wantBroken: 3,
+		},
+		{
+			name: "truncated tail",
+			tamper: func(dbConn *gorm.DB) error {
+				return dbConn.Where("id > ?", 3).Delete(&entities.AuditEvent{}).Error
+			},
+			wantBroken: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			au, dbConn := newTestAuditChain(t, 5)
+			if err := tt.tamper(dbConn); err != nil {
+				t.Fatal(err)
+			}
+
+			report, _ := verifyTestChain(t, au, 0)
+			if report.Valid || report.BrokenEventID != tt.wantBroken {
+				t.Errorf("VerifyChain() = %+v, want broken at %d", report, tt.wantBroken)
+			}
+		})
+	}
+}
+
+func TestVerifyCheckpointTruncatedTail(t *testing.T) {
+	au, dbConn := newTestAuditChain(t, 5)
+	_, checkpoints := verifyTestChain(t, au, 0)
+
+	// the head could be rewritten together with the rows, the checkpoint
+	// kept outside of the database cannot
+	err := dbConn.Where("id > ?", 3).Delete(&entities.AuditEvent{}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dbConn.Model(&entities.AuditChainHead{ID: 1}).Updates(map[string]interface{}{"last_event_id": 3}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := au.VerifyCheckpoint(checkpoints[len(checkpoints)-1]); err == nil {
+		t.Errorf("VerifyCheckpoint() accepted a checkpoint of a removed event")
+	}
+}
+
+func TestVerifyCheckpointForged(t *testing.T) {
+	au, dbConn := newTestAuditChain(t, 3)
+	_, checkpoints := verifyTestChain(t, au, 0)
+	checkpoint := checkpoints[0]
+
+	tests := []struct {
+		name   string
+		forge  func(checkpoint dtos.AuditCheckpoint) dtos.AuditCheckpoint
+		keyEnv string
+	}{
+		{name: "other event", forge: func(c dtos.AuditCheckpoint) dtos.AuditCheckpoint { c.EventID = 2; return c }},
+		{name: "other hash", forge: func(c dtos.AuditCheckpoint) dtos.AuditCheckpoint { c.Hash = fmt.Sprintf("%064d", 0); return c }},
+		{name: "other time", forge: func(c dtos.AuditCheckpoint) dtos.AuditCheckpoint { c.SignedAt = c.SignedAt.Add(time.Hour); return c }},
+		{name: "no signature", forge: func(c dtos.AuditCheckpoint) dtos.AuditCheckpoint { c.Signature = ""; return c }},
+		{name: "wrong key", forge: func(c dtos.AuditCheckpoint) dtos.AuditCheckpoint { return c }, keyEnv: "another-secret"},
+		{name: "no key", forge: func(c dtos.AuditCheckpoint) dtos.AuditCheckpoint { return c }, keyEnv: "-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			switch tt.keyEnv {
+			case "":
+			case "-":
+				t.Setenv("AUDIT_CHECKPOINT_KEY", "")
+			default:
+				t.Setenv("AUDIT_CHECKPOINT_KEY", tt.keyEnv)
+			}
+
+			if err := au.VerifyCheckpoint(tt.forge(checkpoint)); err == nil {
+				t.Errorf("VerifyCheckpoint() accepted a forged checkpoint")
+			}
+		})
+	}
+
+	// a genuine checkpoint still fails once its event was rewritten
+	err := dbConn.Model(&entities.AuditEvent{ID: checkpoint.EventID}).Update("hash", fmt.Sprintf("%064d", 1)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := au.VerifyCheckpoint(checkpoint); err == nil {
+		t.Errorf("VerifyCheckpoint() accepted a checkpoint of a rewritten event")
+	}
+}
+
+func TestVerifyChainSkipsLegacyEvents(t *testing.T) {
+	au, dbConn := newTestAuditChain(t, 0)
+
+	// rows written before the chain existed have no hash
+	for i := 0; i < 2; i++ {
+		legacy := entities.AuditEvent{CreatedAt: time.Now(), Action: constants.AuditActionSignIn, Outcome: constants.AuditOutcomeSuccess}
+		if err := dbConn.Create(&legacy).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := au.Record(constants.AuditActionSignIn, dtos.ClientInfo{}, nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, _ := verifyTestChain(t, au, 0)
+	if !report.Valid || report.LegacySkipped != 2 || report.Checked != 3 {
+		t.Fatalf("VerifyChain() = %+v", report)
+	}
+
+	// only a prefix is legacy, a hashless row inside the chain is tampering
+	err := dbConn.Model(&entities.AuditEvent{ID: 4}).Update("hash", "").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, _ = verifyTestChain(t, au, 0)
+	if report.Valid || report.BrokenEventID != 4 {
+		t.Errorf("VerifyChain() = %+v, want broken at 4", report)
+	}
+}
@@ -10,16 +10,46 @@ import (
 	"engine/internal/pkg/domains/models/dtos"
 	"engine/internal/pkg/domains/models/entities"
 	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/mailer"
+	"engine/pkg/shared/sms"
+	"engine/pkg/shared/template/emails"
 	"engine/pkg/shared/utils"
 )
 
 type AuthUsecase struct {
-	UserRepo interfaces.UserRepository
+	UserRepo        interfaces.UserRepository
+	SessionRepo     interfaces.SessionRepository
+	KnownDeviceRepo interfaces.KnownDeviceRepository
+	PhoneOTPRepo    interfaces.PhoneOTPRepository
+	Authenticator   interfaces.Authenticator
+	Mailer          mailer.Mailer
+	SMSSender       sms.SMSSender
+	Transactor      interfaces.Transactor
+	AuditUsecase    interfaces.AuditUsecase
 }
 
-func NewAuthUsecase(ur interfaces.UserRepository) interfaces.AuthUsecase {
+func NewAuthUsecase(
+	ur interfaces.UserRepository,
+	sr interfaces.SessionRepository,
+	kdr interfaces.KnownDeviceRepository,
+	otpr interfaces.PhoneOTPRepository,
+	authenticator interfaces.Authenticator,
+	m mailer.Mailer,
+	sender sms.SMSSender,
+	tx interfaces.Transactor,
+	auditUsecase interfaces.AuditUsecase,
+) interfaces.AuthUsecase {
 	return &AuthUsecase{
-		UserRepo: ur,
+		UserRepo:        ur,
+		SessionRepo:     sr,
+		KnownDeviceRepo: kdr,
+		PhoneOTPRepo:    otpr,
+		Authenticator:   authenticator,
+		Mailer:          m,
+		SMSSender:       sender,
+		Transactor:      tx,
+		AuditUsecase:    auditUsecase,
 	}
 }
 
@@ -35,12 +65,157 @@ func (au *AuthUsecase) TakeByConditions(conditions map[string]interface{}) (enti
 	return user, err
 }
 
-func (au *AuthUsecase) SignUp(req dtos.CreateUserRequest) (entities.User, error) {
+func (au *AuthUsecase) SignUp(req dtos.CreateUserRequest, client dtos.ClientInfo) (entities.User, error) {
+	user, err := au.signUp(req, client.Locale)
+	au.recordAuditEvent(constants.AuditActionSignUp, client, user.ID, err, map[string]interface{}{
+		"email": req.Email,
+	})
+
+	return user, err
+}
+
+func (au *AuthUsecase) SignIn(req dtos.SignInRequest, client dtos.ClientInfo) (entities.User, string, error) {
+	user, jwtToken, err := au.signIn(req, client)
+	if user.ID != 0 {
+		client.ActorID = &user.ID
+	}
+	au.recordAuditEvent(constants.AuditActionSignIn, client, user.ID, err, map[string]interface{}{
+		"email": req.Email,
+	})
+
+	return user, jwtToken, err
+}
+
+// SignInExternal signs in a user whose identity an external provider, Google,
+// confirmed. The account checks and the SMS second factor of SignIn apply.
+func (au *AuthUsecase) SignInExternal(user entities.User, provider string, client dtos.ClientInfo) (entities.User, string, error) {
+	user, jwtToken, err := au.signInExternal(user, client)
+	if user.ID != 0 {
+		client.ActorID = &user.ID
+	}
+	au.recordAuditEvent(constants.AuditActionSignIn, client, user.ID, err, map[string]interface{}{
+		"email":    user.Email,
+		"provider": provider,
+	})
+
+	return user, jwtToken, err
+}
+
+// SignInSMS completes a sign in with the code of the SMS challenge
+func (au *AuthUsecase) SignInSMS(req dtos.SMSSignInRequest, client dtos.ClientInfo) (entities.User, string, error) {
+	user, jwtToken, err := au.signInSMS(req, client)
+	if user.ID != 0 {
+		client.ActorID = &user.ID
+	}
+	au.recordAuditEvent(constants.AuditActionSMSSignIn, client, user.ID, err, nil)
+
+	return user, jwtToken, err
+}
+
+// Authenticate checks the credentials like SignIn but does not start a session,
+// it is used by flows that issue their own tokens afterwards. It answers with
+// the SMS challenge too, AuthenticateSMS completes it.
+func (au *AuthUsecase) Authenticate(req dtos.SignInRequest, client dtos.ClientInfo) (entities.User, error) {
+	user, err := au.authenticate(req)
+	if user.ID != 0 {
+		client.ActorID = &user.ID
+	}
+	au.recordAuditEvent(constants.AuditActionAuthenticate, client, user.ID, err, map[string]interface{}{
+		"email": req.Email,
+	})
+
+	return user, err
+}
+
+// AuthenticateSMS is SignInSMS without the session, for the flows of Authenticate
+func (au *AuthUsecase) AuthenticateSMS(req dtos.SMSSignInRequest, client dtos.ClientInfo) (entities.User, error) {
+	user, err := au.checkSMSCode(req)
+	if user.ID != 0 {
+		client.ActorID = &user.ID
+	}
+	au.recordAuditEvent(constants.AuditActionAuthenticate, client, user.ID, err, nil)
+
+	return user, err
+}
+
+func (au *AuthUsecase) SendMailForgotPassword(req dtos.ForgotPasswordRequest, client dtos.ClientInfo) error {
+	user, err := au.sendMailForgotPassword(req, client.Locale)
+	au.recordAuditEvent(constants.AuditActionForgotPassword, client, user.ID, err, map[string]interface{}{
+		"email": req.Email,
+	})
+
+	return err
+}
+
+func (au *AuthUsecase) ActiveUser(userID uint, client dtos.ClientInfo) error {
+	err := au.activeUser(userID)
+	au.recordAuditEvent(constants.AuditActionActivateUser, client, userID, err, nil)
+
+	return err
+}
+
+func (au *AuthUsecase) ResetPassword(userID uint, req dtos.ResetPasswordRequest, client dtos.ClientInfo) error {
+	err := au.resetPassword(userID, req)
+	au.recordAuditEvent(constants.AuditActionResetPassword, client, userID, err, nil)
+
+	return err
+}
+
+// NotMe handles the "this wasn't me" link of a new device alert
+func (au *AuthUsecase) NotMe(token string, client dtos.ClientInfo) error {
+	userID, err := au.notMe(token, client.Locale)
+	au.recordAuditEvent(constants.AuditActionNotMe, client, userID, err, nil)
+
+	return err
+}
+
+func (au *AuthUsecase) recordAuditEvent(action string, client dtos.ClientInfo, targetID uint, err error, metadata map[string]interface{}) {
+	recordAuditEvent(au.AuditUsecase, action, client, targetID, err, metadata)
+}
+
+// recordAuditEvent must not break the audited flow, so its error is dropped
+func recordAuditEvent(auditUsecase interfaces.AuditUsecase, action string, client dtos.ClientInfo, targetID uint, err error, metadata map[string]interface{}) {
+	var target *uint
+	if targetID != 0 {
+		target = &targetID
+	}
+
+	_ = auditUsecase.Record(action, client, target, err, metadata)
+}
+
+// parsePurposeToken verifies a one-off link token and returns its numeric claims
+func parsePurposeToken(token string, purpose string, keys ...string) (map[string]uint, error) {
+	claims, err := auth.ParseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims["typ"] != purpose {
+		return nil, errors.New("invalid token")
+	}
+
+	values := map[string]uint{}
+	for _, key := range keys {
+		value, ok := claims[key].(float64)
+		if !ok {
+			return nil, errors.New("invalid token")
+		}
+		values[key] = uint(value)
+	}
+
+	return values, nil
+}
+
+func (au *AuthUsecase) signUp(req dtos.CreateUserRequest, locale string) (entities.User, error) {
 	user := entities.User{
 		Username: req.Username,
 		Email:    req.Email,
 		Password: req.Password,
 		IsActive: req.IsActive,
+		Locale:   req.Locale,
+	}
+	if user.Locale == "" {
+		user.Locale = locale
 	}
 
 	hashPassword, err := utils.HashPassword(user.Password)
@@ -49,96 +224,358 @@ func (au *AuthUsecase) SignUp(req dtos.CreateUserRequest) (entities.User, error)
 	}
 
 	user.Password = hashPassword
-	user, err = au.UserRepo.CreateUser(user)
+
+	// the user and its verification email are committed together, a template
+	// error rolls the user back
+	err = au.Transactor.WithinTransaction(func(repos interfaces.TxRepositories) error {
+		user, err = repos.UserRepo.CreateUser(user)
+		if err != nil {
+			return err
+		}
+
+		msg, err := renderVerifyEmail(user)
+		if err != nil {
+			return err
+		}
+
+		return NewOutboxMailer(repos.EmailOutboxRepo).Send(msg)
+	})
 	if err != nil {
 		return entities.User{}, err
 	}
 
-	encodedEmail := base64.StdEncoding.EncodeToString([]byte(user.Email))
+	return user, nil
+}
 
-	token, err := auth.GenerateHS256JWT(map[string]interface{}{
-		"email": user.Email,
-		"exp":   time.Now().Add(time.Minute * 15).Unix(),
+func (au *AuthUsecase) signIn(req dtos.SignInRequest, client dtos.ClientInfo) (entities.User, string, error) {
+	user, err := au.authenticate(req)
+	if err != nil {
+		return user, "", err
+	}
+
+	session, jwtToken, err := au.IssueAccessToken(user, client, nil)
+	if err != nil {
+		return entities.User{}, "", errors.New("error while generating token")
+	}
+
+	au.checkNewDevice(user, session, client)
+
+	return user, jwtToken, nil
+}
+
+// sendSMSChallenge texts a sign in code to the user. The returned AuthError
+// carries a challenge token that has to be sent back with the code.
+func (au *AuthUsecase) sendSMSChallenge(user entities.User) error {
+	otp, err := sendPhoneOTP(au.PhoneOTPRepo, au.SMSSender, user.ID, user.Phone, constants.PhoneOTPPurposeSignIn)
+	if err != nil {
+		return err
+	}
+
+	challenge, err := auth.GenerateHS256JWT(map[string]interface{}{
+		"typ": constants.TokenPurposeSMSSignIn,
+		"sub": user.ID,
+		"oid": otp.ID,
+		"exp": otp.ExpiresAt.Unix(),
 	})
 	if err != nil {
-		return entities.User{}, err
+		return err
 	}
 
-	templateData := utils.TemplateData{
-		Path:    "pkg/shared/template/verify_email_template.html",
-		To:      req.Email,
-		Subject: "Confirm your email address",
-		Url:     os.Getenv("BASE_URL") + "auth/verify-email/" + encodedEmail + "/" + token,
+	return &dtos.AuthError{
+		Code:      constants.AuthErrorSMSCodeRequired,
+		Message:   "sms code required",
+		Challenge: challenge,
 	}
+}
 
-	err = utils.SendTemplateEMail(templateData)
+func (au *AuthUsecase) signInSMS(req dtos.SMSSignInRequest, client dtos.ClientInfo) (entities.User, string, error) {
+	user, err := au.checkSMSCode(req)
 	if err != nil {
-		return user, err
+		return entities.User{}, "", err
 	}
 
-	return user, nil
+	session, jwtToken, err := au.IssueAccessToken(user, client, nil)
+	if err != nil {
+		return entities.User{}, "", errors.New("error while generating token")
+	}
+
+	au.checkNewDevice(user, session, client)
+
+	return user, jwtToken, nil
 }
 
-func (au *AuthUsecase) SignIn(req dtos.SignInRequest) (entities.User, string, error) {
+// checkSMSCode completes the challenge sent by authenticate and returns the user
+func (au *AuthUsecase) checkSMSCode(req dtos.SMSSignInRequest) (entities.User, error) {
+	claims, err := parsePurposeToken(req.Challenge, constants.TokenPurposeSMSSignIn, "sub", "oid")
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	otp, err := au.PhoneOTPRepo.TakeByConditions(map[string]interface{}{
+		"id":      claims["oid"],
+		"user_id": claims["sub"],
+		"purpose": constants.PhoneOTPPurposeSignIn,
+	})
+	if err != nil {
+		return entities.User{}, ErrPhoneOTPInvalid
+	}
+
+	err = checkPhoneOTP(au.PhoneOTPRepo, otp, req.Code)
+	if err != nil {
+		return entities.User{}, err
+	}
+
 	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
-		"email": req.Email,
+		"id": otp.UserID,
 	})
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	// the account may have changed since the password was checked
+	err = checkSignInUser(user)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return user, nil
+}
+
+func (au *AuthUsecase) authenticate(req dtos.SignInRequest) (entities.User, error) {
+	user, err := au.Authenticator.Authenticate(req)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	err = checkSignInUser(user)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	// every password sign in needs the second factor, the flows that issue
+	// their own tokens included
+	return user, au.checkSecondFactor(user)
+}
+
+func (au *AuthUsecase) signInExternal(user entities.User, client dtos.ClientInfo) (entities.User, string, error) {
+	err := checkSignInUser(user)
 	if err != nil {
 		return entities.User{}, "", err
 	}
 
-	checkPassword := utils.CheckHashPassword(req.Password, user.Password)
-	if !checkPassword {
-		return entities.User{}, "", errors.New("password is incorrect")
+	// the provider only replaces the password, SignInSMS completes the challenge
+	err = au.checkSecondFactor(user)
+	if err != nil {
+		return user, "", err
 	}
 
-	jwtToken, err := auth.GenerateHS256JWT(map[string]interface{}{
-		"email": user.Email,
-		"sub":   user.ID,
-		"exp":   time.Now().Add(time.Hour * 24).Unix(),
-	})
+	session, jwtToken, err := au.IssueAccessToken(user, client, nil)
 	if err != nil {
 		return entities.User{}, "", errors.New("error while generating token")
 	}
 
+	au.checkNewDevice(user, session, client)
+
 	return user, jwtToken, nil
 }
 
-func (au *AuthUsecase) SendMailForgotPassword(req dtos.ForgotPasswordRequest) error {
-	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
-		"email": req.Email,
+// checkSignInUser refuses the accounts that may not sign in, whatever
+// confirmed the identity. The password reset is checked here rather than in a
+// backend, first_success mode would move on to the next backend and sign in
+// anyway.
+func checkSignInUser(user entities.User) error {
+	if user.DeprovisionedAt != nil {
+		return newAuthError(constants.AuthErrorUserDeprovisioned, "user is deprovisioned", nil)
+	}
+
+	if user.IsSuspended {
+		return newAuthError(constants.AuthErrorUserSuspended, "user is suspended", nil)
+	}
+
+	if !user.IsActive {
+		return newAuthError(constants.AuthErrorUserNotActive, "user is not active", nil)
+	}
+
+	if user.MustResetPassword {
+		return newAuthError(constants.AuthErrorPasswordResetRequired, "password reset is required", nil)
+	}
+
+	return nil
+}
+
+// checkSecondFactor sends the SMS challenge when the user turned it on
+func (au *AuthUsecase) checkSecondFactor(user entities.User) error {
+	if user.SMSTwoFactor && user.PhoneVerified && user.Phone != "" {
+		return au.sendSMSChallenge(user)
+	}
+
+	return nil
+}
+
+// GenerateAccessToken starts a new session for the user and returns a JWT linked to it
+func (au *AuthUsecase) GenerateAccessToken(user entities.User, client dtos.ClientInfo) (string, error) {
+	_, jwtToken, err := au.IssueAccessToken(user, client, nil)
+
+	return jwtToken, err
+}
+
+// IssueAccessToken is GenerateAccessToken with extra JWT claims, it also returns the session
+func (au *AuthUsecase) IssueAccessToken(user entities.User, client dtos.ClientInfo, extraClaims map[string]interface{}) (entities.Session, string, error) {
+	now := time.Now()
+	session, err := au.SessionRepo.CreateSession(entities.Session{
+		UserID:     user.ID,
+		Device:     utils.ParseDevice(client.UserAgent),
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(constants.AccessTokenTTL),
 	})
 	if err != nil {
-		return err
+		return entities.Session{}, "", err
 	}
 
-	if !user.IsActive {
-		return errors.New("user is not active")
+	claims := map[string]interface{}{}
+	for key, val := range extraClaims {
+		claims[key] = val
 	}
+	claims["typ"] = constants.TokenTypeAccess
+	claims["email"] = user.Email
+	claims["sub"] = user.ID
+	claims["sid"] = session.ID
+	claims["role"] = user.Role
+	claims["exp"] = session.ExpiresAt.Unix()
 
-	encodedEmail := base64.StdEncoding.EncodeToString([]byte(user.Email))
+	jwtToken, err := auth.GenerateHS256JWT(claims)
+
+	return session, jwtToken, err
+}
+
+// checkNewDevice remembers the device and network of a sign-in and sends an
+// alert when either of them was not seen before for this user
+func (au *AuthUsecase) checkNewDevice(user entities.User, session entities.Session, client dtos.ClientInfo) {
+	fingerprint := utils.DeviceFingerprint(client.UserAgent)
+	ipRange := utils.IPRange(client.IPAddress)
+
+	devices, err := au.KnownDeviceRepo.FindByConditions(map[string]interface{}{
+		"user_id": user.ID,
+	})
+	if err != nil {
+		return
+	}
+
+	knownFingerprint, knownRange := false, false
+	for _, device := range devices {
+		knownFingerprint = knownFingerprint || device.Fingerprint == fingerprint
+		knownRange = knownRange || device.IPRange == ipRange
+
+		if device.Fingerprint == fingerprint && device.IPRange == ipRange {
+			_ = au.KnownDeviceRepo.UpdateKnownDevice(device, map[string]interface{}{
+				"last_seen_at": session.CreatedAt,
+			})
+			return
+		}
+	}
+
+	_, err = au.KnownDeviceRepo.CreateKnownDevice(entities.KnownDevice{
+		UserID:      user.ID,
+		Fingerprint: fingerprint,
+		IPRange:     ipRange,
+		LastSeenAt:  session.CreatedAt,
+	})
+	if err != nil {
+		return
+	}
+
+	// the very first sign-in has nothing to compare against
+	if len(devices) == 0 || (knownFingerprint && knownRange) {
+		return
+	}
 
 	token, err := auth.GenerateHS256JWT(map[string]interface{}{
-		"email": user.Email,
-		"exp":   time.Now().Add(time.Minute * 15).Unix(),
+		"typ": constants.TokenPurposeNotMe,
+		"sub": user.ID,
+		"sid": session.ID,
+		"exp": time.Now().Add(constants.NotMeLinkTTL).Unix(),
 	})
 	if err != nil {
-		return err
+		return
 	}
 
 	templateData := utils.TemplateData{
-		Path:    "pkg/shared/template/forgot_password_template.html",
-		To:      req.Email,
-		Subject: "Forgot Password",
-		Url:     os.Getenv("BASE_URL") + "auth/reset-password/" + encodedEmail + "/" + token,
+		Template:  constants.EmailTemplateNewDevice,
+		Locale:    emails.ResolveLocale(user.Locale, client.Locale),
+		To:        user.Email,
+		Username:  user.Username,
+		Email:     user.Email,
+		Url:       os.Getenv("BASE_URL") + "auth/not-me/" + token,
+		ExpiresIn: constants.NotMeLinkTTL,
+		Extra: map[string]string{
+			"Time":      session.CreatedAt.UTC().Format(constants.DateTimeFormat) + " UTC",
+			"UserAgent": client.UserAgent,
+			"Network":   ipRange,
+		},
 	}
 
-	err = utils.SendTemplateEMail(templateData)
+	// the alert must not slow down or fail the sign-in
+	go utils.SendTemplateEmail(au.Mailer, templateData)
+}
 
-	return err
+func (au *AuthUsecase) notMe(token string, locale string) (uint, error) {
+	claims, err := parsePurposeToken(token, constants.TokenPurposeNotMe, "sub", "sid")
+	if err != nil {
+		return 0, err
+	}
+
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": claims["sub"],
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	session, err := au.SessionRepo.TakeByConditions(map[string]interface{}{
+		"id":      claims["sid"],
+		"user_id": user.ID,
+	})
+	if err != nil {
+		return user.ID, err
+	}
+
+	if session.RevokedAt == nil {
+		err = au.SessionRepo.UpdateSession(session, map[string]interface{}{
+			"revoked_at": time.Now(),
+		})
+		if err != nil {
+			return user.ID, err
+		}
+	}
+
+	err = au.UserRepo.UpdateUser(user, map[string]interface{}{
+		"must_reset_password": true,
+	})
+	if err != nil {
+		return user.ID, err
+	}
+
+	return user.ID, sendMailResetPassword(au.Mailer, user, locale)
 }
 
-func (au *AuthUsecase) ActiveUser(userID uint) error {
+func (au *AuthUsecase) sendMailForgotPassword(req dtos.ForgotPasswordRequest, locale string) (entities.User, error) {
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"email": req.Email,
+	})
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	if !user.IsActive {
+		return user, errors.New("user is not active")
+	}
+
+	return user, sendMailResetPassword(au.Mailer, user, locale)
+}
+
+func (au *AuthUsecase) activeUser(userID uint) error {
 	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
 		"id": userID,
 	})
@@ -156,7 +593,7 @@ func (au *AuthUsecase) ActiveUser(userID uint) error {
 	return err
 }
 
-func (au *AuthUsecase) ResetPassword(userID uint, req dtos.ResetPasswordRequest) error {
+func (au *AuthUsecase) resetPassword(userID uint, req dtos.ResetPasswordRequest) error {
 	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
 		"id": userID,
 	})
@@ -174,8 +611,77 @@ func (au *AuthUsecase) ResetPassword(userID uint, req dtos.ResetPasswordRequest)
 	}
 
 	err = au.UserRepo.UpdateUser(user, map[string]interface{}{
-		"password": newHashPassword,
+		"password":            newHashPassword,
+		"must_reset_password": false,
 	})
 
 	return err
 }
+
+// VerifyLink returns the user of a verify email or reset password link,
+// tokens of any other purpose are refused
+func (au *AuthUsecase) VerifyLink(token string, purpose string) (entities.User, error) {
+	if purpose != constants.TokenPurposeVerifyEmail && purpose != constants.TokenPurposeResetPassword {
+		return entities.User{}, errors.New("invalid token")
+	}
+
+	claims, err := parsePurposeToken(token, purpose, "sub")
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": claims["sub"],
+	})
+}
+
+// renderVerifyEmail renders the verification link of a new user
+func renderVerifyEmail(user entities.User) (mailer.Message, error) {
+	encodedEmail := base64.StdEncoding.EncodeToString([]byte(user.Email))
+
+	token, err := auth.GenerateHS256JWT(map[string]interface{}{
+		"typ": constants.TokenPurposeVerifyEmail,
+		"sub": user.ID,
+		"exp": time.Now().Add(constants.VerifyEmailLinkTTL).Unix(),
+	})
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	return utils.RenderTemplateEmail(utils.TemplateData{
+		Template:  constants.EmailTemplateVerifyEmail,
+		Locale:    user.Locale,
+		To:        user.Email,
+		Username:  user.Username,
+		Email:     user.Email,
+		Url:       os.Getenv("BASE_URL") + "auth/verify-email/" + encodedEmail + "/" + token,
+		ExpiresIn: constants.VerifyEmailLinkTTL,
+	})
+}
+
+// sendMailResetPassword sends the reset link in the language of the user,
+// locale is the one of the request and only used without user preference
+func sendMailResetPassword(m mailer.Mailer, user entities.User, locale string) error {
+	encodedEmail := base64.StdEncoding.EncodeToString([]byte(user.Email))
+
+	token, err := auth.GenerateHS256JWT(map[string]interface{}{
+		"typ": constants.TokenPurposeResetPassword,
+		"sub": user.ID,
+		"exp": time.Now().Add(constants.ResetPasswordLinkTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	templateData := utils.TemplateData{
+		Template:  constants.EmailTemplateForgotPassword,
+		Locale:    emails.ResolveLocale(user.Locale, locale),
+		To:        user.Email,
+		Username:  user.Username,
+		Email:     user.Email,
+		Url:       os.Getenv("BASE_URL") + "auth/reset-password/" + encodedEmail + "/" + token,
+		ExpiresIn: constants.ResetPasswordLinkTTL,
+	}
+
+	return utils.SendTemplateEmail(m, templateData)
+}
@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+)
+
+func TestVerifyLink(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", "test-secret")
+
+	users := &fakeUserRepo{}
+	jane := users.users.insert(entities.User{Email: "jane@acme.test", IsActive: true, Role: constants.RoleUser})
+	au := &AuthUsecase{UserRepo: users}
+
+	sign := func(claims map[string]interface{}) string {
+		claims["exp"] = time.Now().Add(time.Minute).Unix()
+		token, err := auth.GenerateHS256JWT(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		purpose string
+		wantErr bool
+	}{
+		{name: "reset password link", token: sign(map[string]interface{}{"typ": constants.TokenPurposeResetPassword, "sub": jane.ID}), purpose: constants.TokenPurposeResetPassword},
+		{name: "verify email link", token: sign(map[string]interface{}{"typ": constants.TokenPurposeVerifyEmail, "sub": jane.ID}), purpose: constants.TokenPurposeVerifyEmail},
+		{name: "verify email token resets the password", token: sign(map[string]interface{}{"typ": constants.TokenPurposeVerifyEmail, "sub": jane.ID}), purpose: constants.TokenPurposeResetPassword, wantErr: true},
+		{name: "access token", token: sign(map[string]interface{}{"typ": constants.TokenTypeAccess, "sub": jane.ID, "email": jane.Email}), purpose: constants.TokenPurposeResetPassword, wantErr: true},
+		{name: "OAuth access token", token: sign(map[string]interface{}{"sub": jane.ID, "email": jane.Email}), purpose: constants.TokenPurposeResetPassword, wantErr: true},
+		{name: "not a link purpose", token: sign(map[string]interface{}{"typ": constants.TokenPurposeNotMe, "sub": jane.ID}), purpose: constants.TokenPurposeNotMe, wantErr: true},
+		{name: "unknown user", token: sign(map[string]interface{}{"typ": constants.TokenPurposeResetPassword, "sub": 42}), purpose: constants.TokenPurposeResetPassword, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := au.VerifyLink(tt.token, tt.purpose)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("VerifyLink() = %+v, want an error", user)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyLink() error = %v", err)
+			}
+			if user.ID != jane.ID {
+				t.Errorf("VerifyLink() = %+v", user)
+			}
+		})
+	}
+}
+
+func TestSignInExternalChecksTheAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     entities.User
+		wantCode int
+	}{
+		{name: "suspended", user: entities.User{IsActive: true, IsSuspended: true}, wantCode: constants.AuthErrorUserSuspended},
+		{name: "not active", user: entities.User{}, wantCode: constants.AuthErrorUserNotActive},
+		{name: "password reset required", user: entities.User{IsActive: true, MustResetPassword: true}, wantCode: constants.AuthErrorPasswordResetRequired},
+		{name: "deprovisioned", user: entities.User{IsActive: true, DeprovisionedAt: &time.Time{}}, wantCode: constants.AuthErrorUserDeprovisioned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{}
+			tt.user.Email = "jane@acme.test"
+			user := users.users.insert(tt.user)
+			sessions := &fakeSessionRepo{}
+			au := &AuthUsecase{UserRepo: users, SessionRepo: sessions, AuditUsecase: fakeAuditUsecase{}}
+
+			_, token, err := au.SignInExternal(user, constants.SignInProviderGoogle, dtos.ClientInfo{})
+			var authErr *dtos.AuthError
+			if !errors.As(err, &authErr) || authErr.Code != tt.wantCode {
+				t.Fatalf("SignInExternal() error = %v, want code %d", err, tt.wantCode)
+			}
+			if token != "" || len(sessions.sessions.rows) != 0 {
+				t.Errorf("SignInExternal() started a session")
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package usecases
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/utils"
+)
+
+// authBackendMetrics counts attempts, successes, failures per error code and
+// time spent for every backend, keys are prefixed with the backend name
+var authBackendMetrics = expvar.NewMap(constants.AuthBackendMetrics)
+
+// AuthenticatorChain runs several backends. In first_success mode the first
+// backend that accepts the credentials wins, in require_all mode every
+// backend must accept them and agree on the user. Account status checks,
+// such as a required password reset, are left to AuthUsecase.
+type AuthenticatorChain struct {
+	Authenticators []interfaces.Authenticator
+	Mode           string
+}
+
+func NewAuthenticatorChain(mode string, authenticators ...interfaces.Authenticator) interfaces.Authenticator {
+	return &AuthenticatorChain{
+		Authenticators: authenticators,
+		Mode:           mode,
+	}
+}
+
+// NewAuthenticator builds the chain from AUTH_BACKENDS, a comma separated
+// list of backends (local by default), and AUTH_CHAIN_MODE
+func NewAuthenticator(ur interfaces.UserRepository) interfaces.Authenticator {
+	names := os.Getenv("AUTH_BACKENDS")
+	if strings.TrimSpace(names) == "" {
+		names = constants.AuthBackendLocal
+	}
+
+	authenticators := []interfaces.Authenticator{}
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case constants.AuthBackendLocal:
+			authenticators = append(authenticators, NewLocalAuthenticator(ur))
+		case constants.AuthBackendLDAP:
+			authenticators = append(authenticators, NewLDAPAuthenticator(ur, auth.LoadLDAPConfig()))
+		case constants.AuthBackendHTTP:
+			authenticators = append(authenticators, NewHTTPAuthenticator(ur))
+		default:
+			// a typo must not silently drop a required backend
+			authenticators = append(authenticators, &unknownAuthenticator{name: name})
+		}
+	}
+
+	mode := constants.AuthChainFirstSuccess
+	if os.Getenv("AUTH_CHAIN_MODE") == constants.AuthChainRequireAll {
+		mode = constants.AuthChainRequireAll
+	}
+
+	return NewAuthenticatorChain(mode, authenticators...)
+}
+
+func (ac *AuthenticatorChain) Name() string {
+	names := make([]string, 0, len(ac.Authenticators))
+	for _, authenticator := range ac.Authenticators {
+		names = append(names, authenticator.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (ac *AuthenticatorChain) Authenticate(req dtos.SignInRequest) (entities.User, error) {
+	if len(ac.Authenticators) == 0 {
+		return entities.User{}, newAuthError(constants.AuthErrorBackendUnavailable, "no auth backend is configured", nil)
+	}
+
+	if ac.Mode == constants.AuthChainRequireAll {
+		return ac.authenticateAll(req)
+	}
+
+	var authErr *dtos.AuthError
+	for _, authenticator := range ac.Authenticators {
+		user, err := authenticateWithMetrics(authenticator, req)
+		if err == nil {
+			return user, nil
+		}
+
+		// a specific failure says more than a wrong password from another backend
+		if authErr == nil || (authErr.Code == constants.AuthErrorInvalidCredentials && err.Code != constants.AuthErrorInvalidCredentials) {
+			authErr = err
+		}
+	}
+
+	return entities.User{}, authErr
+}
+
+// authenticateAll is the require_all mode. The local users of external
+// backends are only synced once every backend accepted the credentials, a
+// sign in that one backend rejects must not create or change an account.
+func (ac *AuthenticatorChain) authenticateAll(req dtos.SignInRequest) (entities.User, error) {
+	users := make([]entities.User, len(ac.Authenticators))
+	externalUsers := make([]externalUser, len(ac.Authenticators))
+	for i, authenticator := range ac.Authenticators {
+		var err *dtos.AuthError
+		if external, ok := authenticator.(externalAuthenticator); ok {
+			externalUsers[i], err = verifyWithMetrics(external, req)
+		} else {
+			users[i], err = authenticateWithMetrics(authenticator, req)
+		}
+		if err != nil {
+			return entities.User{}, err
+		}
+	}
+
+	user := entities.User{}
+	for i, authenticator := range ac.Authenticators {
+		if external, ok := authenticator.(externalAuthenticator); ok {
+			backendUser, err := external.sync(externalUsers[i])
+			if err != nil {
+				return entities.User{}, toAuthError(external.Name(), err)
+			}
+			users[i] = backendUser
+		}
+
+		if user.ID != 0 && user.ID != users[i].ID {
+			return entities.User{}, newAuthError(constants.AuthErrorInvalidCredentials, "email or password is incorrect",
+				fmt.Errorf("backend %s resolved another user", authenticator.Name()))
+		}
+		user = users[i]
+	}
+
+	return user, nil
+}
+
+// authenticateWithMetrics runs one backend, errors that are not an AuthError
+// are reported as the backend being unavailable
+func authenticateWithMetrics(authenticator interfaces.Authenticator, req dtos.SignInRequest) (entities.User, *dtos.AuthError) {
+	user := entities.User{}
+	err := withMetrics(authenticator.Name(), func() (err error) {
+		user, err = authenticator.Authenticate(req)
+		return err
+	})
+
+	return user, err
+}
+
+// verifyWithMetrics checks the credentials with an external backend without syncing the local user
+func verifyWithMetrics(authenticator externalAuthenticator, req dtos.SignInRequest) (externalUser, *dtos.AuthError) {
+	user := externalUser{}
+	err := withMetrics(authenticator.Name(), func() (err error) {
+		user, err = authenticator.verify(req)
+		return err
+	})
+
+	return user, err
+}
+
+func withMetrics(name string, attempt func() error) *dtos.AuthError {
+	start := time.Now()
+	err := attempt()
+	authBackendMetrics.AddFloat(name+".seconds", time.Since(start).Seconds())
+	authBackendMetrics.Add(name+".attempts", 1)
+
+	if err == nil {
+		authBackendMetrics.Add(name+".successes", 1)
+		return nil
+	}
+
+	authErr := toAuthError(name, err)
+	authBackendMetrics.Add(fmt.Sprintf("%s.failures.%d", name, authErr.Code), 1)
+
+	return authErr
+}
+
+func toAuthError(name string, err error) *dtos.AuthError {
+	authErr := &dtos.AuthError{}
+	if !errors.As(err, &authErr) {
+		authErr = newAuthError(constants.AuthErrorBackendUnavailable, "authentication backend failed", err)
+	}
+	authErr.Backend = name
+
+	return authErr
+}
+
+// externalUser is what an external backend knows of a user once it accepted
+// the credentials. An empty Role leaves the role of the local user as is.
+type externalUser struct {
+	Email           string
+	Username        string
+	Role            string
+	JITProvisioning bool
+}
+
+// externalAuthenticator is a backend whose users live elsewhere, Authenticate
+// is verify followed by sync
+type externalAuthenticator interface {
+	interfaces.Authenticator
+	// verify checks the credentials without touching the local user
+	verify(req dtos.SignInRequest) (externalUser, error)
+	// sync finds the local user of a verified login, see syncExternalUser
+	sync(user externalUser) (entities.User, error)
+}
+
+// syncExternalUser finds the local user of an external backend, creating it
+// when just-in-time provisioning is on
+func syncExternalUser(ur interfaces.UserRepository, ext externalUser) (entities.User, error) {
+	user, err := ur.TakeByConditions(map[string]interface{}{
+		"email": ext.Email,
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.User{}, newAuthError(constants.AuthErrorBackendUnavailable, "user store is unavailable", err)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if !ext.JITProvisioning {
+			return entities.User{}, newAuthError(constants.AuthErrorUserNotProvisioned, "user does not exist", nil)
+		}
+
+		// the local password is unusable, the backend stays the source of truth
+		randomPassword, _, err := auth.GenerateOpaqueToken("")
+		if err != nil {
+			return entities.User{}, err
+		}
+
+		hashPassword, err := utils.HashPassword(randomPassword)
+		if err != nil {
+			return entities.User{}, err
+		}
+
+		role := ext.Role
+		if role == "" {
+			role = constants.RoleUser
+		}
+		return ur.CreateUser(entities.User{
+			Username: ext.Username,
+			Email:    ext.Email,
+			Password: hashPassword,
+			IsActive: true,
+			Role:     role,
+		})
+	}
+
+	data := map[string]interface{}{}
+	if ext.Username != "" && ext.Username != user.Username {
+		data["username"] = ext.Username
+	}
+	if ext.Role != "" && ext.Role != user.Role {
+		data["role"] = ext.Role
+	}
+	if len(data) == 0 {
+		return user, nil
+	}
+
+	err = ur.UpdateUser(user, data)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return ur.TakeByConditions(map[string]interface{}{
+		"id": user.ID,
+	})
+}
+
+func newAuthError(code int, message string, err error) *dtos.AuthError {
+	return &dtos.AuthError{
+		Code:    code,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// unknownAuthenticator stands for a backend name that is not supported
+type unknownAuthenticator struct {
+	name string
+}
+
+func (ua *unknownAuthenticator) Name() string {
+	return ua.name
+}
+
+func (ua *unknownAuthenticator) Authenticate(req dtos.SignInRequest) (entities.User, error) {
+	return entities.User{}, newAuthError(constants.AuthErrorBackendUnavailable, "unknown auth backend "+ua.name, nil)
+}
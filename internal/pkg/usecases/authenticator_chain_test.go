@@ -0,0 +1,78 @@
+package usecases
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+)
+
+func newTestHTTPAuthenticator(t *testing.T, users *fakeUserRepo, status int) *HTTPAuthenticator {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"email":"jane@acme.test","username":"Jane Hook"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &HTTPAuthenticator{UserRepo: users, URL: server.URL, JITProvisioning: true, Client: server.Client()}
+}
+
+func TestAuthenticateMustResetPassword(t *testing.T) {
+	la, users := newTestLDAPAuthenticator(t, nil)
+	users.users.insert(entities.User{Email: "jane@acme.test", Username: "Jane Doe", IsActive: true, Role: constants.RoleUser, MustResetPassword: true})
+
+	// the local password is wrong, first_success moves on to the directory which accepts it
+	au := &AuthUsecase{
+		UserRepo:      users,
+		Authenticator: NewAuthenticatorChain(constants.AuthChainFirstSuccess, NewLocalAuthenticator(users), la),
+	}
+
+	_, err := au.authenticate(dtos.SignInRequest{Email: "jane@acme.test", Password: "jane-secret"})
+	var authErr *dtos.AuthError
+	if !errors.As(err, &authErr) || authErr.Code != constants.AuthErrorPasswordResetRequired {
+		t.Fatalf("authenticate() error = %v, want code %d", err, constants.AuthErrorPasswordResetRequired)
+	}
+}
+
+func TestAuthenticatorChainRequireAll(t *testing.T) {
+	tests := []struct {
+		name       string
+		hookStatus int
+		wantCode   int
+	}{
+		{name: "every backend accepts", hookStatus: http.StatusOK},
+		{name: "the last backend rejects", hookStatus: http.StatusUnauthorized, wantCode: constants.AuthErrorInvalidCredentials},
+		{name: "the last backend is down", hookStatus: http.StatusBadGateway, wantCode: constants.AuthErrorBackendUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			la, users := newTestLDAPAuthenticator(t, []string{testLDAPAdmins})
+			chain := NewAuthenticatorChain(constants.AuthChainRequireAll, la, newTestHTTPAuthenticator(t, users, tt.hookStatus))
+
+			user, err := chain.Authenticate(dtos.SignInRequest{Email: "jane@acme.test", Password: "jane-secret"})
+			if tt.wantCode != 0 {
+				var authErr *dtos.AuthError
+				if !errors.As(err, &authErr) || authErr.Code != tt.wantCode {
+					t.Fatalf("Authenticate() error = %v, want code %d", err, tt.wantCode)
+				}
+				if len(users.users.rows) != 0 {
+					t.Errorf("a rejected sign in provisioned %+v", *users.users.rows[0])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if user.Email != "jane@acme.test" || user.Role != constants.RoleAdmin || len(users.users.rows) != 1 {
+				t.Errorf("Authenticate() = %+v", user)
+			}
+		})
+	}
+}
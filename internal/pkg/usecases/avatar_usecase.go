@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/avatar"
+	"engine/pkg/shared/blob"
+	"engine/pkg/shared/constants"
+)
+
+// ErrNoAvatar is returned when deleting the avatar of a user that uploaded none
+var ErrNoAvatar = errors.New("no avatar uploaded")
+
+type AvatarUsecase struct {
+	UserRepo     interfaces.UserRepository
+	BlobStore    blob.BlobStore
+	AuditUsecase interfaces.AuditUsecase
+}
+
+func NewAvatarUsecase(ur interfaces.UserRepository, store blob.BlobStore, auditUsecase interfaces.AuditUsecase) interfaces.AvatarUsecase {
+	return &AvatarUsecase{
+		UserRepo:     ur,
+		BlobStore:    store,
+		AuditUsecase: auditUsecase,
+	}
+}
+
+func (au *AvatarUsecase) UploadAvatar(userID uint, data []byte, client dtos.ClientInfo) (entities.User, error) {
+	user, err := au.uploadAvatar(userID, data)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionAvatarUploaded, client, userID, err, nil)
+
+	return user, err
+}
+
+func (au *AvatarUsecase) DeleteAvatar(userID uint, client dtos.ClientInfo) (entities.User, error) {
+	user, err := au.deleteAvatar(userID)
+	recordAuditEvent(au.AuditUsecase, constants.AuditActionAvatarDeleted, client, userID, err, nil)
+
+	return user, err
+}
+
+func (au *AvatarUsecase) uploadAvatar(userID uint, data []byte) (entities.User, error) {
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	renditions, err := avatar.Process(data)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	// every upload gets its own directory, so cached URLs of the previous
+	// avatar never serve the new one
+	random := make([]byte, 8)
+	_, err = rand.Read(random)
+	if err != nil {
+		return entities.User{}, err
+	}
+	dir := fmt.Sprintf("avatars/%d/%s", user.ID, hex.EncodeToString(random))
+
+	keys := make([]string, 0, len(renditions))
+	for _, rendition := range renditions {
+		key := avatar.Key(dir, rendition.Size, rendition.Ext)
+		err = au.BlobStore.Put(key, rendition.Data, rendition.ContentType)
+		if err != nil {
+			au.deleteBlobs(keys)
+			return entities.User{}, err
+		}
+		keys = append(keys, key)
+	}
+
+	err = au.UserRepo.UpdateUser(user, map[string]interface{}{
+		"avatar_key": keys[0],
+	})
+	if err != nil {
+		au.deleteBlobs(keys)
+		return entities.User{}, err
+	}
+
+	au.deleteAvatarBlobs(user.AvatarKey)
+	user.AvatarKey = keys[0]
+
+	return user, nil
+}
+
+func (au *AvatarUsecase) deleteAvatar(userID uint) (entities.User, error) {
+	user, err := au.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	if user.AvatarKey == "" {
+		return entities.User{}, ErrNoAvatar
+	}
+
+	err = au.UserRepo.UpdateUser(user, map[string]interface{}{
+		"avatar_key": "",
+	})
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	au.deleteAvatarBlobs(user.AvatarKey)
+	user.AvatarKey = ""
+
+	return user, nil
+}
+
+// deleteAvatarBlobs removes every size of the avatar stored at key
+func (au *AvatarUsecase) deleteAvatarBlobs(key string) {
+	if key == "" {
+		return
+	}
+
+	keys := make([]string, 0, len(avatar.Sizes))
+	for _, size := range avatar.Sizes {
+		keys = append(keys, avatar.SizeKey(key, size))
+	}
+	au.deleteBlobs(keys)
+}
+
+// deleteBlobs is a cleanup, a leftover blob is not worth failing the request
+func (au *AvatarUsecase) deleteBlobs(keys []string) {
+	for _, key := range keys {
+		_ = au.BlobStore.Delete(key)
+	}
+}
@@ -0,0 +1,206 @@
+package usecases
+
+import (
+	"errors"
+	"time"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/mailer"
+)
+
+// OutboxMailer queues messages in email_outbox instead of sending them. Built
+// on the repositories of a transaction, the email is only queued if the
+// transaction commits.
+type OutboxMailer struct {
+	EmailOutboxRepo interfaces.EmailOutboxRepository
+}
+
+func NewOutboxMailer(eor interfaces.EmailOutboxRepository) mailer.Mailer {
+	return &OutboxMailer{
+		EmailOutboxRepo: eor,
+	}
+}
+
+func (om *OutboxMailer) Send(msg mailer.Message) error {
+	if msg.To == "" {
+		return errors.New("email has no recipient")
+	}
+
+	_, err := om.EmailOutboxRepo.CreateEmail(entities.EmailOutbox{
+		Recipient:     msg.To,
+		Subject:       msg.Subject,
+		HTMLBody:      msg.HTMLBody,
+		TextBody:      msg.TextBody,
+		Status:        constants.EmailStatusPending,
+		NextAttemptAt: time.Now(),
+	})
+
+	return err
+}
+
+type EmailOutboxUsecase struct {
+	EmailOutboxRepo interfaces.EmailOutboxRepository
+	Mailer          mailer.Mailer
+}
+
+func NewEmailOutboxUsecase(eor interfaces.EmailOutboxRepository, m mailer.Mailer) interfaces.EmailOutboxUsecase {
+	return &EmailOutboxUsecase{
+		EmailOutboxRepo: eor,
+		Mailer:          m,
+	}
+}
+
+func (eu *EmailOutboxUsecase) ListEmails(req dtos.ListEmailOutboxRequest) ([]entities.EmailOutbox, dtos.PaginationResponse, error) {
+	page := req.Page
+	if page == 0 {
+		page = 1
+	}
+
+	pageSize := req.PageSize
+	if pageSize == 0 {
+		pageSize = constants.DefaultPageSize
+	}
+	if pageSize > constants.MaxPageSize {
+		pageSize = constants.MaxPageSize
+	}
+
+	conditions := map[string]interface{}{}
+	if req.Status != "" {
+		conditions["status"] = req.Status
+	}
+	if req.Recipient != "" {
+		conditions["recipient"] = req.Recipient
+	}
+
+	emails, total, err := eu.EmailOutboxRepo.PaginateByConditions(conditions, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, dtos.PaginationResponse{}, err
+	}
+
+	return emails, dtos.PaginationResponse{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+// ResendEmail queues a sent or dead email again with a fresh attempt budget
+func (eu *EmailOutboxUsecase) ResendEmail(emailID uint) error {
+	email, err := eu.EmailOutboxRepo.TakeByConditions(map[string]interface{}{
+		"id": emailID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if email.Status == constants.EmailStatusPending {
+		return errors.New("email is already queued")
+	}
+
+	return eu.EmailOutboxRepo.UpdateEmail(email, map[string]interface{}{
+		"status":          constants.EmailStatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"locked_until":    nil,
+		"last_error":      "",
+	})
+}
+
+func (eu *EmailOutboxUsecase) DeliverDue() (int, error) {
+	now := time.Now()
+	emails, err := eu.EmailOutboxRepo.FindDue(now, constants.EmailOutboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, email := range emails {
+		// another instance may have picked the email since FindDue
+		claimed, err := eu.EmailOutboxRepo.ClaimEmail(email, now.Add(constants.EmailOutboxLockTTL))
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		delivered, err := eu.deliver(email)
+		if err != nil {
+			return sent, err
+		}
+		if delivered {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+func (eu *EmailOutboxUsecase) RunWorker(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// a failing database only delays delivery, the next tick retries
+		_, _ = eu.DeliverDue()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver sends one claimed email and records the outcome, a failed send
+// is retried with exponential backoff until it is dead-lettered. A suppressed
+// recipient is dead-lettered right away.
+func (eu *EmailOutboxUsecase) deliver(email entities.EmailOutbox) (bool, error) {
+	attempts := email.Attempts + 1
+	sendErr := eu.Mailer.Send(mailer.Message{
+		To:       email.Recipient,
+		Subject:  email.Subject,
+		HTMLBody: email.HTMLBody,
+		TextBody: email.TextBody,
+	})
+
+	now := time.Now()
+	if sendErr == nil {
+		err := eu.EmailOutboxRepo.UpdateEmail(email, map[string]interface{}{
+			"status":       constants.EmailStatusSent,
+			"attempts":     attempts,
+			"sent_at":      now,
+			"locked_until": nil,
+			"last_error":   "",
+		})
+		return true, err
+	}
+
+	data := map[string]interface{}{
+		"attempts":     attempts,
+		"locked_until": nil,
+		"last_error":   sendErr.Error(),
+	}
+	if attempts >= constants.EmailOutboxMaxAttempts || errors.Is(sendErr, mailer.ErrSuppressed) {
+		data["status"] = constants.EmailStatusDead
+	} else {
+		data["next_attempt_at"] = now.Add(outboxBackoff(attempts))
+	}
+
+	return false, eu.EmailOutboxRepo.UpdateEmail(email, data)
+}
+
+// outboxBackoff doubles the delay after every failed attempt
+func outboxBackoff(attempts int) time.Duration {
+	backoff := constants.EmailOutboxBaseBackoff
+	for i := 1; i < attempts && backoff < constants.EmailOutboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > constants.EmailOutboxMaxBackoff {
+		backoff = constants.EmailOutboxMaxBackoff
+	}
+	return backoff
+}
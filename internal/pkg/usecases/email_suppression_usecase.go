@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/mailer"
+)
+
+type EmailSuppressionUsecase struct {
+	EmailSuppressionRepo interfaces.EmailSuppressionRepository
+	UserRepo             interfaces.UserRepository
+}
+
+func NewEmailSuppressionUsecase(esr interfaces.EmailSuppressionRepository, ur interfaces.UserRepository) interfaces.EmailSuppressionUsecase {
+	return &EmailSuppressionUsecase{
+		EmailSuppressionRepo: esr,
+		UserRepo:             ur,
+	}
+}
+
+// HandleEvents suppresses hard bounced and complaining addresses. Soft
+// bounces are left to the retries of the outbox.
+func (eu *EmailSuppressionUsecase) HandleEvents(req dtos.EmailEventsRequest) (dtos.EmailEventsResponse, error) {
+	res := dtos.EmailEventsResponse{}
+	for _, event := range req.Events {
+		email := mailer.NormalizeAddress(event.Email)
+		if event.Type == constants.EmailEventBounce && event.BounceType != constants.EmailBounceHard {
+			res.Ignored++
+			continue
+		}
+
+		_, err := eu.EmailSuppressionRepo.SaveSuppression(entities.EmailSuppression{
+			Email:  email,
+			Reason: event.Type,
+			Detail: event.Reason,
+			Source: event.Provider,
+		})
+		if err != nil {
+			return res, err
+		}
+		res.Suppressed++
+
+		// a complaint is about our emails, not about the address
+		if event.Type == constants.EmailEventBounce {
+			err = eu.setEmailBounced(email, true)
+			if err != nil {
+				return res, err
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (eu *EmailSuppressionUsecase) ListSuppressions(req dtos.ListEmailSuppressionsRequest) ([]entities.EmailSuppression, dtos.PaginationResponse, error) {
+	page := req.Page
+	if page == 0 {
+		page = 1
+	}
+
+	pageSize := req.PageSize
+	if pageSize == 0 {
+		pageSize = constants.DefaultPageSize
+	}
+	if pageSize > constants.MaxPageSize {
+		pageSize = constants.MaxPageSize
+	}
+
+	conditions := map[string]interface{}{}
+	if req.Reason != "" {
+		conditions["reason"] = req.Reason
+	}
+	if req.Email != "" {
+		conditions["email"] = mailer.NormalizeAddress(req.Email)
+	}
+
+	suppressions, total, err := eu.EmailSuppressionRepo.PaginateByConditions(conditions, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, dtos.PaginationResponse{}, err
+	}
+
+	return suppressions, dtos.PaginationResponse{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+// DeleteSuppression lets emails reach the address again, for example after
+// the user fixed their mailbox
+func (eu *EmailSuppressionUsecase) DeleteSuppression(suppressionID uint) error {
+	suppression, err := eu.EmailSuppressionRepo.TakeByConditions(map[string]interface{}{
+		"id": suppressionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = eu.EmailSuppressionRepo.DeleteSuppression(suppression)
+	if err != nil {
+		return err
+	}
+
+	return eu.setEmailBounced(suppression.Email, false)
+}
+
+// setEmailBounced flags the users of the address, which is normalized while
+// theirs is stored as they typed it
+func (eu *EmailSuppressionUsecase) setEmailBounced(email string, bounced bool) error {
+	users, err := eu.UserRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if user.EmailBounced == bounced {
+			continue
+		}
+
+		err = eu.UserRepo.UpdateUser(user, map[string]interface{}{
+			"email_bounced": bounced,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
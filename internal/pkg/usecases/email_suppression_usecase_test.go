@@ -0,0 +1,68 @@
+package usecases
+
+import (
+	"testing"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+)
+
+type fakeEmailSuppressionRepo struct {
+	interfaces.EmailSuppressionRepository
+	suppressions memTable[entities.EmailSuppression]
+}
+
+func (r *fakeEmailSuppressionRepo) SaveSuppression(suppression entities.EmailSuppression) (entities.EmailSuppression, error) {
+	return r.suppressions.insert(suppression), nil
+}
+
+func (r *fakeEmailSuppressionRepo) TakeByConditions(conditions map[string]interface{}) (entities.EmailSuppression, error) {
+	return r.suppressions.take(conditions)
+}
+
+func (r *fakeEmailSuppressionRepo) PaginateByConditions(conditions map[string]interface{}, offset int, limit int) ([]entities.EmailSuppression, int64, error) {
+	suppressions := r.suppressions.find(conditions)
+	return suppressions, int64(len(suppressions)), nil
+}
+
+func (r *fakeEmailSuppressionRepo) DeleteSuppression(suppression entities.EmailSuppression) error {
+	r.suppressions.delete(map[string]interface{}{"id": suppression.ID})
+	return nil
+}
+
+func TestEmailSuppressionMixedCase(t *testing.T) {
+	users := &fakeUserRepo{}
+	jane := users.users.insert(entities.User{Email: "Jane.Doe@Acme.test"})
+	suppressions := &fakeEmailSuppressionRepo{}
+	eu := &EmailSuppressionUsecase{EmailSuppressionRepo: suppressions, UserRepo: users}
+
+	_, err := eu.HandleEvents(dtos.EmailEventsRequest{Events: []dtos.EmailEvent{{
+		Type:       constants.EmailEventBounce,
+		BounceType: constants.EmailBounceHard,
+		Email:      " JANE.doe@acme.TEST",
+	}}})
+	if err != nil {
+		t.Fatalf("HandleEvents() error = %v", err)
+	}
+
+	if user, _ := users.TakeByConditions(map[string]interface{}{"id": jane.ID}); !user.EmailBounced {
+		t.Errorf("the bounce did not flag %q", user.Email)
+	}
+
+	listed, _, err := eu.ListSuppressions(dtos.ListEmailSuppressionsRequest{Email: "Jane.Doe@Acme.test"})
+	if err != nil {
+		t.Fatalf("ListSuppressions() error = %v", err)
+	}
+	if len(listed) != 1 || listed[0].Email != "jane.doe@acme.test" {
+		t.Fatalf("ListSuppressions() = %+v", listed)
+	}
+
+	if err := eu.DeleteSuppression(listed[0].ID); err != nil {
+		t.Fatalf("DeleteSuppression() error = %v", err)
+	}
+	if user, _ := users.TakeByConditions(map[string]interface{}{"id": jane.ID}); user.EmailBounced {
+		t.Errorf("deleting the suppression did not clear %q", user.Email)
+	}
+}
@@ -0,0 +1,299 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+// memTable is an in-memory table for the fake repositories. Conditions and
+// updates use the gorm column names, like the real repositories do.
+type memTable[T any] struct {
+	rows []*T
+}
+
+func (t *memTable[T]) insert(row T) T {
+	columnsOf(&row)["id"].SetUint(uint64(len(t.rows) + 1))
+	t.rows = append(t.rows, &row)
+	return row
+}
+
+func (t *memTable[T]) find(conditions map[string]interface{}) []T {
+	rows := []T{}
+	for _, row := range t.rows {
+		if matchesConditions(row, conditions) {
+			rows = append(rows, *row)
+		}
+	}
+	return rows
+}
+
+func (t *memTable[T]) take(conditions map[string]interface{}) (T, error) {
+	rows := t.find(conditions)
+	if len(rows) == 0 {
+		var zero T
+		return zero, gorm.ErrRecordNotFound
+	}
+	return rows[0], nil
+}
+
+// update applies data to the rows matching conditions and returns how many changed
+func (t *memTable[T]) update(conditions map[string]interface{}, data map[string]interface{}) int {
+	updated := 0
+	for _, row := range t.rows {
+		if !matchesConditions(row, conditions) {
+			continue
+		}
+		columns := columnsOf(row)
+		for column, value := range data {
+			setColumn(columns[column], value)
+		}
+		updated++
+	}
+	return updated
+}
+
+func (t *memTable[T]) delete(conditions map[string]interface{}) {
+	rows := t.rows[:0]
+	for _, row := range t.rows {
+		if !matchesConditions(row, conditions) {
+			rows = append(rows, row)
+		}
+	}
+	t.rows = rows
+}
+
+// columnsOf maps the gorm column names of an entity to its fields
+func columnsOf(entity interface{}) map[string]reflect.Value {
+	columns := map[string]reflect.Value{}
+	collectColumns(reflect.ValueOf(entity).Elem(), columns)
+	return columns
+}
+
+func collectColumns(v reflect.Value, columns map[string]reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous {
+			collectColumns(v.Field(i), columns)
+			continue
+		}
+		columns[columnName(field)] = v.Field(i)
+	}
+}
+
+func columnName(field reflect.StructField) string {
+	for _, part := range strings.Split(field.Tag.Get("gorm"), ";") {
+		if strings.HasPrefix(part, "column:") {
+			return strings.TrimPrefix(part, "column:")
+		}
+	}
+	if field.Name == "ID" {
+		return "id"
+	}
+
+	name := ""
+	for i, r := range field.Name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			name += "_"
+		}
+		name += strings.ToLower(string(r))
+	}
+	return name
+}
+
+// matchesConditions compares like a gorm map condition, a slice is an IN
+func matchesConditions(entity interface{}, conditions map[string]interface{}) bool {
+	columns := columnsOf(entity)
+	for column, want := range conditions {
+		field, ok := columns[column]
+		if !ok {
+			panic("fake repository: unknown column " + column)
+		}
+		if !matchesValue(field, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesValue(field reflect.Value, want interface{}) bool {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return want == nil
+		}
+		field = field.Elem()
+	}
+	if want == nil {
+		return false
+	}
+
+	wantValue := reflect.ValueOf(want)
+	if wantValue.Kind() == reflect.Slice {
+		for i := 0; i < wantValue.Len(); i++ {
+			if matchesValue(field, wantValue.Index(i).Interface()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return fmt.Sprint(field.Interface()) == fmt.Sprint(want)
+}
+
+func setColumn(field reflect.Value, value interface{}) {
+	if !field.IsValid() {
+		panic(fmt.Sprintf("fake repository: unknown column for %v", value))
+	}
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+
+	v := reflect.ValueOf(value)
+	if field.Kind() == reflect.Ptr && v.Type().ConvertibleTo(field.Type().Elem()) {
+		ptr := reflect.New(field.Type().Elem())
+		ptr.Elem().Set(v.Convert(field.Type().Elem()))
+		field.Set(ptr)
+		return
+	}
+	field.Set(v.Convert(field.Type()))
+}
+
+type fakeUserRepo struct {
+	interfaces.UserRepository
+	users memTable[entities.User]
+}
+
+func (r *fakeUserRepo) CreateUser(user entities.User) (entities.User, error) {
+	if _, err := r.users.take(map[string]interface{}{"email": user.Email}); err == nil {
+		return entities.User{}, errors.New("duplicate key value violates unique constraint")
+	}
+	return r.users.insert(user), nil
+}
+
+func (r *fakeUserRepo) FindByConditions(conditions map[string]interface{}) ([]entities.User, error) {
+	return r.users.find(conditions), nil
+}
+
+func (r *fakeUserRepo) TakeByConditions(conditions map[string]interface{}) (entities.User, error) {
+	return r.users.take(conditions)
+}
+
+func (r *fakeUserRepo) FindByEmail(email string) ([]entities.User, error) {
+	users := []entities.User{}
+	for _, user := range r.users.rows {
+		if strings.EqualFold(user.Email, email) {
+			users = append(users, *user)
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepo) UpdateUser(user entities.User, data map[string]interface{}) error {
+	r.users.update(map[string]interface{}{"id": user.ID}, data)
+	return nil
+}
+
+func (r *fakeUserRepo) DeleteUser(user entities.User) error {
+	r.users.delete(map[string]interface{}{"id": user.ID})
+	return nil
+}
+
+type fakeOrganizationRepo struct {
+	interfaces.OrganizationRepository
+	orgs    memTable[entities.Organization]
+	members memTable[entities.OrganizationMember]
+}
+
+func (r *fakeOrganizationRepo) TakeByConditions(conditions map[string]interface{}) (entities.Organization, error) {
+	return r.orgs.take(conditions)
+}
+
+func (r *fakeOrganizationRepo) TakeMember(conditions map[string]interface{}) (entities.OrganizationMember, error) {
+	return r.members.take(conditions)
+}
+
+func (r *fakeOrganizationRepo) AddMember(member entities.OrganizationMember) error {
+	_, err := r.members.take(map[string]interface{}{
+		"organization_id": member.OrganizationID,
+		"user_id":         member.UserID,
+	})
+	if err == nil {
+		return nil
+	}
+	r.members.insert(member)
+	return nil
+}
+
+func (r *fakeOrganizationRepo) PaginateMembers(conditions map[string]interface{}, offset int, limit int) ([]entities.OrganizationMember, int64, error) {
+	members := r.members.find(conditions)
+	total := int64(len(members))
+	if offset > len(members) {
+		offset = len(members)
+	}
+	members = members[offset:]
+	if limit < len(members) {
+		members = members[:limit]
+	}
+	return members, total, nil
+}
+
+func (r *fakeOrganizationRepo) UpdateMember(member entities.OrganizationMember, data map[string]interface{}) error {
+	r.members.update(map[string]interface{}{"id": member.ID}, data)
+	return nil
+}
+
+func (r *fakeOrganizationRepo) RemoveMember(member entities.OrganizationMember) error {
+	r.members.delete(map[string]interface{}{"id": member.ID})
+	return nil
+}
+
+type fakeSessionRepo struct {
+	interfaces.SessionRepository
+	sessions memTable[entities.Session]
+}
+
+func (r *fakeSessionRepo) RevokeSessions(userID uint, exceptSessionID uint) error {
+	for _, session := range r.sessions.find(map[string]interface{}{"user_id": userID, "revoked_at": nil}) {
+		if session.ID != exceptSessionID {
+			r.sessions.update(map[string]interface{}{"id": session.ID}, map[string]interface{}{"revoked_at": time.Now()})
+		}
+	}
+	return nil
+}
+
+type fakeRefreshTokenRepo struct {
+	interfaces.OAuthRefreshTokenRepository
+	tokens memTable[entities.OAuthRefreshToken]
+}
+
+func (r *fakeRefreshTokenRepo) RevokeByUserID(userID uint) error {
+	r.tokens.update(map[string]interface{}{"user_id": userID, "revoked_at": nil}, map[string]interface{}{"revoked_at": time.Now()})
+	return nil
+}
+
+type fakeAuthUsecase struct {
+	interfaces.AuthUsecase
+}
+
+func (fakeAuthUsecase) IssueAccessToken(user entities.User, client dtos.ClientInfo, extraClaims map[string]interface{}) (entities.Session, string, error) {
+	session := entities.Session{UserID: user.ID}
+	session.ID = 1
+	return session, fmt.Sprintf("token-%d", user.ID), nil
+}
+
+type fakeAuditUsecase struct {
+	interfaces.AuditUsecase
+}
+
+func (fakeAuditUsecase) Record(action string, client dtos.ClientInfo, targetID *uint, err error, metadata map[string]interface{}) error {
+	return nil
+}
@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+)
+
+// HTTPAuthenticator delegates the credential check to an external hook. The
+// hook answers 200 with the user on success and 401 or 403 on bad credentials.
+type HTTPAuthenticator struct {
+	UserRepo        interfaces.UserRepository
+	URL             string
+	Token           string
+	JITProvisioning bool
+	Client          *http.Client
+}
+
+func NewHTTPAuthenticator(ur interfaces.UserRepository) interfaces.Authenticator {
+	return &HTTPAuthenticator{
+		UserRepo:        ur,
+		URL:             os.Getenv("AUTH_HTTP_HOOK_URL"),
+		Token:           os.Getenv("AUTH_HTTP_HOOK_TOKEN"),
+		JITProvisioning: os.Getenv("AUTH_HTTP_HOOK_JIT_PROVISIONING") != "false",
+		Client:          &http.Client{Timeout: constants.AuthHTTPHookTimeout},
+	}
+}
+
+func (ha *HTTPAuthenticator) Name() string {
+	return constants.AuthBackendHTTP
+}
+
+func (ha *HTTPAuthenticator) Authenticate(req dtos.SignInRequest) (entities.User, error) {
+	user, err := ha.verify(req)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return ha.sync(user)
+}
+
+func (ha *HTTPAuthenticator) verify(req dtos.SignInRequest) (externalUser, error) {
+	if ha.URL == "" {
+		return externalUser{}, newAuthError(constants.AuthErrorBackendUnavailable, "AUTH_HTTP_HOOK_URL is not set", nil)
+	}
+
+	body, err := json.Marshal(dtos.HTTPAuthHookRequest{
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		return externalUser{}, err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, ha.URL, bytes.NewReader(body))
+	if err != nil {
+		return externalUser{}, newAuthError(constants.AuthErrorBackendUnavailable, "auth hook is unavailable", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if ha.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+ha.Token)
+	}
+
+	resp, err := ha.Client.Do(httpReq)
+	if err != nil {
+		return externalUser{}, newAuthError(constants.AuthErrorBackendUnavailable, "auth hook is unavailable", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return externalUser{}, newAuthError(constants.AuthErrorInvalidCredentials, "email or password is incorrect", nil)
+	default:
+		return externalUser{}, newAuthError(constants.AuthErrorBackendUnavailable, "auth hook is unavailable",
+			fmt.Errorf("auth hook answered %d", resp.StatusCode))
+	}
+
+	hookRes := dtos.HTTPAuthHookResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&hookRes)
+	if err != nil {
+		return externalUser{}, newAuthError(constants.AuthErrorBackendUnavailable, "auth hook answer is invalid", err)
+	}
+
+	email := strings.ToLower(hookRes.Email)
+	if email == "" {
+		email = strings.ToLower(req.Email)
+	}
+	username := hookRes.Username
+	if username == "" {
+		username = email
+	}
+
+	// an unknown role is ignored rather than trusted
+	role := ""
+	if hookRes.Role == constants.RoleUser || hookRes.Role == constants.RoleAdmin {
+		role = hookRes.Role
+	}
+
+	return externalUser{
+		Email:           email,
+		Username:        username,
+		Role:            role,
+		JITProvisioning: ha.JITProvisioning,
+	}, nil
+}
+
+func (ha *HTTPAuthenticator) sync(user externalUser) (entities.User, error) {
+	return syncExternalUser(ha.UserRepo, user)
+}
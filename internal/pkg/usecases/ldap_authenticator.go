@@ -0,0 +1,72 @@
+package usecases
+
+import (
+	"errors"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+)
+
+// LDAPAuthenticator binds against the directory and keeps the local user in
+// sync with the entry, the local password is never checked
+type LDAPAuthenticator struct {
+	UserRepo interfaces.UserRepository
+	Config   auth.LDAPConfig
+}
+
+func NewLDAPAuthenticator(ur interfaces.UserRepository, config auth.LDAPConfig) interfaces.Authenticator {
+	return &LDAPAuthenticator{
+		UserRepo: ur,
+		Config:   config,
+	}
+}
+
+func (la *LDAPAuthenticator) Name() string {
+	return constants.AuthBackendLDAP
+}
+
+func (la *LDAPAuthenticator) Authenticate(req dtos.SignInRequest) (entities.User, error) {
+	user, err := la.verify(req)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return la.sync(user)
+}
+
+func (la *LDAPAuthenticator) verify(req dtos.SignInRequest) (externalUser, error) {
+	entry, err := auth.LDAPBind(la.Config, req.Email, req.Password)
+	if errors.Is(err, auth.ErrLDAPInvalidCredentials) {
+		return externalUser{}, newAuthError(constants.AuthErrorInvalidCredentials, "email or password is incorrect", err)
+	}
+	if err != nil {
+		return externalUser{}, newAuthError(constants.AuthErrorBackendUnavailable, "directory is unavailable", err)
+	}
+
+	if entry.Email == "" {
+		return externalUser{}, newAuthError(constants.AuthErrorUserNotProvisioned, "directory entry has no email", nil)
+	}
+
+	// the directory only owns the role when admin groups are configured
+	role := ""
+	if len(la.Config.AdminGroups) > 0 {
+		role = constants.RoleUser
+		if auth.IsLDAPAdmin(la.Config, entry) {
+			role = constants.RoleAdmin
+		}
+	}
+
+	return externalUser{
+		Email:           entry.Email,
+		Username:        entry.Username,
+		Role:            role,
+		JITProvisioning: la.Config.JITProvisioning,
+	}, nil
+}
+
+func (la *LDAPAuthenticator) sync(user externalUser) (entities.User, error) {
+	return syncExternalUser(la.UserRepo, user)
+}
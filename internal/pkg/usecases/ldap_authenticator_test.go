@@ -0,0 +1,143 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/auth/ldaptest"
+	"engine/pkg/shared/constants"
+)
+
+const testLDAPAdmins = "cn=admins,ou=groups,dc=acme,dc=test"
+
+func newTestLDAPAuthenticator(t *testing.T, adminGroups []string) (*LDAPAuthenticator, *fakeUserRepo) {
+	t.Helper()
+
+	server := ldaptest.NewServer(
+		ldaptest.Entry{
+			DN:       "uid=jane,ou=people,dc=acme,dc=test",
+			Password: "jane-secret",
+			Attributes: map[string][]string{
+				"mail":     {"jane@acme.test"},
+				"cn":       {"Jane Doe"},
+				"memberOf": {testLDAPAdmins},
+			},
+		},
+		ldaptest.Entry{
+			DN:       "uid=john,ou=people,dc=acme,dc=test",
+			Password: "john-secret",
+			Attributes: map[string][]string{
+				"mail":     {"john@acme.test"},
+				"cn":       {"John Doe"},
+				"memberOf": {"cn=staff,ou=groups,dc=acme,dc=test"},
+			},
+		},
+	)
+	t.Cleanup(server.Close)
+
+	users := &fakeUserRepo{}
+	return &LDAPAuthenticator{
+		UserRepo: users,
+		Config: auth.LDAPConfig{
+			URL:               server.URL,
+			BaseDN:            "ou=people,dc=acme,dc=test",
+			UserFilter:        "(mail=%s)",
+			EmailAttribute:    "mail",
+			UsernameAttribute: "cn",
+			GroupAttribute:    "memberOf",
+			AdminGroups:       adminGroups,
+			JITProvisioning:   true,
+		},
+	}, users
+}
+
+func TestLDAPAuthenticatorCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantCode int
+	}{
+		{name: "successful bind", email: "jane@acme.test", password: "jane-secret"},
+		{name: "wrong password", email: "jane@acme.test", password: "john-secret", wantCode: constants.AuthErrorInvalidCredentials},
+		{name: "empty password", email: "jane@acme.test", password: "", wantCode: constants.AuthErrorInvalidCredentials},
+		{name: "unknown login", email: "nobody@acme.test", password: "jane-secret", wantCode: constants.AuthErrorInvalidCredentials},
+		{name: "filter injection", email: "*", password: "jane-secret", wantCode: constants.AuthErrorInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			la, users := newTestLDAPAuthenticator(t, nil)
+
+			user, err := la.Authenticate(dtos.SignInRequest{Email: tt.email, Password: tt.password})
+			if tt.wantCode != 0 {
+				var authErr *dtos.AuthError
+				if !errors.As(err, &authErr) || authErr.Code != tt.wantCode {
+					t.Fatalf("Authenticate() error = %v, want code %d", err, tt.wantCode)
+				}
+				if len(users.users.rows) != 0 {
+					t.Errorf("a failed bind provisioned a user")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if user.Email != "jane@acme.test" || user.Username != "Jane Doe" || !user.IsActive {
+				t.Errorf("Authenticate() = %+v", user)
+			}
+		})
+	}
+}
+
+func TestLDAPAuthenticatorUnavailable(t *testing.T) {
+	la, _ := newTestLDAPAuthenticator(t, nil)
+	la.Config.URL = "ldap://127.0.0.1:1"
+
+	_, err := la.Authenticate(dtos.SignInRequest{Email: "jane@acme.test", Password: "jane-secret"})
+	var authErr *dtos.AuthError
+	if !errors.As(err, &authErr) || authErr.Code != constants.AuthErrorBackendUnavailable {
+		t.Fatalf("Authenticate() error = %v, want code %d", err, constants.AuthErrorBackendUnavailable)
+	}
+}
+
+func TestLDAPAuthenticatorGroupRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		adminGroups []string
+		email       string
+		password    string
+		existing    string
+		wantRole    string
+	}{
+		{name: "admin group member", adminGroups: []string{testLDAPAdmins}, email: "jane@acme.test", password: "jane-secret", wantRole: constants.RoleAdmin},
+		{name: "admin group DN case differs", adminGroups: []string{"CN=Admins,OU=Groups,DC=acme,DC=test"}, email: "jane@acme.test", password: "jane-secret", wantRole: constants.RoleAdmin},
+		{name: "not an admin group member", adminGroups: []string{testLDAPAdmins}, email: "john@acme.test", password: "john-secret", wantRole: constants.RoleUser},
+		{name: "admin removed from the group", adminGroups: []string{testLDAPAdmins}, email: "john@acme.test", password: "john-secret", existing: constants.RoleAdmin, wantRole: constants.RoleUser},
+		{name: "no admin groups provisions a user", email: "jane@acme.test", password: "jane-secret", wantRole: constants.RoleUser},
+		{name: "no admin groups keeps the local role", email: "john@acme.test", password: "john-secret", existing: constants.RoleAdmin, wantRole: constants.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			la, users := newTestLDAPAuthenticator(t, tt.adminGroups)
+			if tt.existing != "" {
+				users.users.insert(entities.User{Email: tt.email, Username: tt.email, IsActive: true, Role: tt.existing})
+			}
+
+			user, err := la.Authenticate(dtos.SignInRequest{Email: tt.email, Password: tt.password})
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if user.Role != tt.wantRole {
+				t.Errorf("role = %q, want %q", user.Role, tt.wantRole)
+			}
+			if len(users.users.rows) != 1 {
+				t.Errorf("users = %d, want 1", len(users.users.rows))
+			}
+		})
+	}
+}
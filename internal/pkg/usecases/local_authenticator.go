@@ -0,0 +1,47 @@
+package usecases
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/utils"
+)
+
+// LocalAuthenticator checks the bcrypt password stored on the user
+type LocalAuthenticator struct {
+	UserRepo interfaces.UserRepository
+}
+
+func NewLocalAuthenticator(ur interfaces.UserRepository) interfaces.Authenticator {
+	return &LocalAuthenticator{
+		UserRepo: ur,
+	}
+}
+
+func (la *LocalAuthenticator) Name() string {
+	return constants.AuthBackendLocal
+}
+
+func (la *LocalAuthenticator) Authenticate(req dtos.SignInRequest) (entities.User, error) {
+	user, err := la.UserRepo.TakeByConditions(map[string]interface{}{
+		"email": req.Email,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.User{}, newAuthError(constants.AuthErrorInvalidCredentials, "email or password is incorrect", err)
+	}
+	if err != nil {
+		return entities.User{}, newAuthError(constants.AuthErrorBackendUnavailable, "user store is unavailable", err)
+	}
+
+	checkPassword := utils.CheckHashPassword(req.Password, user.Password)
+	if !checkPassword {
+		return entities.User{}, newAuthError(constants.AuthErrorInvalidCredentials, "email or password is incorrect", nil)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,1065 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/sms"
+	"engine/pkg/shared/utils"
+)
+
+type OAuthUsecase struct {
+	AuthUsecase      interfaces.AuthUsecase
+	UserRepo         interfaces.UserRepository
+	SessionRepo      interfaces.SessionRepository
+	ClientRepo       interfaces.OAuthClientRepository
+	CodeRepo         interfaces.OAuthAuthorizationCodeRepository
+	RefreshTokenRepo interfaces.OAuthRefreshTokenRepository
+	DeviceCodeRepo   interfaces.OAuthDeviceCodeRepository
+	RevokedTokenRepo interfaces.OAuthRevokedTokenRepository
+	PATRepo          interfaces.PersonalAccessTokenRepository
+}
+
+func NewOAuthUsecase(
+	authUsecase interfaces.AuthUsecase,
+	ur interfaces.UserRepository,
+	sr interfaces.SessionRepository,
+	cr interfaces.OAuthClientRepository,
+	acr interfaces.OAuthAuthorizationCodeRepository,
+	rtr interfaces.OAuthRefreshTokenRepository,
+	dcr interfaces.OAuthDeviceCodeRepository,
+	rvr interfaces.OAuthRevokedTokenRepository,
+	tr interfaces.PersonalAccessTokenRepository,
+) interfaces.OAuthUsecase {
+	return &OAuthUsecase{
+		AuthUsecase:      authUsecase,
+		UserRepo:         ur,
+		SessionRepo:      sr,
+		ClientRepo:       cr,
+		CodeRepo:         acr,
+		RefreshTokenRepo: rtr,
+		DeviceCodeRepo:   dcr,
+		RevokedTokenRepo: rvr,
+		PATRepo:          tr,
+	}
+}
+
+// RegisterClient returns the client and its plain secret, empty for public clients
+func (ou *OAuthUsecase) RegisterClient(ownerID uint, req dtos.CreateOAuthClientRequest) (entities.OAuthClient, string, error) {
+	clientID, _, err := auth.GenerateOpaqueToken(constants.OAuthClientIDPrefix)
+	if err != nil {
+		return entities.OAuthClient{}, "", err
+	}
+
+	client := entities.OAuthClient{
+		ClientID:               clientID,
+		Name:                   req.Name,
+		RedirectURIs:           strings.Join(req.RedirectURIs, " "),
+		PostLogoutRedirectURIs: strings.Join(req.PostLogoutRedirectURIs, " "),
+		Audiences:              strings.Join(req.Audiences, " "),
+		Scopes:                 strings.Join(req.Scopes, " "),
+		IsConfidential:         req.IsConfidential,
+		OwnerID:                ownerID,
+	}
+
+	plainSecret := ""
+	if req.IsConfidential {
+		plainSecret, client.ClientSecretHash, err = auth.GenerateOpaqueToken(constants.OAuthClientSecretPrefix)
+		if err != nil {
+			return entities.OAuthClient{}, "", err
+		}
+	}
+
+	client, err = ou.ClientRepo.CreateClient(client)
+	if err != nil {
+		return entities.OAuthClient{}, "", err
+	}
+
+	return client, plainSecret, nil
+}
+
+func (ou *OAuthUsecase) ListClients() ([]entities.OAuthClient, error) {
+	clients, err := ou.ClientRepo.FindByConditions(map[string]interface{}{})
+
+	return clients, err
+}
+
+func (ou *OAuthUsecase) DeleteClient(id uint) error {
+	client, err := ou.ClientRepo.TakeByConditions(map[string]interface{}{
+		"id": id,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ou.ClientRepo.DeleteClient(client)
+}
+
+// ValidateAuthorizeRequest checks the parts of an authorization request that
+// decide whether it is safe to redirect back to the client at all
+func (ou *OAuthUsecase) ValidateAuthorizeRequest(req dtos.AuthorizeRequest) (entities.OAuthClient, error) {
+	client, err := ou.ClientRepo.TakeByConditions(map[string]interface{}{
+		"client_id": req.ClientID,
+	})
+	if err != nil {
+		return entities.OAuthClient{}, &dtos.OAuthError{Code: "invalid_client", Description: "unknown client_id"}
+	}
+
+	if !containsField(client.RedirectURIs, req.RedirectURI) {
+		return entities.OAuthClient{}, &dtos.OAuthError{Code: "invalid_request", Description: "redirect_uri is not registered"}
+	}
+
+	return client, nil
+}
+
+// Authorize handles the consent form and returns the URL to redirect the browser to
+func (ou *OAuthUsecase) Authorize(req dtos.AuthorizeDecisionRequest, clientInfo dtos.ClientInfo) (string, error) {
+	client, err := ou.ValidateAuthorizeRequest(req.AuthorizeRequest)
+	if err != nil {
+		return "", err
+	}
+
+	if req.Decision != "allow" {
+		return authorizeRedirect(req.RedirectURI, map[string]string{
+			"error": "access_denied",
+			"state": req.State,
+		})
+	}
+
+	if req.ResponseType != "code" {
+		return authorizeRedirect(req.RedirectURI, map[string]string{
+			"error": "unsupported_response_type",
+			"state": req.State,
+		})
+	}
+
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != constants.PKCEMethodS256 {
+		return authorizeRedirect(req.RedirectURI, map[string]string{
+			"error":             "invalid_request",
+			"error_description": "PKCE with code_challenge_method S256 is required",
+			"state":             req.State,
+		})
+	}
+
+	scope, ok := narrowScope(client.Scopes, req.Scope)
+	if !ok {
+		return authorizeRedirect(req.RedirectURI, map[string]string{
+			"error": "invalid_scope",
+			"state": req.State,
+		})
+	}
+
+	// credentials are checked by the regular sign-in, SMS second factor
+	// included, errors are shown on the consent page
+	user, err := ou.authenticateConsent(req, clientInfo)
+	if err != nil {
+		return "", consentError(err)
+	}
+
+	plainCode, codeHash, err := auth.GenerateOpaqueToken("")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = ou.CodeRepo.CreateCode(entities.OAuthAuthorizationCode{
+		CodeHash:            codeHash,
+		ClientID:            client.ClientID,
+		UserID:              user.ID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               scope,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
+		ExpiresAt:           time.Now().Add(constants.OAuthCodeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return authorizeRedirect(req.RedirectURI, map[string]string{
+		"code":  plainCode,
+		"state": req.State,
+	})
+}
+
+func (ou *OAuthUsecase) Token(req dtos.TokenRequest, clientInfo dtos.ClientInfo) (dtos.TokenResponse, error) {
+	client, err := ou.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+
+	switch req.GrantType {
+	case constants.GrantTypeAuthorizationCode:
+		return ou.exchangeAuthorizationCode(client, req, clientInfo)
+	case constants.GrantTypeRefreshToken:
+		return ou.exchangeRefreshToken(client, req, clientInfo)
+	case constants.GrantTypeClientCredentials:
+		return ou.issueClientToken(client, req)
+	case constants.GrantTypeDeviceCode:
+		return ou.exchangeDeviceCode(client, req, clientInfo)
+	default:
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "unsupported_grant_type"}
+	}
+}
+
+// authenticateClient allows public clients without a secret, confidential ones must send it
+func (ou *OAuthUsecase) authenticateClient(clientID string, clientSecret string) (entities.OAuthClient, error) {
+	client, err := ou.ClientRepo.TakeByConditions(map[string]interface{}{
+		"client_id": clientID,
+	})
+	if err != nil {
+		return entities.OAuthClient{}, &dtos.OAuthError{Code: "invalid_client"}
+	}
+
+	if client.IsConfidential {
+		hash := auth.HashOpaqueToken(clientSecret)
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(client.ClientSecretHash)) != 1 {
+			return entities.OAuthClient{}, &dtos.OAuthError{Code: "invalid_client"}
+		}
+	}
+
+	return client, nil
+}
+
+func (ou *OAuthUsecase) exchangeAuthorizationCode(client entities.OAuthClient, req dtos.TokenRequest, clientInfo dtos.ClientInfo) (dtos.TokenResponse, error) {
+	code, err := ou.CodeRepo.TakeByConditions(map[string]interface{}{
+		"code_hash": auth.HashOpaqueToken(req.Code),
+	})
+	if err != nil {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant"}
+	}
+
+	if code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI || time.Now().After(code.ExpiresAt) {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant"}
+	}
+
+	if !verifyCodeChallenge(code.CodeChallenge, req.CodeVerifier) {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant", Description: "code_verifier does not match"}
+	}
+
+	consumed, err := ou.CodeRepo.ConsumeCode(code)
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+	if !consumed {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant", Description: "code was already used"}
+	}
+
+	// the account may have been suspended since the code was issued
+	user, ok := ou.takeActiveUser(code.UserID)
+	if !ok {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant"}
+	}
+
+	return ou.issueTokens(client, user, code.Scope, code.Nonce, clientInfo)
+}
+
+// exchangeRefreshToken rotates the refresh token, the old session is closed
+func (ou *OAuthUsecase) exchangeRefreshToken(client entities.OAuthClient, req dtos.TokenRequest, clientInfo dtos.ClientInfo) (dtos.TokenResponse, error) {
+	refreshToken, err := ou.RefreshTokenRepo.TakeByConditions(map[string]interface{}{
+		"token_hash": auth.HashOpaqueToken(req.RefreshToken),
+	})
+	if err != nil || refreshToken.ClientID != client.ClientID || time.Now().After(refreshToken.ExpiresAt) {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant"}
+	}
+
+	// signing out of the session also ends its refresh tokens
+	session, err := ou.SessionRepo.TakeByConditions(map[string]interface{}{
+		"id": refreshToken.SessionID,
+	})
+	if err != nil || session.RevokedAt != nil {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant"}
+	}
+
+	revoked, err := ou.RefreshTokenRepo.RevokeToken(refreshToken)
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+	if !revoked {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant", Description: "refresh token was revoked"}
+	}
+
+	user, err := ou.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": refreshToken.UserID,
+	})
+	if err != nil || !user.IsActive || user.IsSuspended || user.DeprovisionedAt != nil {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant"}
+	}
+
+	scope := refreshToken.Scope
+	if req.Scope != "" {
+		narrowed, ok := narrowScope(refreshToken.Scope, req.Scope)
+		if !ok {
+			return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_scope"}
+		}
+		scope = narrowed
+	}
+
+	err = ou.SessionRepo.UpdateSession(session, map[string]interface{}{
+		"revoked_at": time.Now(),
+	})
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+
+	return ou.issueTokens(client, user, scope, "", clientInfo)
+}
+
+func (ou *OAuthUsecase) issueTokens(client entities.OAuthClient, user entities.User, scope string, nonce string, clientInfo dtos.ClientInfo) (dtos.TokenResponse, error) {
+	session, accessToken, err := ou.AuthUsecase.IssueAccessToken(user, clientInfo, map[string]interface{}{
+		"scope":     scope,
+		"client_id": client.ClientID,
+	})
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+
+	plainRefreshToken, refreshTokenHash, err := auth.GenerateOpaqueToken(constants.OAuthRefreshTokenPrefix)
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+
+	_, err = ou.RefreshTokenRepo.CreateToken(entities.OAuthRefreshToken{
+		TokenHash: refreshTokenHash,
+		ClientID:  client.ClientID,
+		UserID:    user.ID,
+		SessionID: session.ID,
+		Scope:     scope,
+		ExpiresAt: time.Now().Add(constants.OAuthRefreshTokenTTL),
+	})
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+
+	idToken := ""
+	if containsField(scope, constants.ScopeOpenID) {
+		idToken, err = generateIDToken(client, user, session, scope, nonce)
+		if err != nil {
+			return dtos.TokenResponse{}, err
+		}
+	}
+
+	return dtos.TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    constants.TokenTypeBearer,
+		ExpiresIn:    int64(time.Until(session.ExpiresAt).Seconds()),
+		RefreshToken: plainRefreshToken,
+		Scope:        scope,
+		IDToken:      idToken,
+	}, nil
+}
+
+// issueClientToken is the client_credentials grant, the token acts for the
+// client itself so it has no session and no refresh token
+func (ou *OAuthUsecase) issueClientToken(client entities.OAuthClient, req dtos.TokenRequest) (dtos.TokenResponse, error) {
+	if !client.IsConfidential {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "unauthorized_client", Description: "only confidential clients can use client_credentials"}
+	}
+
+	// without an issuer the audience of the engine API would be empty
+	if auth.Issuer() == "" {
+		return dtos.TokenResponse{}, errors.New("OIDC_ISSUER is not configured")
+	}
+
+	scope, ok := narrowScope(client.Scopes, req.Scope)
+	if !ok {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_scope"}
+	}
+
+	// the engine API itself is always an allowed audience
+	audience := req.Audience
+	if audience == "" {
+		audience = auth.Issuer()
+	}
+	if audience != auth.Issuer() && !containsField(client.Audiences, audience) {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_target", Description: "audience is not allowed for this client"}
+	}
+
+	// client tokens have no session, the jti is what gets revoked
+	jti, _, err := auth.GenerateOpaqueToken("")
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(constants.ClientCredentialsTokenTTL)
+	accessToken, err := auth.GenerateHS256JWT(map[string]interface{}{
+		"typ":       constants.TokenTypeAccess,
+		"jti":       jti,
+		"iss":       auth.Issuer(),
+		"sub":       constants.ClientSubjectPrefix + client.ClientID,
+		"aud":       audience,
+		"client_id": client.ClientID,
+		"scope":     scope,
+		"iat":       now.Unix(),
+		"exp":       expiresAt.Unix(),
+	})
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+
+	return dtos.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   constants.TokenTypeBearer,
+		ExpiresIn:   int64(constants.ClientCredentialsTokenTTL.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// Introspect reports whether a token is active (RFC 7662). The token type is
+// known from its format, so token_type_hint is not needed.
+func (ou *OAuthUsecase) Introspect(req dtos.IntrospectionRequest) (dtos.IntrospectionResponse, error) {
+	client, err := ou.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return dtos.IntrospectionResponse{}, err
+	}
+
+	if !client.IsConfidential {
+		return dtos.IntrospectionResponse{}, &dtos.OAuthError{Code: "unauthorized_client", Description: "only confidential clients can introspect tokens"}
+	}
+
+	switch {
+	case strings.HasPrefix(req.Token, constants.OAuthRefreshTokenPrefix):
+		return ou.introspectRefreshToken(req.Token), nil
+	case strings.HasPrefix(req.Token, constants.PersonalAccessTokenPrefix):
+		return ou.introspectPersonalAccessToken(req.Token), nil
+	default:
+		return ou.introspectAccessToken(req.Token), nil
+	}
+}
+
+func (ou *OAuthUsecase) introspectAccessToken(token string) dtos.IntrospectionResponse {
+	claims, err := auth.ParseJWT(token)
+	if err != nil || claims["typ"] != constants.TokenTypeAccess {
+		return dtos.IntrospectionResponse{}
+	}
+
+	exp, _ := claims["exp"].(float64)
+	iat, _ := claims["iat"].(float64)
+	scope, _ := claims["scope"].(string)
+	clientID, _ := claims["client_id"].(string)
+	res := dtos.IntrospectionResponse{
+		Active:    true,
+		Scope:     scope,
+		ClientID:  clientID,
+		TokenType: constants.TokenTypeBearer,
+		Exp:       int64(exp),
+		Iat:       int64(iat),
+		Iss:       auth.Issuer(),
+	}
+
+	if subject, ok := claims["sub"].(string); ok && strings.HasPrefix(subject, constants.ClientSubjectPrefix) {
+		jti, _ := claims["jti"].(string)
+		revoked, err := ou.RevokedTokenRepo.IsRevoked(jti)
+		if err != nil || revoked {
+			return dtos.IntrospectionResponse{}
+		}
+
+		_, err = ou.ClientRepo.TakeByConditions(map[string]interface{}{
+			"client_id": clientID,
+		})
+		if err != nil {
+			return dtos.IntrospectionResponse{}
+		}
+
+		res.Sub = subject
+		res.Aud, _ = claims["aud"].(string)
+		return res
+	}
+
+	sub, okSub := claims["sub"].(float64)
+	sid, okSid := claims["sid"].(float64)
+	if !okSub || !okSid {
+		return dtos.IntrospectionResponse{}
+	}
+
+	session, err := ou.SessionRepo.TakeByConditions(map[string]interface{}{
+		"id":      uint(sid),
+		"user_id": uint(sub),
+	})
+	if err != nil || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return dtos.IntrospectionResponse{}
+	}
+
+	user, ok := ou.takeActiveUser(uint(sub))
+	if !ok {
+		return dtos.IntrospectionResponse{}
+	}
+
+	res.Sub = strconv.FormatUint(uint64(user.ID), 10)
+	res.Username = user.Email
+	return res
+}
+
+func (ou *OAuthUsecase) introspectRefreshToken(token string) dtos.IntrospectionResponse {
+	refreshToken, err := ou.RefreshTokenRepo.TakeByConditions(map[string]interface{}{
+		"token_hash": auth.HashOpaqueToken(token),
+	})
+	if err != nil || refreshToken.RevokedAt != nil || time.Now().After(refreshToken.ExpiresAt) {
+		return dtos.IntrospectionResponse{}
+	}
+
+	// signing out of the session also ends its refresh tokens
+	session, err := ou.SessionRepo.TakeByConditions(map[string]interface{}{
+		"id": refreshToken.SessionID,
+	})
+	if err != nil || session.RevokedAt != nil {
+		return dtos.IntrospectionResponse{}
+	}
+
+	user, ok := ou.takeActiveUser(refreshToken.UserID)
+	if !ok {
+		return dtos.IntrospectionResponse{}
+	}
+
+	return dtos.IntrospectionResponse{
+		Active:    true,
+		Scope:     refreshToken.Scope,
+		ClientID:  refreshToken.ClientID,
+		Username:  user.Email,
+		TokenType: constants.GrantTypeRefreshToken,
+		Exp:       refreshToken.ExpiresAt.Unix(),
+		Iat:       refreshToken.CreatedAt.Unix(),
+		Sub:       strconv.FormatUint(uint64(user.ID), 10),
+		Iss:       auth.Issuer(),
+	}
+}
+
+func (ou *OAuthUsecase) introspectPersonalAccessToken(token string) dtos.IntrospectionResponse {
+	pat, err := ou.PATRepo.TakeByConditions(map[string]interface{}{
+		"token_hash": auth.HashOpaqueToken(token),
+	})
+	if err != nil || pat.RevokedAt != nil || time.Now().After(pat.ExpiresAt) {
+		return dtos.IntrospectionResponse{}
+	}
+
+	user, ok := ou.takeActiveUser(pat.UserID)
+	if !ok {
+		return dtos.IntrospectionResponse{}
+	}
+
+	return dtos.IntrospectionResponse{
+		Active:    true,
+		Scope:     pat.Scopes,
+		Username:  user.Email,
+		TokenType: constants.TokenTypeBearer,
+		Exp:       pat.ExpiresAt.Unix(),
+		Iat:       pat.CreatedAt.Unix(),
+		Sub:       strconv.FormatUint(uint64(user.ID), 10),
+		Iss:       auth.Issuer(),
+	}
+}
+
+// Revoke implements RFC 7009. A client can only revoke its own tokens, and
+// unknown or foreign tokens are not an error so nothing is leaked about them.
+func (ou *OAuthUsecase) Revoke(req dtos.RevocationRequest) error {
+	client, err := ou.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(req.Token, constants.PersonalAccessTokenPrefix) {
+		return &dtos.OAuthError{Code: "unsupported_token_type", Description: "personal access tokens are revoked from the account"}
+	}
+
+	if strings.HasPrefix(req.Token, constants.OAuthRefreshTokenPrefix) {
+		refreshToken, err := ou.RefreshTokenRepo.TakeByConditions(map[string]interface{}{
+			"token_hash": auth.HashOpaqueToken(req.Token),
+		})
+		if err != nil || refreshToken.ClientID != client.ClientID {
+			return nil
+		}
+
+		// access tokens of the same grant end with it
+		return ou.revokeSession(refreshToken.SessionID)
+	}
+
+	claims, err := auth.ParseJWT(req.Token)
+	if err != nil || claims["typ"] != constants.TokenTypeAccess || claims["client_id"] != client.ClientID {
+		return nil
+	}
+
+	if sid, ok := claims["sid"].(float64); ok {
+		return ou.revokeSession(uint(sid))
+	}
+
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+	if jti == "" {
+		return nil
+	}
+
+	err = ou.RevokedTokenRepo.RevokeJTI(entities.OAuthRevokedToken{
+		JTI:       jti,
+		ExpiresAt: time.Unix(int64(exp), 0),
+	})
+	if err != nil {
+		return err
+	}
+
+	// an expired token is refused anyway, its jti does not need to be kept
+	return ou.RevokedTokenRepo.DeleteExpired(time.Now())
+}
+
+// revokeSession signs a session out together with its refresh tokens
+func (ou *OAuthUsecase) revokeSession(sessionID uint) error {
+	session, err := ou.SessionRepo.TakeByConditions(map[string]interface{}{
+		"id": sessionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if session.RevokedAt == nil {
+		err = ou.SessionRepo.UpdateSession(session, map[string]interface{}{
+			"revoked_at": time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return ou.RefreshTokenRepo.RevokeBySessionID(session.ID)
+}
+
+// authenticateConsent checks the password of the consent form, or the SMS
+// code once the password was accepted
+func (ou *OAuthUsecase) authenticateConsent(req dtos.AuthorizeDecisionRequest, clientInfo dtos.ClientInfo) (entities.User, error) {
+	if req.Challenge != "" {
+		return ou.AuthUsecase.AuthenticateSMS(dtos.SMSSignInRequest{
+			Challenge: req.Challenge,
+			Code:      req.Code,
+		}, clientInfo)
+	}
+
+	return ou.AuthUsecase.Authenticate(dtos.SignInRequest{
+		Email:    req.Email,
+		Password: req.Password,
+	}, clientInfo)
+}
+
+// consentError keeps the account state errors of a sign in, which are only
+// reached with the right password, anything else becomes the same credential
+// error so the consent page does not tell whether an account exists
+func consentError(err error) error {
+	authErr := &dtos.AuthError{}
+	if errors.As(err, &authErr) && authErr.Code != constants.AuthErrorInvalidCredentials {
+		return authErr
+	}
+
+	// the password was already accepted, a wrong code does not tell anything
+	if errors.Is(err, ErrPhoneOTPInvalid) || errors.Is(err, ErrPhoneOTPRateLimited) || errors.Is(err, sms.ErrDisabled) {
+		return err
+	}
+
+	return newAuthError(constants.AuthErrorInvalidCredentials, "email or password is incorrect", err)
+}
+
+func (ou *OAuthUsecase) takeActiveUser(userID uint) (entities.User, bool) {
+	user, err := ou.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil || !user.IsActive || user.IsSuspended || user.DeprovisionedAt != nil {
+		return entities.User{}, false
+	}
+
+	return user, true
+}
+
+// DeviceAuthorization starts the device flow (RFC 8628) for clients that can not
+// open a browser, the user approves the user code from a signed-in device
+func (ou *OAuthUsecase) DeviceAuthorization(req dtos.DeviceAuthorizationRequest) (dtos.DeviceAuthorizationResponse, error) {
+	client, err := ou.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return dtos.DeviceAuthorizationResponse{}, err
+	}
+
+	scope, ok := narrowScope(client.Scopes, req.Scope)
+	if !ok {
+		return dtos.DeviceAuthorizationResponse{}, &dtos.OAuthError{Code: "invalid_scope"}
+	}
+
+	plainDeviceCode, deviceCodeHash, err := auth.GenerateOpaqueToken("")
+	if err != nil {
+		return dtos.DeviceAuthorizationResponse{}, err
+	}
+
+	userCode, err := generateUserCode()
+	if err != nil {
+		return dtos.DeviceAuthorizationResponse{}, err
+	}
+
+	deviceCode, err := ou.DeviceCodeRepo.CreateDeviceCode(entities.OAuthDeviceCode{
+		DeviceCodeHash: deviceCodeHash,
+		UserCode:       userCode,
+		ClientID:       client.ClientID,
+		Scope:          scope,
+		Interval:       constants.DeviceCodePollInterval,
+		ExpiresAt:      time.Now().Add(constants.DeviceCodeTTL),
+	})
+	if err != nil {
+		return dtos.DeviceAuthorizationResponse{}, err
+	}
+
+	verificationURI := os.Getenv("BASE_URL") + "device"
+	return dtos.DeviceAuthorizationResponse{
+		DeviceCode:              plainDeviceCode,
+		UserCode:                formatUserCode(userCode),
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(formatUserCode(userCode)),
+		ExpiresIn:               int64(constants.DeviceCodeTTL.Seconds()),
+		Interval:                deviceCode.Interval,
+	}, nil
+}
+
+func (ou *OAuthUsecase) GetDeviceAuthorization(userCode string) (entities.OAuthClient, entities.OAuthDeviceCode, error) {
+	deviceCode, err := ou.takePendingDeviceCode(userCode)
+	if err != nil {
+		return entities.OAuthClient{}, entities.OAuthDeviceCode{}, err
+	}
+
+	client, err := ou.ClientRepo.TakeByConditions(map[string]interface{}{
+		"client_id": deviceCode.ClientID,
+	})
+	if err != nil {
+		return entities.OAuthClient{}, entities.OAuthDeviceCode{}, err
+	}
+
+	return client, deviceCode, nil
+}
+
+func (ou *OAuthUsecase) DecideDeviceAuthorization(userID uint, userCode string, req dtos.DeviceDecisionRequest) error {
+	deviceCode, err := ou.takePendingDeviceCode(userCode)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"user_id":     userID,
+		"approved_at": time.Now(),
+	}
+	if req.Decision != "approve" {
+		data = map[string]interface{}{
+			"denied_at": time.Now(),
+		}
+	}
+
+	decided, err := ou.DeviceCodeRepo.DecideDeviceCode(deviceCode, data)
+	if err != nil {
+		return err
+	}
+	if !decided {
+		return errDeviceCodeNotPending
+	}
+
+	return nil
+}
+
+// errDeviceCodeNotPending is returned when the code was decided or expired
+var errDeviceCodeNotPending = errors.New("device code is no longer pending")
+
+func (ou *OAuthUsecase) takePendingDeviceCode(userCode string) (entities.OAuthDeviceCode, error) {
+	deviceCode, err := ou.DeviceCodeRepo.TakeByConditions(map[string]interface{}{
+		"user_code": normalizeUserCode(userCode),
+	})
+	if err != nil {
+		return entities.OAuthDeviceCode{}, err
+	}
+
+	if deviceCode.ApprovedAt != nil || deviceCode.DeniedAt != nil || time.Now().After(deviceCode.ExpiresAt) {
+		return entities.OAuthDeviceCode{}, errDeviceCodeNotPending
+	}
+
+	return deviceCode, nil
+}
+
+// exchangeDeviceCode is polled by the device until the user made a decision
+func (ou *OAuthUsecase) exchangeDeviceCode(client entities.OAuthClient, req dtos.TokenRequest, clientInfo dtos.ClientInfo) (dtos.TokenResponse, error) {
+	deviceCode, err := ou.DeviceCodeRepo.TakeByConditions(map[string]interface{}{
+		"device_code_hash": auth.HashOpaqueToken(req.DeviceCode),
+	})
+	if err != nil || deviceCode.ClientID != client.ClientID || deviceCode.UsedAt != nil {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant"}
+	}
+
+	if time.Now().After(deviceCode.ExpiresAt) {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "expired_token"}
+	}
+
+	if deviceCode.DeniedAt != nil {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "access_denied"}
+	}
+
+	if deviceCode.ApprovedAt == nil {
+		// a device polling faster than the interval has to wait 5 seconds longer from now on
+		if deviceCode.LastPolledAt != nil && time.Since(*deviceCode.LastPolledAt) < time.Duration(deviceCode.Interval)*time.Second {
+			err = ou.DeviceCodeRepo.UpdateDeviceCode(deviceCode, map[string]interface{}{
+				"poll_interval":  deviceCode.Interval + constants.DeviceCodePollInterval,
+				"last_polled_at": time.Now(),
+			})
+			if err != nil {
+				return dtos.TokenResponse{}, err
+			}
+			return dtos.TokenResponse{}, &dtos.OAuthError{Code: "slow_down"}
+		}
+
+		err = ou.DeviceCodeRepo.UpdateDeviceCode(deviceCode, map[string]interface{}{
+			"last_polled_at": time.Now(),
+		})
+		if err != nil {
+			return dtos.TokenResponse{}, err
+		}
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "authorization_pending"}
+	}
+
+	consumed, err := ou.DeviceCodeRepo.ConsumeDeviceCode(deviceCode)
+	if err != nil {
+		return dtos.TokenResponse{}, err
+	}
+	if !consumed || deviceCode.UserID == nil {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant"}
+	}
+
+	user, err := ou.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": *deviceCode.UserID,
+	})
+	if err != nil || !user.IsActive || user.IsSuspended || user.DeprovisionedAt != nil {
+		return dtos.TokenResponse{}, &dtos.OAuthError{Code: "invalid_grant"}
+	}
+
+	return ou.issueTokens(client, user, deviceCode.Scope, "", clientInfo)
+}
+
+func (ou *OAuthUsecase) Discovery() dtos.OpenIDConfiguration {
+	issuer := auth.Issuer()
+
+	return dtos.OpenIDConfiguration{
+		Issuer:                      issuer,
+		AuthorizationEndpoint:       issuer + "/oauth/authorize",
+		TokenEndpoint:               issuer + "/oauth/token",
+		UserinfoEndpoint:            issuer + "/oauth/userinfo",
+		JwksURI:                     issuer + "/.well-known/jwks.json",
+		EndSessionEndpoint:          issuer + "/oauth/logout",
+		DeviceAuthorizationEndpoint: issuer + "/oauth/device_authorization",
+		IntrospectionEndpoint:       issuer + "/oauth/introspect",
+		RevocationEndpoint:          issuer + "/oauth/revoke",
+		ScopesSupported: []string{
+			constants.ScopeOpenID, constants.ScopeProfile, constants.ScopeEmail,
+			constants.ScopeRead, constants.ScopeWrite, constants.ScopeAdmin,
+		},
+		ResponseTypesSupported: []string{"code"},
+		GrantTypesSupported: []string{
+			constants.GrantTypeAuthorizationCode, constants.GrantTypeRefreshToken,
+			constants.GrantTypeClientCredentials, constants.GrantTypeDeviceCode,
+		},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{constants.PKCEMethodS256},
+		ClaimsSupported:                   []string{"iss", "sub", "aud", "exp", "iat", "nonce", "sid", "name", "picture", "locale", "zoneinfo", "email", "email_verified"},
+	}
+}
+
+// UserInfo releases the claims allowed by scope, an empty scope is a first party token
+func (ou *OAuthUsecase) UserInfo(userID uint, scope string) (dtos.UserInfoResponse, error) {
+	user, err := ou.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil {
+		return dtos.UserInfoResponse{}, err
+	}
+
+	if scope == "" {
+		scope = constants.ScopeProfile + " " + constants.ScopeEmail
+	}
+
+	res := dtos.UserInfoResponse{
+		Sub: strconv.FormatUint(uint64(user.ID), 10),
+	}
+	if containsField(scope, constants.ScopeProfile) {
+		res.Name = user.Username
+		if user.DisplayName != "" {
+			res.Name = user.DisplayName
+		}
+		res.Picture, _ = utils.AvatarURLs(user)
+		res.Locale = user.Locale
+		res.Zoneinfo = user.Timezone
+	}
+	if containsField(scope, constants.ScopeEmail) {
+		emailVerified := user.IsActive
+		res.Email = user.Email
+		res.EmailVerified = &emailVerified
+	}
+
+	return res, nil
+}
+
+// EndSession implements RP-initiated logout. The engine keeps no browser
+// session, so the ID token hint is what tells which session to end.
+func (ou *OAuthUsecase) EndSession(req dtos.EndSessionRequest) (string, error) {
+	if req.IDTokenHint == "" {
+		return "", &dtos.OAuthError{Code: "invalid_request", Description: "id_token_hint is required"}
+	}
+
+	claims, err := auth.ParseRS256JWT(req.IDTokenHint)
+	if err != nil || claims["iss"] != auth.Issuer() {
+		return "", &dtos.OAuthError{Code: "invalid_request", Description: "id_token_hint is invalid"}
+	}
+
+	clientID, _ := claims["aud"].(string)
+	if req.ClientID != "" && req.ClientID != clientID {
+		return "", &dtos.OAuthError{Code: "invalid_request", Description: "client_id does not match id_token_hint"}
+	}
+
+	sid, _ := claims["sid"].(string)
+	sessionID, err := strconv.ParseUint(sid, 10, 64)
+	if err != nil {
+		return "", &dtos.OAuthError{Code: "invalid_request", Description: "id_token_hint has no session"}
+	}
+
+	err = ou.revokeSession(uint(sessionID))
+	if err != nil {
+		return "", err
+	}
+
+	if req.PostLogoutRedirectURI == "" {
+		return "", nil
+	}
+
+	client, err := ou.ClientRepo.TakeByConditions(map[string]interface{}{
+		"client_id": clientID,
+	})
+	if err != nil || !containsField(client.PostLogoutRedirectURIs, req.PostLogoutRedirectURI) {
+		return "", &dtos.OAuthError{Code: "invalid_request", Description: "post_logout_redirect_uri is not registered"}
+	}
+
+	return authorizeRedirect(req.PostLogoutRedirectURI, map[string]string{
+		"state": req.State,
+	})
+}
+
+// generateIDToken signs the OpenID Connect ID token with the published RS256 key
+func generateIDToken(client entities.OAuthClient, user entities.User, session entities.Session, scope string, nonce string) (string, error) {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss": auth.Issuer(),
+		"sub": strconv.FormatUint(uint64(user.ID), 10),
+		"aud": client.ClientID,
+		"azp": client.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(constants.IDTokenTTL).Unix(),
+		"sid": strconv.FormatUint(uint64(session.ID), 10),
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	if containsField(scope, constants.ScopeProfile) {
+		claims["name"] = user.Username
+	}
+	if containsField(scope, constants.ScopeEmail) {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.IsActive
+	}
+
+	return auth.GenerateRS256JWT(claims)
+}
+
+// verifyCodeChallenge implements the S256 method of RFC 7636
+func verifyCodeChallenge(challenge string, verifier string) bool {
+	if verifier == "" {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// narrowScope returns the requested scope if it is a subset of allowed,
+// an empty request means every allowed scope
+func narrowScope(allowed string, requested string) (string, bool) {
+	if requested == "" {
+		return allowed, true
+	}
+
+	for _, scope := range strings.Fields(requested) {
+		if !containsField(allowed, scope) {
+			return "", false
+		}
+	}
+
+	return strings.Join(strings.Fields(requested), " "), true
+}
+
+// containsField reports whether value is one of the space separated fields of list
+func containsField(list string, value string) bool {
+	for _, field := range strings.Fields(list) {
+		if field == value {
+			return true
+		}
+	}
+	return false
+}
+
+func authorizeRedirect(redirectURI string, params map[string]string) (string, error) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", errors.New("invalid redirect_uri")
+	}
+
+	query := u.Query()
+	for key, val := range params {
+		if val != "" {
+			query.Set(key, val)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// generateUserCode returns a random user code, without the dash
+func generateUserCode() (string, error) {
+	alphabet := constants.DeviceUserCodeAlphabet
+	code := make([]byte, constants.DeviceUserCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = alphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+// formatUserCode splits the user code in two halves for readability
+func formatUserCode(code string) string {
+	half := len(code) / 2
+	return code[:half] + "-" + code[half:]
+}
+
+// normalizeUserCode accepts user input in any case, with or without separators
+func normalizeUserCode(input string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(input) {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"errors"
+	"regexp"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+)
+
+// organizationSlugPattern slugs end up in SSO URLs, so they are kept URL safe
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// attributeNamePattern attribute names are the keys of User.Metadata
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type OrganizationUsecase struct {
+	OrgRepo       interfaces.OrganizationRepository
+	AttributeRepo interfaces.OrganizationAttributeRepository
+}
+
+func NewOrganizationUsecase(orgr interfaces.OrganizationRepository, oar interfaces.OrganizationAttributeRepository) interfaces.OrganizationUsecase {
+	return &OrganizationUsecase{
+		OrgRepo:       orgr,
+		AttributeRepo: oar,
+	}
+}
+
+func (ou *OrganizationUsecase) ListOrganizations() ([]entities.Organization, error) {
+	orgs, err := ou.OrgRepo.FindByConditions(map[string]interface{}{})
+
+	return orgs, err
+}
+
+func (ou *OrganizationUsecase) CreateOrganization(req dtos.CreateOrganizationRequest) (entities.Organization, error) {
+	if !organizationSlugPattern.MatchString(req.Slug) {
+		return entities.Organization{}, errors.New("slug must be lowercase letters, digits and dashes")
+	}
+
+	_, err := ou.OrgRepo.TakeByConditions(map[string]interface{}{
+		"slug": req.Slug,
+	})
+	if err == nil {
+		return entities.Organization{}, errors.New("slug already exists")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Organization{}, err
+	}
+
+	return ou.OrgRepo.CreateOrganization(entities.Organization{
+		Name: req.Name,
+		Slug: req.Slug,
+	})
+}
+
+func (ou *OrganizationUsecase) ListAttributes(orgID uint) ([]entities.OrganizationAttribute, error) {
+	_, err := ou.OrgRepo.TakeByConditions(map[string]interface{}{
+		"id": orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ou.AttributeRepo.FindByConditions(map[string]interface{}{
+		"organization_id": orgID,
+	})
+}
+
+// CreateAttribute declares a custom attribute. Values already stored in the
+// metadata of members are not checked again, only the next updates are.
+func (ou *OrganizationUsecase) CreateAttribute(orgID uint, req dtos.CreateOrganizationAttributeRequest) (entities.OrganizationAttribute, error) {
+	_, err := ou.OrgRepo.TakeByConditions(map[string]interface{}{
+		"id": orgID,
+	})
+	if err != nil {
+		return entities.OrganizationAttribute{}, err
+	}
+
+	if !attributeNamePattern.MatchString(req.Name) {
+		return entities.OrganizationAttribute{}, errors.New("name must start with a letter and hold lowercase letters, digits and underscores")
+	}
+	if req.Type != constants.AttributeTypeEnum && len(req.Options) > 0 {
+		return entities.OrganizationAttribute{}, errors.New("options are only allowed for enum attributes")
+	}
+	if req.Type != constants.AttributeTypeString && req.MaxLength > 0 {
+		return entities.OrganizationAttribute{}, errors.New("max_length is only allowed for string attributes")
+	}
+
+	_, err = ou.AttributeRepo.TakeByConditions(map[string]interface{}{
+		"organization_id": orgID,
+		"name":            req.Name,
+	})
+	if err == nil {
+		return entities.OrganizationAttribute{}, errors.New("attribute already exists")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.OrganizationAttribute{}, err
+	}
+
+	return ou.AttributeRepo.CreateAttribute(entities.OrganizationAttribute{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Type:           req.Type,
+		Required:       req.Required,
+		Options:        req.Options,
+		MaxLength:      req.MaxLength,
+	})
+}
+
+func (ou *OrganizationUsecase) DeleteAttribute(orgID uint, attrID uint) error {
+	attr, err := ou.AttributeRepo.TakeByConditions(map[string]interface{}{
+		"id":              attrID,
+		"organization_id": orgID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ou.AttributeRepo.DeleteAttribute(attr)
+}
@@ -0,0 +1,144 @@
+package usecases
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+)
+
+type PersonalAccessTokenUsecase struct {
+	UserRepo     interfaces.UserRepository
+	TokenRepo    interfaces.PersonalAccessTokenRepository
+	AuditUsecase interfaces.AuditUsecase
+}
+
+func NewPersonalAccessTokenUsecase(
+	ur interfaces.UserRepository,
+	tr interfaces.PersonalAccessTokenRepository,
+	auditUsecase interfaces.AuditUsecase,
+) interfaces.PersonalAccessTokenUsecase {
+	return &PersonalAccessTokenUsecase{
+		UserRepo:     ur,
+		TokenRepo:    tr,
+		AuditUsecase: auditUsecase,
+	}
+}
+
+func (pu *PersonalAccessTokenUsecase) ListTokens(userID uint) ([]entities.PersonalAccessToken, error) {
+	tokens, err := pu.TokenRepo.FindActiveByUserID(userID)
+
+	return tokens, err
+}
+
+// CreateToken returns the stored token and its plain value, which is only available here
+func (pu *PersonalAccessTokenUsecase) CreateToken(userID uint, req dtos.CreatePersonalAccessTokenRequest, client dtos.ClientInfo) (entities.PersonalAccessToken, string, error) {
+	token, plainToken, err := pu.createToken(userID, req)
+	recordAuditEvent(pu.AuditUsecase, constants.AuditActionTokenCreated, client, userID, err, map[string]interface{}{
+		"token_id": token.ID,
+		"name":     req.Name,
+		"scopes":   req.Scopes,
+	})
+
+	return token, plainToken, err
+}
+
+func (pu *PersonalAccessTokenUsecase) RevokeToken(userID uint, tokenID uint, client dtos.ClientInfo) error {
+	err := pu.revokeToken(userID, tokenID)
+	recordAuditEvent(pu.AuditUsecase, constants.AuditActionTokenRevoked, client, userID, err, map[string]interface{}{
+		"token_id": tokenID,
+	})
+
+	return err
+}
+
+func (pu *PersonalAccessTokenUsecase) AuthenticateToken(plainToken string) (entities.PersonalAccessToken, entities.User, error) {
+	token, err := pu.TokenRepo.TakeByConditions(map[string]interface{}{
+		"token_hash": auth.HashOpaqueToken(plainToken),
+	})
+	if err != nil || token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return entities.PersonalAccessToken{}, entities.User{}, errors.New("token is invalid")
+	}
+
+	user, err := pu.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": token.UserID,
+	})
+	if err != nil || !user.IsActive || user.IsSuspended || user.DeprovisionedAt != nil {
+		return entities.PersonalAccessToken{}, entities.User{}, errors.New("user is not active")
+	}
+
+	// the same lock as sign in, a forced reset also stops machine clients
+	if user.MustResetPassword {
+		return entities.PersonalAccessToken{}, entities.User{}, errors.New("password reset is required")
+	}
+
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > constants.SessionTouchInterval {
+		_ = pu.TokenRepo.UpdateToken(token, map[string]interface{}{
+			"last_used_at": time.Now(),
+		})
+	}
+
+	return token, user, nil
+}
+
+func (pu *PersonalAccessTokenUsecase) createToken(userID uint, req dtos.CreatePersonalAccessTokenRequest) (entities.PersonalAccessToken, string, error) {
+	user, err := pu.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+	if err != nil {
+		return entities.PersonalAccessToken{}, "", err
+	}
+
+	for _, scope := range req.Scopes {
+		if scope == constants.ScopeAdmin && user.Role != constants.RoleAdmin {
+			return entities.PersonalAccessToken{}, "", errors.New("admin scope requires the admin role")
+		}
+	}
+
+	expiresInDays := req.ExpiresInDays
+	if expiresInDays == 0 {
+		expiresInDays = constants.DefaultPersonalAccessTokenDays
+	}
+
+	plainToken, tokenHash, err := auth.GenerateOpaqueToken(constants.PersonalAccessTokenPrefix)
+	if err != nil {
+		return entities.PersonalAccessToken{}, "", err
+	}
+
+	token, err := pu.TokenRepo.CreateToken(entities.PersonalAccessToken{
+		UserID:      user.ID,
+		Name:        req.Name,
+		TokenPrefix: plainToken[:len(constants.PersonalAccessTokenPrefix)+6],
+		TokenHash:   tokenHash,
+		Scopes:      strings.Join(req.Scopes, " "),
+		ExpiresAt:   time.Now().AddDate(0, 0, expiresInDays),
+	})
+	if err != nil {
+		return entities.PersonalAccessToken{}, "", err
+	}
+
+	return token, plainToken, nil
+}
+
+func (pu *PersonalAccessTokenUsecase) revokeToken(userID uint, tokenID uint) error {
+	token, err := pu.TokenRepo.TakeByConditions(map[string]interface{}{
+		"id":      tokenID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if token.RevokedAt != nil {
+		return errors.New("token already revoked")
+	}
+
+	return pu.TokenRepo.UpdateToken(token, map[string]interface{}{
+		"revoked_at": time.Now(),
+	})
+}
@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/sms"
+)
+
+// ErrPhoneOTPRateLimited is returned when too many codes were sent to a user or a number
+var ErrPhoneOTPRateLimited = errors.New("too many codes requested, try again later")
+
+// ErrPhoneOTPInvalid is returned for a wrong, expired or used up code
+var ErrPhoneOTPInvalid = errors.New("code is invalid or expired")
+
+// sendPhoneOTP sends a new code to phone. The limits keep a single account
+// from flooding a number and a single number from being used by many accounts.
+func sendPhoneOTP(otpr interfaces.PhoneOTPRepository, sender sms.SMSSender, userID uint, phone string, purpose string) (entities.PhoneOTP, error) {
+	if !sms.Enabled(sender) {
+		return entities.PhoneOTP{}, sms.ErrDisabled
+	}
+
+	now := time.Now()
+
+	recent, err := otpr.CountSince(map[string]interface{}{"user_id": userID}, now.Add(-constants.PhoneOTPResendInterval))
+	if err != nil {
+		return entities.PhoneOTP{}, err
+	}
+	if recent > 0 {
+		return entities.PhoneOTP{}, ErrPhoneOTPRateLimited
+	}
+
+	for _, conditions := range []map[string]interface{}{{"user_id": userID}, {"phone": phone}} {
+		count, err := otpr.CountSince(conditions, now.Add(-time.Hour))
+		if err != nil {
+			return entities.PhoneOTP{}, err
+		}
+		if count >= constants.PhoneOTPMaxPerHour {
+			return entities.PhoneOTP{}, ErrPhoneOTPRateLimited
+		}
+	}
+
+	code, err := auth.GenerateNumericCode(constants.PhoneOTPDigits)
+	if err != nil {
+		return entities.PhoneOTP{}, err
+	}
+
+	otp, err := otpr.CreateOTP(entities.PhoneOTP{
+		UserID:    userID,
+		Phone:     phone,
+		Purpose:   purpose,
+		CodeHash:  auth.HashNumericCode(code, phoneOTPSubject(userID, phone)),
+		ExpiresAt: now.Add(constants.PhoneOTPTTL),
+	})
+	if err != nil {
+		return entities.PhoneOTP{}, err
+	}
+
+	body := fmt.Sprintf("%s is your verification code. It expires in %d minutes.", code, int(constants.PhoneOTPTTL/time.Minute))
+	err = sender.Send(phone, body)
+	if err != nil {
+		return entities.PhoneOTP{}, err
+	}
+
+	return otp, nil
+}
+
+// checkPhoneOTP consumes otp when code matches. Every try is counted before
+// the comparison, so parallel guesses can not exceed the attempt limit.
+func checkPhoneOTP(otpr interfaces.PhoneOTPRepository, otp entities.PhoneOTP, code string) error {
+	if otp.ConsumedAt != nil || time.Now().After(otp.ExpiresAt) {
+		return ErrPhoneOTPInvalid
+	}
+
+	counted, err := otpr.AddAttempt(otp, constants.PhoneOTPMaxAttempts)
+	if err != nil {
+		return err
+	}
+	if !counted {
+		return ErrPhoneOTPInvalid
+	}
+
+	hash := auth.HashNumericCode(code, phoneOTPSubject(otp.UserID, otp.Phone))
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(otp.CodeHash)) != 1 {
+		return ErrPhoneOTPInvalid
+	}
+
+	consumed, err := otpr.ConsumeOTP(otp)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrPhoneOTPInvalid
+	}
+
+	return nil
+}
+
+func phoneOTPSubject(userID uint, phone string) string {
+	return strconv.FormatUint(uint64(userID), 10) + ":" + phone
+}
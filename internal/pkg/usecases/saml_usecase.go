@@ -0,0 +1,417 @@
+package usecases
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/utils"
+)
+
+// ErrInvalidSAMLResponse is returned for responses that fail validation, the
+// reason is logged and not shown to the caller
+var ErrInvalidSAMLResponse = errors.New("invalid SAML response")
+
+type SAMLUsecase struct {
+	AuthUsecase   interfaces.AuthUsecase
+	UserRepo      interfaces.UserRepository
+	OrgRepo       interfaces.OrganizationRepository
+	ConnRepo      interfaces.SAMLConnectionRepository
+	RequestRepo   interfaces.SAMLRequestRepository
+	AssertionRepo interfaces.SAMLAssertionRepository
+	AuditUsecase  interfaces.AuditUsecase
+}
+
+func NewSAMLUsecase(
+	authUsecase interfaces.AuthUsecase,
+	ur interfaces.UserRepository,
+	orgr interfaces.OrganizationRepository,
+	scr interfaces.SAMLConnectionRepository,
+	srr interfaces.SAMLRequestRepository,
+	sar interfaces.SAMLAssertionRepository,
+	auditUsecase interfaces.AuditUsecase,
+) interfaces.SAMLUsecase {
+	return &SAMLUsecase{
+		AuthUsecase:   authUsecase,
+		UserRepo:      ur,
+		OrgRepo:       orgr,
+		ConnRepo:      scr,
+		RequestRepo:   srr,
+		AssertionRepo: sar,
+		AuditUsecase:  auditUsecase,
+	}
+}
+
+func (su *SAMLUsecase) GetConnection(orgID uint) (entities.SAMLConnection, error) {
+	conn, err := su.ConnRepo.TakeByConditions(map[string]interface{}{
+		"organization_id": orgID,
+	})
+
+	return conn, err
+}
+
+func (su *SAMLUsecase) ConfigureConnection(orgID uint, req dtos.SAMLConnectionRequest) (entities.SAMLConnection, error) {
+	_, err := su.OrgRepo.TakeByConditions(map[string]interface{}{
+		"id": orgID,
+	})
+	if err != nil {
+		return entities.SAMLConnection{}, err
+	}
+
+	idpMetadata, err := samlsp.ParseMetadata([]byte(req.IdPMetadataXML))
+	if err != nil {
+		return entities.SAMLConnection{}, errors.New("invalid identity provider metadata: " + err.Error())
+	}
+	if len(idpMetadata.IDPSSODescriptors) == 0 {
+		return entities.SAMLConnection{}, errors.New("identity provider metadata has no IDPSSODescriptor")
+	}
+
+	conn := entities.SAMLConnection{
+		OrganizationID:    orgID,
+		IdPMetadataXML:    req.IdPMetadataXML,
+		SSOBinding:        req.SSOBinding,
+		EmailAttribute:    req.EmailAttribute,
+		UsernameAttribute: req.UsernameAttribute,
+		JITProvisioning:   req.JITProvisioning,
+		DefaultRole:       req.DefaultRole,
+		IsEnabled:         true,
+	}
+	if conn.SSOBinding == "" {
+		conn.SSOBinding = constants.SAMLBindingRedirect
+	}
+	if conn.DefaultRole == "" {
+		conn.DefaultRole = constants.OrganizationRoleMember
+	}
+	if req.IsEnabled != nil {
+		conn.IsEnabled = *req.IsEnabled
+	}
+
+	return su.ConnRepo.SaveConnection(conn)
+}
+
+// Metadata returns the SP metadata XML to give to the identity provider
+func (su *SAMLUsecase) Metadata(slug string) ([]byte, error) {
+	_, _, sp, err := su.serviceProvider(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return xml.MarshalIndent(sp.Metadata(), "", "  ")
+}
+
+// Login starts an SP initiated sign-in. The AuthnRequest ID doubles as the
+// RelayState so the response can be matched to a request we sent.
+func (su *SAMLUsecase) Login(slug string) (dtos.SAMLLoginResponse, error) {
+	org, conn, sp, err := su.serviceProvider(slug)
+	if err != nil {
+		return dtos.SAMLLoginResponse{}, err
+	}
+
+	binding := saml.HTTPRedirectBinding
+	if conn.SSOBinding == constants.SAMLBindingPost {
+		binding = saml.HTTPPostBinding
+	}
+
+	idpURL := sp.GetSSOBindingLocation(binding)
+	if idpURL == "" {
+		return dtos.SAMLLoginResponse{}, errors.New("identity provider does not support the " + conn.SSOBinding + " binding")
+	}
+
+	authnRequest, err := sp.MakeAuthenticationRequest(idpURL, binding, saml.HTTPPostBinding)
+	if err != nil {
+		return dtos.SAMLLoginResponse{}, err
+	}
+
+	_, err = su.RequestRepo.CreateRequest(entities.SAMLRequest{
+		RequestID:      authnRequest.ID,
+		OrganizationID: org.ID,
+		ExpiresAt:      time.Now().Add(constants.SAMLRequestTTL),
+	})
+	if err != nil {
+		return dtos.SAMLLoginResponse{}, err
+	}
+
+	if binding == saml.HTTPPostBinding {
+		return dtos.SAMLLoginResponse{
+			PostForm: authnRequest.Post(authnRequest.ID),
+		}, nil
+	}
+
+	redirectURL, err := authnRequest.Redirect(authnRequest.ID, sp)
+	if err != nil {
+		return dtos.SAMLLoginResponse{}, err
+	}
+
+	return dtos.SAMLLoginResponse{
+		RedirectURL: redirectURL.String(),
+	}, nil
+}
+
+func (su *SAMLUsecase) ConsumeAssertion(slug string, req dtos.SAMLACSRequest, client dtos.ClientInfo) (entities.User, string, error) {
+	user, token, err := su.consumeAssertion(slug, req, client)
+	recordAuditEvent(su.AuditUsecase, constants.AuditActionSAMLSignIn, client, user.ID, err, map[string]interface{}{
+		"organization": slug,
+	})
+
+	return user, token, err
+}
+
+// consumeAssertion validates the response at the ACS. Signature, audience,
+// conditions, recipient and InResponseTo are checked by the SAML library,
+// replays are caught by remembering request and assertion IDs.
+func (su *SAMLUsecase) consumeAssertion(slug string, req dtos.SAMLACSRequest, client dtos.ClientInfo) (entities.User, string, error) {
+	org, conn, sp, err := su.serviceProvider(slug)
+	if err != nil {
+		return entities.User{}, "", err
+	}
+
+	// IdP initiated sign-in is not allowed, every response answers one of our requests
+	samlRequest, err := su.RequestRepo.TakeByConditions(map[string]interface{}{
+		"request_id":      req.RelayState,
+		"organization_id": org.ID,
+	})
+	if err != nil || samlRequest.UsedAt != nil || time.Now().After(samlRequest.ExpiresAt) {
+		return entities.User{}, "", errors.New("SAML response does not match a pending request")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(req.SAMLResponse)
+	if err != nil {
+		return entities.User{}, "", errors.New("SAMLResponse is not base64")
+	}
+
+	assertion, err := sp.ParseXMLResponse(decoded, []string{samlRequest.RequestID})
+	if err != nil {
+		// the private error tells which check failed, it is only logged
+		var invalidErr *saml.InvalidResponseError
+		if errors.As(err, &invalidErr) {
+			logrus.WithField("organization", slug).WithError(invalidErr.PrivateErr).Warn("invalid SAML response")
+			return entities.User{}, "", ErrInvalidSAMLResponse
+		}
+		return entities.User{}, "", err
+	}
+
+	consumed, err := su.RequestRepo.ConsumeRequest(samlRequest)
+	if err != nil {
+		return entities.User{}, "", err
+	}
+	if !consumed {
+		return entities.User{}, "", errors.New("SAML request was already answered")
+	}
+
+	expiresAt := time.Now().Add(constants.SAMLRequestTTL)
+	if assertion.Conditions != nil && !assertion.Conditions.NotOnOrAfter.IsZero() {
+		expiresAt = assertion.Conditions.NotOnOrAfter
+	}
+	recorded, err := su.AssertionRepo.RecordAssertion(entities.SAMLAssertion{
+		AssertionID:    assertion.ID,
+		OrganizationID: org.ID,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		return entities.User{}, "", err
+	}
+	if !recorded {
+		return entities.User{}, "", errors.New("SAML assertion was already used")
+	}
+
+	email, username := mapSAMLAttributes(conn, assertion)
+	if !strings.Contains(email, "@") {
+		return entities.User{}, "", errors.New("SAML assertion has no email address")
+	}
+
+	user, err := su.provisionUser(org, conn, email, username)
+	if err != nil {
+		return user, "", err
+	}
+
+	if user.IsSuspended {
+		return user, "", errors.New("user is suspended")
+	}
+	if user.DeprovisionedAt != nil {
+		return user, "", errors.New("user is deprovisioned")
+	}
+
+	_, token, err := su.AuthUsecase.IssueAccessToken(user, client, map[string]interface{}{
+		"org": org.Slug,
+	})
+	if err != nil {
+		return user, "", err
+	}
+
+	return user, token, nil
+}
+
+// provisionUser finds the user of the assertion, creating it and its
+// membership when just-in-time provisioning is on for the organization. An
+// existing account is only matched when it is already a member, an identity
+// provider must not be able to sign in to accounts of other organizations.
+func (su *SAMLUsecase) provisionUser(org entities.Organization, conn entities.SAMLConnection, email string, username string) (entities.User, error) {
+	user, err := su.UserRepo.TakeByConditions(map[string]interface{}{
+		"email": email,
+	})
+	if err == nil {
+		member, err := su.OrgRepo.TakeMember(map[string]interface{}{
+			"organization_id": org.ID,
+			"user_id":         user.ID,
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.User{}, errors.New("user is not a member of the organization")
+		}
+		if err != nil {
+			return entities.User{}, err
+		}
+		if member.DeprovisionedAt != nil {
+			return entities.User{}, errors.New("member is deprovisioned")
+		}
+
+		if conn.UsernameAttribute != "" && username != user.Username {
+			err = su.UserRepo.UpdateUser(user, map[string]interface{}{
+				"username": username,
+			})
+			if err != nil {
+				return user, err
+			}
+			user.Username = username
+		}
+
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.User{}, err
+	}
+
+	if !conn.JITProvisioning {
+		return entities.User{}, errors.New("user does not exist")
+	}
+
+	// the account can only be used through SSO until a password is reset
+	randomPassword, _, err := auth.GenerateOpaqueToken("")
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	hashPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	// DefaultRole is the role in the organization, never a platform role
+	user, err = su.UserRepo.CreateUser(entities.User{
+		Username: username,
+		Email:    email,
+		Password: hashPassword,
+		IsActive: true,
+		Role:     constants.RoleUser,
+	})
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	err = su.OrgRepo.AddMember(entities.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		Role:           conn.DefaultRole,
+	})
+
+	return user, err
+}
+
+func (su *SAMLUsecase) serviceProvider(slug string) (entities.Organization, entities.SAMLConnection, *saml.ServiceProvider, error) {
+	org, err := su.OrgRepo.TakeByConditions(map[string]interface{}{
+		"slug": slug,
+	})
+	if err != nil {
+		return entities.Organization{}, entities.SAMLConnection{}, nil, err
+	}
+
+	conn, err := su.GetConnection(org.ID)
+	if err != nil {
+		return org, entities.SAMLConnection{}, nil, err
+	}
+	if !conn.IsEnabled {
+		return org, conn, nil, errors.New("SAML is disabled for this organization")
+	}
+
+	idpMetadata, err := samlsp.ParseMetadata([]byte(conn.IdPMetadataXML))
+	if err != nil {
+		return org, conn, nil, err
+	}
+
+	base := auth.Issuer() + "/saml/" + url.PathEscape(org.Slug)
+	metadataURL, err := url.Parse(base + "/metadata")
+	if err != nil {
+		return org, conn, nil, err
+	}
+	acsURL, err := url.Parse(base + "/acs")
+	if err != nil {
+		return org, conn, nil, err
+	}
+
+	sp := &saml.ServiceProvider{
+		EntityID:          metadataURL.String(),
+		MetadataURL:       *metadataURL,
+		AcsURL:            *acsURL,
+		IDPMetadata:       idpMetadata,
+		AuthnNameIDFormat: saml.EmailAddressNameIDFormat,
+	}
+
+	key, cert, err := auth.LoadSAMLKeyPair()
+	if err != nil {
+		return org, conn, nil, err
+	}
+	if key != nil {
+		sp.Key = key
+		sp.Certificate = cert
+		sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+	}
+
+	return org, conn, sp, nil
+}
+
+// mapSAMLAttributes reads the email and username, the NameID is the email
+// when no attribute is configured and the username falls back to the email
+func mapSAMLAttributes(conn entities.SAMLConnection, assertion *saml.Assertion) (string, string) {
+	email := ""
+	if conn.EmailAttribute != "" {
+		email = samlAttribute(assertion, conn.EmailAttribute)
+	} else if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		email = assertion.Subject.NameID.Value
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	username := ""
+	if conn.UsernameAttribute != "" {
+		username = strings.TrimSpace(samlAttribute(assertion, conn.UsernameAttribute))
+	}
+	if username == "" {
+		username = email
+	}
+
+	return email, username
+}
+
+// samlAttribute returns the first value of the attribute with the given name or friendly name
+func samlAttribute(assertion *saml.Assertion, name string) string {
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			if (attr.Name == name || attr.FriendlyName == name) && len(attr.Values) > 0 {
+				return attr.Values[0].Value
+			}
+		}
+	}
+	return ""
+}
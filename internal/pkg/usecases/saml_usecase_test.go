@@ -0,0 +1,386 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+)
+
+const (
+	testIssuer   = "https://engine.test"
+	testOrgSlug  = "acme"
+	testSAMLUser = "jane@acme.test"
+)
+
+type fakeSAMLConnectionRepo struct {
+	interfaces.SAMLConnectionRepository
+	conns memTable[entities.SAMLConnection]
+}
+
+func (r *fakeSAMLConnectionRepo) TakeByConditions(conditions map[string]interface{}) (entities.SAMLConnection, error) {
+	return r.conns.take(conditions)
+}
+
+type fakeSAMLRequestRepo struct {
+	requests memTable[entities.SAMLRequest]
+}
+
+func (r *fakeSAMLRequestRepo) CreateRequest(req entities.SAMLRequest) (entities.SAMLRequest, error) {
+	return r.requests.insert(req), nil
+}
+
+func (r *fakeSAMLRequestRepo) TakeByConditions(conditions map[string]interface{}) (entities.SAMLRequest, error) {
+	return r.requests.take(conditions)
+}
+
+func (r *fakeSAMLRequestRepo) ConsumeRequest(req entities.SAMLRequest) (bool, error) {
+	updated := r.requests.update(map[string]interface{}{"id": req.ID, "used_at": nil}, map[string]interface{}{
+		"used_at": time.Now(),
+	})
+	return updated == 1, nil
+}
+
+type fakeSAMLAssertionRepo struct {
+	assertions memTable[entities.SAMLAssertion]
+}
+
+func (r *fakeSAMLAssertionRepo) RecordAssertion(assertion entities.SAMLAssertion) (bool, error) {
+	if _, err := r.assertions.take(map[string]interface{}{"assertion_id": assertion.AssertionID}); err == nil {
+		return false, nil
+	}
+	r.assertions.insert(assertion)
+	return true, nil
+}
+
+// testIdP signs responses like a real identity provider would
+type testIdP struct {
+	idp *saml.IdentityProvider
+}
+
+func newTestIdP(t *testing.T, entityID string) testIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: entityID},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadataURL, _ := url.Parse(entityID + "/metadata")
+	ssoURL, _ := url.Parse(entityID + "/sso")
+
+	return testIdP{idp: &saml.IdentityProvider{
+		Key:             key,
+		Certificate:     cert,
+		MetadataURL:     *metadataURL,
+		SSOURL:          *ssoURL,
+		SignatureMethod: dsig.RSASHA256SignatureMethod,
+	}}
+}
+
+func (ti testIdP) metadataXML(t *testing.T) string {
+	t.Helper()
+
+	data, err := xml.Marshal(ti.idp.Metadata())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// samlAssertionOptions changes the otherwise valid assertion of a test
+type samlAssertionOptions struct {
+	assertionID string
+	audience    string
+	issuedAt    time.Time
+	email       string
+}
+
+// response returns the signed, base64 encoded response to requestID
+func (ti testIdP) response(t *testing.T, requestID string, opts samlAssertionOptions) string {
+	t.Helper()
+
+	acsURL := testIssuer + "/saml/" + testOrgSlug + "/acs"
+	if opts.assertionID == "" {
+		opts.assertionID = "id-" + requestID
+	}
+	if opts.audience == "" {
+		opts.audience = testIssuer + "/saml/" + testOrgSlug + "/metadata"
+	}
+	if opts.issuedAt.IsZero() {
+		opts.issuedAt = saml.TimeNow()
+	}
+	if opts.email == "" {
+		opts.email = testSAMLUser
+	}
+
+	notOnOrAfter := opts.issuedAt.Add(5 * time.Minute)
+	req := &saml.IdpAuthnRequest{
+		IDP:         ti.idp,
+		Request:     saml.AuthnRequest{ID: requestID},
+		ACSEndpoint: &saml.IndexedEndpoint{Location: acsURL},
+		// no encryption key, the assertion is only signed
+		SPSSODescriptor: &saml.SPSSODescriptor{},
+		Now:             opts.issuedAt,
+		Assertion: &saml.Assertion{
+			ID:           opts.assertionID,
+			IssueInstant: opts.issuedAt,
+			Version:      "2.0",
+			Issuer: saml.Issuer{
+				Format: "urn:oasis:names:tc:SAML:2.0:nameid-format:entity",
+				Value:  ti.idp.MetadataURL.String(),
+			},
+			Subject: &saml.Subject{
+				NameID: &saml.NameID{
+					Format: string(saml.EmailAddressNameIDFormat),
+					Value:  opts.email,
+				},
+				SubjectConfirmations: []saml.SubjectConfirmation{{
+					Method: "urn:oasis:names:tc:SAML:2.0:cm:bearer",
+					SubjectConfirmationData: &saml.SubjectConfirmationData{
+						InResponseTo: requestID,
+						NotOnOrAfter: notOnOrAfter,
+						Recipient:    acsURL,
+					},
+				}},
+			},
+			Conditions: &saml.Conditions{
+				NotBefore:    opts.issuedAt.Add(-time.Minute),
+				NotOnOrAfter: notOnOrAfter,
+				AudienceRestrictions: []saml.AudienceRestriction{{
+					Audience: saml.Audience{Value: opts.audience},
+				}},
+			},
+			AuthnStatements: []saml.AuthnStatement{{
+				AuthnInstant: opts.issuedAt,
+			}},
+		},
+	}
+
+	err := req.MakeResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := etree.NewDocument()
+	doc.SetRoot(req.ResponseEl)
+	data, err := doc.WriteToBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+type samlFixture struct {
+	usecase *SAMLUsecase
+	users   *fakeUserRepo
+	orgs    *fakeOrganizationRepo
+	idp     testIdP
+	org     entities.Organization
+}
+
+func newSAMLFixture(t *testing.T, jit bool) samlFixture {
+	t.Helper()
+	t.Setenv("OIDC_ISSUER", testIssuer)
+
+	idp := newTestIdP(t, "https://idp.acme.test")
+	users := &fakeUserRepo{}
+	orgs := &fakeOrganizationRepo{}
+	org := orgs.orgs.insert(entities.Organization{Name: "Acme", Slug: testOrgSlug})
+
+	conns := &fakeSAMLConnectionRepo{}
+	conns.conns.insert(entities.SAMLConnection{
+		OrganizationID:  org.ID,
+		IdPMetadataXML:  idp.metadataXML(t),
+		SSOBinding:      constants.SAMLBindingRedirect,
+		JITProvisioning: jit,
+		DefaultRole:     constants.OrganizationRoleMember,
+		IsEnabled:       true,
+	})
+
+	return samlFixture{
+		usecase: &SAMLUsecase{
+			AuthUsecase:   fakeAuthUsecase{},
+			UserRepo:      users,
+			OrgRepo:       orgs,
+			ConnRepo:      conns,
+			RequestRepo:   &fakeSAMLRequestRepo{},
+			AssertionRepo: &fakeSAMLAssertionRepo{},
+			AuditUsecase:  fakeAuditUsecase{},
+		},
+		users: users,
+		orgs:  orgs,
+		idp:   idp,
+		org:   org,
+	}
+}
+
+// login starts a sign-in and returns the AuthnRequest ID, which is also the RelayState
+func (f samlFixture) login(t *testing.T) string {
+	t.Helper()
+
+	res, err := f.usecase.Login(testOrgSlug)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redirectURL, err := url.Parse(res.RedirectURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return redirectURL.Query().Get("RelayState")
+}
+
+func (f samlFixture) consume(requestID string, samlResponse string) (entities.User, string, error) {
+	return f.usecase.ConsumeAssertion(testOrgSlug, dtos.SAMLACSRequest{
+		SAMLResponse: samlResponse,
+		RelayState:   requestID,
+	}, dtos.ClientInfo{})
+}
+
+func TestConsumeAssertionValid(t *testing.T) {
+	f := newSAMLFixture(t, true)
+	requestID := f.login(t)
+
+	user, token, err := f.consume(requestID, f.idp.response(t, requestID, samlAssertionOptions{}))
+	if err != nil {
+		t.Fatalf("ConsumeAssertion() error = %v", err)
+	}
+	if user.Email != testSAMLUser || token == "" {
+		t.Fatalf("ConsumeAssertion() = %q, %q", user.Email, token)
+	}
+	if user.Role != constants.RoleUser {
+		t.Errorf("provisioned user role = %q, want %q", user.Role, constants.RoleUser)
+	}
+
+	member, err := f.orgs.TakeMember(map[string]interface{}{"organization_id": f.org.ID, "user_id": user.ID})
+	if err != nil {
+		t.Fatalf("provisioned user is not a member: %v", err)
+	}
+	if member.Role != constants.OrganizationRoleMember {
+		t.Errorf("membership role = %q, want %q", member.Role, constants.OrganizationRoleMember)
+	}
+}
+
+func TestConsumeAssertionRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		response func(t *testing.T, f samlFixture, requestID string) string
+	}{
+		{
+			name: "bad signature",
+			response: func(t *testing.T, f samlFixture, requestID string) string {
+				// same entity ID, but a key the connection does not trust
+				return newTestIdP(t, "https://idp.acme.test").response(t, requestID, samlAssertionOptions{})
+			},
+		},
+		{
+			name: "wrong audience",
+			response: func(t *testing.T, f samlFixture, requestID string) string {
+				return f.idp.response(t, requestID, samlAssertionOptions{audience: testIssuer + "/saml/other/metadata"})
+			},
+		},
+		{
+			name: "expired assertion",
+			response: func(t *testing.T, f samlFixture, requestID string) string {
+				return f.idp.response(t, requestID, samlAssertionOptions{issuedAt: saml.TimeNow().Add(-time.Hour)})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSAMLFixture(t, true)
+			requestID := f.login(t)
+
+			_, token, err := f.consume(requestID, tt.response(t, f, requestID))
+			if !errors.Is(err, ErrInvalidSAMLResponse) {
+				t.Fatalf("ConsumeAssertion() error = %v, want %v", err, ErrInvalidSAMLResponse)
+			}
+			if err.Error() != ErrInvalidSAMLResponse.Error() {
+				t.Errorf("error %q shows validation details", err)
+			}
+			if token != "" || len(f.users.users.rows) != 0 {
+				t.Errorf("rejected assertion signed in or provisioned a user")
+			}
+		})
+	}
+}
+
+func TestConsumeAssertionReplay(t *testing.T) {
+	f := newSAMLFixture(t, true)
+	requestID := f.login(t)
+	samlResponse := f.idp.response(t, requestID, samlAssertionOptions{assertionID: "id-replayed"})
+
+	_, _, err := f.consume(requestID, samlResponse)
+	if err != nil {
+		t.Fatalf("first ConsumeAssertion() error = %v", err)
+	}
+
+	t.Run("same response", func(t *testing.T) {
+		_, token, err := f.consume(requestID, samlResponse)
+		if err == nil || token != "" {
+			t.Fatalf("replayed response was accepted")
+		}
+	})
+
+	t.Run("same assertion for a new request", func(t *testing.T) {
+		nextRequestID := f.login(t)
+		_, token, err := f.consume(nextRequestID, f.idp.response(t, nextRequestID, samlAssertionOptions{assertionID: "id-replayed"}))
+		if err == nil || token != "" {
+			t.Fatalf("replayed assertion was accepted")
+		}
+	})
+}
+
+func TestConsumeAssertionOtherOrganizationUser(t *testing.T) {
+	f := newSAMLFixture(t, true)
+	admin := f.users.users.insert(entities.User{
+		Email:    testSAMLUser,
+		Username: "jane",
+		IsActive: true,
+		Role:     constants.RoleAdmin,
+	})
+
+	requestID := f.login(t)
+	_, token, err := f.consume(requestID, f.idp.response(t, requestID, samlAssertionOptions{}))
+	if err == nil || token != "" {
+		t.Fatalf("assertion signed in to an account outside the organization")
+	}
+
+	_, err = f.orgs.TakeMember(map[string]interface{}{"organization_id": f.org.ID, "user_id": admin.ID})
+	if err == nil {
+		t.Errorf("account outside the organization was added as a member")
+	}
+}
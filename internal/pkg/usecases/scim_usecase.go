@@ -0,0 +1,1035 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/utils"
+)
+
+// scimFilterPattern only the `attribute eq "value"` form is supported, it is
+// what directories send to look up a resource before creating it
+var scimFilterPattern = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9.]*)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// scimMemberFilterPattern matches the members[value eq "id"] path of a group PATCH
+var scimMemberFilterPattern = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]*)"\s*\]$`)
+
+type SCIMUsecase struct {
+	UserRepo      interfaces.UserRepository
+	SessionRepo   interfaces.SessionRepository
+	TokenRepo     interfaces.PersonalAccessTokenRepository
+	OrgRepo       interfaces.OrganizationRepository
+	GroupRepo     interfaces.OrganizationGroupRepository
+	SCIMTokenRepo interfaces.SCIMTokenRepository
+}
+
+func NewSCIMUsecase(
+	ur interfaces.UserRepository,
+	sr interfaces.SessionRepository,
+	tr interfaces.PersonalAccessTokenRepository,
+	orgr interfaces.OrganizationRepository,
+	gr interfaces.OrganizationGroupRepository,
+	str interfaces.SCIMTokenRepository,
+) interfaces.SCIMUsecase {
+	return &SCIMUsecase{
+		UserRepo:      ur,
+		SessionRepo:   sr,
+		TokenRepo:     tr,
+		OrgRepo:       orgr,
+		GroupRepo:     gr,
+		SCIMTokenRepo: str,
+	}
+}
+
+// scimUserChanges are the User attributes a directory can set, nil means unchanged
+type scimUserChanges struct {
+	Email      *string
+	Username   *string
+	ExternalID *string
+	Active     *bool
+}
+
+func (su *SCIMUsecase) ListTokens(orgID uint) ([]entities.SCIMToken, error) {
+	tokens, err := su.SCIMTokenRepo.FindActiveByOrganizationID(orgID)
+
+	return tokens, err
+}
+
+func (su *SCIMUsecase) CreateToken(orgID uint, req dtos.CreateSCIMTokenRequest) (entities.SCIMToken, string, error) {
+	_, err := su.OrgRepo.TakeByConditions(map[string]interface{}{
+		"id": orgID,
+	})
+	if err != nil {
+		return entities.SCIMToken{}, "", err
+	}
+
+	plainToken, tokenHash, err := auth.GenerateOpaqueToken(constants.SCIMTokenPrefix)
+	if err != nil {
+		return entities.SCIMToken{}, "", err
+	}
+
+	token, err := su.SCIMTokenRepo.CreateToken(entities.SCIMToken{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		TokenPrefix:    plainToken[:len(constants.SCIMTokenPrefix)+6],
+		TokenHash:      tokenHash,
+	})
+	if err != nil {
+		return entities.SCIMToken{}, "", err
+	}
+
+	return token, plainToken, nil
+}
+
+func (su *SCIMUsecase) RevokeToken(orgID uint, tokenID uint) error {
+	token, err := su.SCIMTokenRepo.TakeByConditions(map[string]interface{}{
+		"id":              tokenID,
+		"organization_id": orgID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if token.RevokedAt != nil {
+		return errors.New("token already revoked")
+	}
+
+	return su.SCIMTokenRepo.UpdateToken(token, map[string]interface{}{
+		"revoked_at": time.Now(),
+	})
+}
+
+func (su *SCIMUsecase) ListUsers(orgID uint, req dtos.SCIMListRequest) (dtos.SCIMListResponse, error) {
+	attr, value, err := parseSCIMFilter(req.Filter)
+	if err != nil {
+		return dtos.SCIMListResponse{}, err
+	}
+
+	startIndex, offset, limit := scimPage(req)
+	conditions := map[string]interface{}{
+		"organization_id": orgID,
+	}
+
+	switch strings.ToLower(attr) {
+	case "":
+	case "username", "emails", "emails.value":
+		user, err := su.UserRepo.TakeByConditions(map[string]interface{}{
+			"email": strings.ToLower(value),
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return scimListResponse(0, startIndex, []dtos.SCIMUser{}), nil
+		}
+		if err != nil {
+			return dtos.SCIMListResponse{}, err
+		}
+		conditions["user_id"] = user.ID
+	case "externalid":
+		conditions["external_id"] = value
+	default:
+		return dtos.SCIMListResponse{}, scimError(http.StatusBadRequest, "invalidFilter", "filtering on "+attr+" is not supported")
+	}
+
+	members, total, err := su.OrgRepo.PaginateMembers(conditions, offset, scimQueryLimit(limit))
+	if err != nil {
+		return dtos.SCIMListResponse{}, err
+	}
+	if limit == 0 {
+		members = nil
+	}
+
+	users, err := su.findUsers(memberUserIDs(members))
+	if err != nil {
+		return dtos.SCIMListResponse{}, err
+	}
+
+	resources := make([]dtos.SCIMUser, 0, len(members))
+	for _, member := range members {
+		if user, ok := users[member.UserID]; ok {
+			resources = append(resources, toSCIMUser(member, user))
+		}
+	}
+
+	return scimListResponse(total, startIndex, resources), nil
+}
+
+func (su *SCIMUsecase) GetUser(orgID uint, id string) (dtos.SCIMUser, error) {
+	member, user, err := su.takeMember(orgID, id)
+	if err != nil {
+		return dtos.SCIMUser{}, err
+	}
+
+	return toSCIMUser(member, user), nil
+}
+
+// CreateUser provisions a user. An existing account with the same email is
+// only linked when it already is a member the directory did not claim yet, or
+// when this directory created it, the account of another tenant is a conflict.
+func (su *SCIMUsecase) CreateUser(orgID uint, req dtos.SCIMUser) (dtos.SCIMUser, error) {
+	changes := scimUserChangesFromResource(req)
+	if changes.Email == nil || !strings.Contains(*changes.Email, "@") {
+		return dtos.SCIMUser{}, scimError(http.StatusBadRequest, "invalidValue", "userName or a primary email must be an email address")
+	}
+	if changes.Active == nil {
+		active := true
+		changes.Active = &active
+	}
+
+	user, err := su.UserRepo.TakeByConditions(map[string]interface{}{
+		"email": *changes.Email,
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dtos.SCIMUser{}, err
+	}
+
+	if err == nil {
+		member, err := su.OrgRepo.TakeMember(map[string]interface{}{
+			"organization_id": orgID,
+			"user_id":         user.ID,
+		})
+		switch {
+		case err == nil && (member.ExternalID != "" || scimOwnsUser(orgID, user)):
+			// the directory already provisioned this member
+			return dtos.SCIMUser{}, scimError(http.StatusConflict, "uniqueness", "user already exists")
+		case errors.Is(err, gorm.ErrRecordNotFound) && !scimOwnsUser(orgID, user):
+			// the account signed up by itself or belongs to another tenant
+			return dtos.SCIMUser{}, scimError(http.StatusConflict, "uniqueness", "user already exists")
+		case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+			return dtos.SCIMUser{}, err
+		}
+	} else {
+		// the account can only be used through SSO until a password is reset
+		randomPassword, _, err := auth.GenerateOpaqueToken("")
+		if err != nil {
+			return dtos.SCIMUser{}, err
+		}
+
+		hashPassword, err := utils.HashPassword(randomPassword)
+		if err != nil {
+			return dtos.SCIMUser{}, err
+		}
+
+		user, err = su.UserRepo.CreateUser(entities.User{
+			Username: *changes.Username,
+			Email:    *changes.Email,
+			Password: hashPassword,
+			IsActive: true,
+			Role:     constants.RoleUser,
+
+			SCIMOrganizationID: &orgID,
+		})
+		if err != nil {
+			return dtos.SCIMUser{}, err
+		}
+	}
+
+	err = su.OrgRepo.AddMember(entities.OrganizationMember{
+		OrganizationID: orgID,
+		UserID:         user.ID,
+		Role:           constants.OrganizationRoleMember,
+	})
+	if err != nil {
+		return dtos.SCIMUser{}, err
+	}
+
+	id := strconv.FormatUint(uint64(user.ID), 10)
+	member, user, err := su.takeMember(orgID, id)
+	if err != nil {
+		return dtos.SCIMUser{}, err
+	}
+
+	err = su.applyUserChanges(member, user, changes)
+	if err != nil {
+		return dtos.SCIMUser{}, err
+	}
+
+	return su.GetUser(orgID, id)
+}
+
+func (su *SCIMUsecase) ReplaceUser(orgID uint, id string, req dtos.SCIMUser) (dtos.SCIMUser, error) {
+	member, user, err := su.takeMember(orgID, id)
+	if err != nil {
+		return dtos.SCIMUser{}, err
+	}
+
+	changes := scimUserChangesFromResource(req)
+	if changes.Active == nil {
+		active := true
+		changes.Active = &active
+	}
+
+	err = su.applyUserChanges(member, user, changes)
+	if err != nil {
+		return dtos.SCIMUser{}, err
+	}
+
+	return su.GetUser(orgID, id)
+}
+
+func (su *SCIMUsecase) PatchUser(orgID uint, id string, req dtos.SCIMPatchRequest) (dtos.SCIMUser, error) {
+	member, user, err := su.takeMember(orgID, id)
+	if err != nil {
+		return dtos.SCIMUser{}, err
+	}
+
+	changes := scimUserChanges{}
+	for _, op := range req.Operations {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path == "" {
+				values := map[string]json.RawMessage{}
+				if json.Unmarshal(op.Value, &values) != nil {
+					return dtos.SCIMUser{}, scimError(http.StatusBadRequest, "invalidValue", "value must be an object when path is empty")
+				}
+				for attr, value := range values {
+					err = setSCIMUserAttribute(&changes, attr, value)
+					if err != nil {
+						return dtos.SCIMUser{}, err
+					}
+				}
+				continue
+			}
+
+			err = setSCIMUserAttribute(&changes, op.Path, op.Value)
+			if err != nil {
+				return dtos.SCIMUser{}, err
+			}
+		case "remove":
+			if !strings.EqualFold(op.Path, "externalId") {
+				return dtos.SCIMUser{}, scimError(http.StatusBadRequest, "mutability", op.Path+" can not be removed")
+			}
+			empty := ""
+			changes.ExternalID = &empty
+		default:
+			return dtos.SCIMUser{}, scimError(http.StatusBadRequest, "invalidSyntax", "unknown op "+op.Op)
+		}
+	}
+
+	err = su.applyUserChanges(member, user, changes)
+	if err != nil {
+		return dtos.SCIMUser{}, err
+	}
+
+	return su.GetUser(orgID, id)
+}
+
+// DeleteUser removes the user from the organization. An account this
+// directory created is deprovisioned and kept for the audit trail.
+func (su *SCIMUsecase) DeleteUser(orgID uint, id string) error {
+	member, user, err := su.takeMember(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if scimOwnsUser(orgID, user) {
+		err = su.deprovisionUser(user)
+		if err != nil {
+			return err
+		}
+	}
+
+	return su.OrgRepo.RemoveMember(member)
+}
+
+func (su *SCIMUsecase) ListGroups(orgID uint, req dtos.SCIMListRequest) (dtos.SCIMListResponse, error) {
+	attr, value, err := parseSCIMFilter(req.Filter)
+	if err != nil {
+		return dtos.SCIMListResponse{}, err
+	}
+
+	startIndex, offset, limit := scimPage(req)
+	conditions := map[string]interface{}{
+		"organization_id": orgID,
+	}
+
+	switch strings.ToLower(attr) {
+	case "":
+	case "displayname":
+		conditions["display_name"] = value
+	case "externalid":
+		conditions["external_id"] = value
+	default:
+		return dtos.SCIMListResponse{}, scimError(http.StatusBadRequest, "invalidFilter", "filtering on "+attr+" is not supported")
+	}
+
+	groups, total, err := su.GroupRepo.PaginateByConditions(conditions, offset, scimQueryLimit(limit))
+	if err != nil {
+		return dtos.SCIMListResponse{}, err
+	}
+	if limit == 0 {
+		groups = nil
+	}
+
+	resources := make([]dtos.SCIMGroup, 0, len(groups))
+	for _, group := range groups {
+		resource, err := su.toSCIMGroup(group)
+		if err != nil {
+			return dtos.SCIMListResponse{}, err
+		}
+		resources = append(resources, resource)
+	}
+
+	return scimListResponse(total, startIndex, resources), nil
+}
+
+func (su *SCIMUsecase) GetGroup(orgID uint, id string) (dtos.SCIMGroup, error) {
+	group, err := su.takeGroup(orgID, id)
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	return su.toSCIMGroup(group)
+}
+
+func (su *SCIMUsecase) CreateGroup(orgID uint, req dtos.SCIMGroup) (dtos.SCIMGroup, error) {
+	if req.DisplayName == "" {
+		return dtos.SCIMGroup{}, scimError(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+
+	userIDs, err := su.resolveMemberIDs(orgID, req.Members)
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	group, err := su.GroupRepo.CreateGroup(entities.OrganizationGroup{
+		OrganizationID: orgID,
+		DisplayName:    req.DisplayName,
+		ExternalID:     req.ExternalID,
+	})
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	err = su.GroupRepo.AddMembers(group.ID, userIDs)
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	return su.toSCIMGroup(group)
+}
+
+func (su *SCIMUsecase) ReplaceGroup(orgID uint, id string, req dtos.SCIMGroup) (dtos.SCIMGroup, error) {
+	group, err := su.takeGroup(orgID, id)
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	if req.DisplayName == "" {
+		return dtos.SCIMGroup{}, scimError(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+
+	userIDs, err := su.resolveMemberIDs(orgID, req.Members)
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	err = su.GroupRepo.UpdateGroup(group, map[string]interface{}{
+		"display_name": req.DisplayName,
+		"external_id":  req.ExternalID,
+	})
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	err = su.GroupRepo.ReplaceMembers(group.ID, userIDs)
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	return su.GetGroup(orgID, id)
+}
+
+func (su *SCIMUsecase) PatchGroup(orgID uint, id string, req dtos.SCIMPatchRequest) (dtos.SCIMGroup, error) {
+	group, err := su.takeGroup(orgID, id)
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	for _, op := range req.Operations {
+		err = su.applyGroupOperation(orgID, group, op)
+		if err != nil {
+			return dtos.SCIMGroup{}, err
+		}
+	}
+
+	return su.GetGroup(orgID, id)
+}
+
+func (su *SCIMUsecase) DeleteGroup(orgID uint, id string) error {
+	group, err := su.takeGroup(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	return su.GroupRepo.DeleteGroup(group)
+}
+
+func (su *SCIMUsecase) applyGroupOperation(orgID uint, group entities.OrganizationGroup, op dtos.SCIMPatchOperation) error {
+	opName := strings.ToLower(op.Op)
+	path := strings.TrimSpace(op.Path)
+
+	if opName != "add" && opName != "replace" && opName != "remove" {
+		return scimError(http.StatusBadRequest, "invalidSyntax", "unknown op "+op.Op)
+	}
+
+	if matches := scimMemberFilterPattern.FindStringSubmatch(path); matches != nil {
+		if opName != "remove" {
+			return scimError(http.StatusBadRequest, "invalidPath", "only remove is supported on a member filter")
+		}
+		userIDs, err := parseMemberIDs([]dtos.SCIMMemberRef{{Value: matches[1]}})
+		if err != nil {
+			return err
+		}
+		return su.GroupRepo.RemoveMembers(group.ID, userIDs)
+	}
+
+	if path == "" {
+		if opName == "remove" {
+			return scimError(http.StatusBadRequest, "noTarget", "remove needs a path")
+		}
+
+		values := map[string]json.RawMessage{}
+		if json.Unmarshal(op.Value, &values) != nil {
+			return scimError(http.StatusBadRequest, "invalidValue", "value must be an object when path is empty")
+		}
+		for attr, value := range values {
+			err := su.applyGroupOperation(orgID, group, dtos.SCIMPatchOperation{Op: op.Op, Path: attr, Value: value})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch strings.ToLower(path) {
+	case "displayname", "externalid":
+		value := ""
+		if opName != "remove" && json.Unmarshal(op.Value, &value) != nil {
+			return scimError(http.StatusBadRequest, "invalidValue", path+" must be a string")
+		}
+		if strings.EqualFold(path, "displayName") && value == "" {
+			return scimError(http.StatusBadRequest, "invalidValue", "displayName is required")
+		}
+
+		column := "external_id"
+		if strings.EqualFold(path, "displayName") {
+			column = "display_name"
+		}
+		return su.GroupRepo.UpdateGroup(group, map[string]interface{}{
+			column: value,
+		})
+	case "members":
+		refs := []dtos.SCIMMemberRef{}
+		if len(op.Value) > 0 && json.Unmarshal(op.Value, &refs) != nil {
+			return scimError(http.StatusBadRequest, "invalidValue", "members must be a list")
+		}
+
+		switch opName {
+		case "add", "replace":
+			userIDs, err := su.resolveMemberIDs(orgID, refs)
+			if err != nil {
+				return err
+			}
+			if opName == "add" {
+				return su.GroupRepo.AddMembers(group.ID, userIDs)
+			}
+			return su.GroupRepo.ReplaceMembers(group.ID, userIDs)
+		case "remove":
+			// without a value every member is removed
+			if len(refs) == 0 {
+				return su.GroupRepo.ReplaceMembers(group.ID, nil)
+			}
+			userIDs, err := parseMemberIDs(refs)
+			if err != nil {
+				return err
+			}
+			return su.GroupRepo.RemoveMembers(group.ID, userIDs)
+		}
+	}
+
+	return scimError(http.StatusBadRequest, "invalidPath", "unsupported path "+path)
+}
+
+// applyUserChanges only changes the account itself when this directory
+// created it, otherwise the account is shared with the rest of the platform
+// and only the membership is changed
+func (su *SCIMUsecase) applyUserChanges(member entities.OrganizationMember, user entities.User, changes scimUserChanges) error {
+	data := map[string]interface{}{}
+	owned := scimOwnsUser(member.OrganizationID, user)
+
+	if changes.Email != nil && *changes.Email != user.Email {
+		if !owned {
+			return scimError(http.StatusBadRequest, "mutability", "userName of an account the directory did not create can not be changed")
+		}
+		if !strings.Contains(*changes.Email, "@") {
+			return scimError(http.StatusBadRequest, "invalidValue", "userName must be an email address")
+		}
+
+		_, err := su.UserRepo.TakeByConditions(map[string]interface{}{
+			"email": *changes.Email,
+		})
+		if err == nil {
+			return scimError(http.StatusConflict, "uniqueness", "userName is already taken")
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		data["email"] = *changes.Email
+	}
+	if owned && changes.Username != nil && *changes.Username != "" && *changes.Username != user.Username {
+		data["username"] = *changes.Username
+	}
+
+	if len(data) > 0 {
+		err := su.UserRepo.UpdateUser(user, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if changes.Active != nil && *changes.Active != scimUserActive(member, user) {
+		err := su.setMemberActive(member, user, *changes.Active)
+		if err != nil {
+			return err
+		}
+	}
+
+	if changes.ExternalID != nil && *changes.ExternalID != member.ExternalID {
+		return su.OrgRepo.UpdateMember(member, map[string]interface{}{
+			"external_id": *changes.ExternalID,
+		})
+	}
+
+	return nil
+}
+
+// setMemberActive blocks or allows the SSO sign in to the organization, the
+// account is deprovisioned or restored with it when this directory created it
+func (su *SCIMUsecase) setMemberActive(member entities.OrganizationMember, user entities.User, active bool) error {
+	var deprovisionedAt interface{}
+	if !active {
+		deprovisionedAt = time.Now()
+	}
+
+	err := su.OrgRepo.UpdateMember(member, map[string]interface{}{
+		"deprovisioned_at": deprovisionedAt,
+	})
+	if err != nil || !scimOwnsUser(member.OrganizationID, user) {
+		return err
+	}
+
+	if !active {
+		return su.deprovisionUser(user)
+	}
+	return su.UserRepo.UpdateUser(user, map[string]interface{}{
+		"deprovisioned_at": nil,
+	})
+}
+
+// deprovisionUser disables the account and signs the user out everywhere,
+// refresh tokens die with their sessions. The email verification state is
+// left alone, verifying the email again must not bring the account back.
+func (su *SCIMUsecase) deprovisionUser(user entities.User) error {
+	err := su.UserRepo.UpdateUser(user, map[string]interface{}{
+		"deprovisioned_at": time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = su.SessionRepo.RevokeSessions(user.ID, 0)
+	if err != nil {
+		return err
+	}
+
+	return su.TokenRepo.RevokeTokens(user.ID)
+}
+
+func (su *SCIMUsecase) takeMember(orgID uint, id string) (entities.OrganizationMember, entities.User, error) {
+	userID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return entities.OrganizationMember{}, entities.User{}, scimError(http.StatusNotFound, "", "user not found")
+	}
+
+	member, err := su.OrgRepo.TakeMember(map[string]interface{}{
+		"organization_id": orgID,
+		"user_id":         uint(userID),
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.OrganizationMember{}, entities.User{}, scimError(http.StatusNotFound, "", "user not found")
+	}
+	if err != nil {
+		return entities.OrganizationMember{}, entities.User{}, err
+	}
+
+	user, err := su.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": member.UserID,
+	})
+	if err != nil {
+		return entities.OrganizationMember{}, entities.User{}, err
+	}
+
+	return member, user, nil
+}
+
+func (su *SCIMUsecase) takeGroup(orgID uint, id string) (entities.OrganizationGroup, error) {
+	groupID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return entities.OrganizationGroup{}, scimError(http.StatusNotFound, "", "group not found")
+	}
+
+	group, err := su.GroupRepo.TakeByConditions(map[string]interface{}{
+		"id":              uint(groupID),
+		"organization_id": orgID,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.OrganizationGroup{}, scimError(http.StatusNotFound, "", "group not found")
+	}
+
+	return group, err
+}
+
+// resolveMemberIDs only accepts users that were provisioned to the organization
+func (su *SCIMUsecase) resolveMemberIDs(orgID uint, refs []dtos.SCIMMemberRef) ([]uint, error) {
+	userIDs, err := parseMemberIDs(refs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userID := range userIDs {
+		_, err := su.OrgRepo.TakeMember(map[string]interface{}{
+			"organization_id": orgID,
+			"user_id":         userID,
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, scimError(http.StatusBadRequest, "invalidValue", "member "+strconv.FormatUint(uint64(userID), 10)+" is not a user of this organization")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return userIDs, nil
+}
+
+func (su *SCIMUsecase) findUsers(userIDs []uint) (map[uint]entities.User, error) {
+	res := map[uint]entities.User{}
+	if len(userIDs) == 0 {
+		return res, nil
+	}
+
+	users, err := su.UserRepo.FindByConditions(map[string]interface{}{
+		"id": userIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		res[user.ID] = user
+	}
+	return res, nil
+}
+
+func (su *SCIMUsecase) toSCIMGroup(group entities.OrganizationGroup) (dtos.SCIMGroup, error) {
+	memberIDs, err := su.GroupRepo.FindMemberIDs(group.ID)
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	users, err := su.findUsers(memberIDs)
+	if err != nil {
+		return dtos.SCIMGroup{}, err
+	}
+
+	base := scimBaseURL()
+	members := make([]dtos.SCIMMemberRef, 0, len(memberIDs))
+	for _, userID := range memberIDs {
+		id := strconv.FormatUint(uint64(userID), 10)
+		members = append(members, dtos.SCIMMemberRef{
+			Value:   id,
+			Ref:     base + "/Users/" + id,
+			Display: users[userID].Username,
+		})
+	}
+
+	id := strconv.FormatUint(uint64(group.ID), 10)
+	return dtos.SCIMGroup{
+		Schemas:     []string{constants.SCIMSchemaGroup},
+		ID:          id,
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Members:     members,
+		Meta: &dtos.SCIMMeta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location:     base + "/Groups/" + id,
+		},
+	}, nil
+}
+
+func toSCIMUser(member entities.OrganizationMember, user entities.User) dtos.SCIMUser {
+	id := strconv.FormatUint(uint64(user.ID), 10)
+	active := scimUserActive(member, user)
+
+	lastModified := user.UpdatedAt
+	if member.UpdatedAt.After(lastModified) {
+		lastModified = member.UpdatedAt
+	}
+
+	return dtos.SCIMUser{
+		Schemas:     []string{constants.SCIMSchemaUser},
+		ID:          id,
+		ExternalID:  member.ExternalID,
+		UserName:    user.Email,
+		Name:        &dtos.SCIMName{Formatted: user.Username},
+		DisplayName: user.Username,
+		Emails: []dtos.SCIMEmail{{
+			Value:   user.Email,
+			Type:    "work",
+			Primary: true,
+		}},
+		Active: &active,
+		Meta: &dtos.SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: lastModified,
+			Location:     scimBaseURL() + "/Users/" + id,
+		},
+	}
+}
+
+// scimOwnsUser tells whether the directory of the organization created the account
+func scimOwnsUser(orgID uint, user entities.User) bool {
+	return user.SCIMOrganizationID != nil && *user.SCIMOrganizationID == orgID
+}
+
+func scimUserActive(member entities.OrganizationMember, user entities.User) bool {
+	if member.DeprovisionedAt != nil {
+		return false
+	}
+	return !scimOwnsUser(member.OrganizationID, user) || user.DeprovisionedAt == nil
+}
+
+// scimUserChangesFromResource maps a full User resource, as sent by POST and PUT
+func scimUserChangesFromResource(req dtos.SCIMUser) scimUserChanges {
+	email := strings.ToLower(strings.TrimSpace(req.UserName))
+	if !strings.Contains(email, "@") {
+		for _, e := range req.Emails {
+			if e.Primary || len(req.Emails) == 1 {
+				email = strings.ToLower(strings.TrimSpace(e.Value))
+			}
+		}
+	}
+
+	username := req.DisplayName
+	if username == "" && req.Name != nil {
+		username = req.Name.Formatted
+		if username == "" {
+			username = strings.TrimSpace(req.Name.GivenName + " " + req.Name.FamilyName)
+		}
+	}
+	if username == "" {
+		username = email
+	}
+
+	externalID := req.ExternalID
+	return scimUserChanges{
+		Email:      &email,
+		Username:   &username,
+		ExternalID: &externalID,
+		Active:     req.Active,
+	}
+}
+
+// setSCIMUserAttribute applies one PATCH value. Attributes this service does
+// not store are ignored, directories send many of them.
+func setSCIMUserAttribute(changes *scimUserChanges, attr string, value json.RawMessage) error {
+	lowerAttr := strings.ToLower(attr)
+	switch {
+	case lowerAttr == "active":
+		active, err := parseSCIMBool(value)
+		if err != nil {
+			return err
+		}
+		changes.Active = &active
+	case lowerAttr == "username":
+		email, err := parseSCIMString(attr, value)
+		if err != nil {
+			return err
+		}
+		email = strings.ToLower(strings.TrimSpace(email))
+		changes.Email = &email
+	case lowerAttr == "displayname" || lowerAttr == "name.formatted":
+		username, err := parseSCIMString(attr, value)
+		if err != nil {
+			return err
+		}
+		changes.Username = &username
+	case lowerAttr == "name":
+		name := dtos.SCIMName{}
+		if json.Unmarshal(value, &name) != nil {
+			return scimError(http.StatusBadRequest, "invalidValue", "name must be an object")
+		}
+		username := name.Formatted
+		if username == "" {
+			username = strings.TrimSpace(name.GivenName + " " + name.FamilyName)
+		}
+		changes.Username = &username
+	case lowerAttr == "externalid":
+		externalID, err := parseSCIMString(attr, value)
+		if err != nil {
+			return err
+		}
+		changes.ExternalID = &externalID
+	case strings.HasPrefix(lowerAttr, "emails"):
+		email := ""
+		emails := []dtos.SCIMEmail{}
+		if json.Unmarshal(value, &emails) == nil {
+			for _, e := range emails {
+				if e.Primary || len(emails) == 1 {
+					email = e.Value
+				}
+			}
+		} else if json.Unmarshal(value, &email) != nil {
+			return scimError(http.StatusBadRequest, "invalidValue", "emails must be a list or a string")
+		}
+		if email != "" {
+			email = strings.ToLower(strings.TrimSpace(email))
+			changes.Email = &email
+		}
+	}
+
+	return nil
+}
+
+// parseSCIMBool also accepts "True" and "False" strings, some directories send those
+func parseSCIMBool(value json.RawMessage) (bool, error) {
+	b := false
+	if json.Unmarshal(value, &b) == nil {
+		return b, nil
+	}
+
+	s := ""
+	if json.Unmarshal(value, &s) == nil {
+		if parsed, err := strconv.ParseBool(s); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return false, scimError(http.StatusBadRequest, "invalidValue", "active must be a boolean")
+}
+
+func parseSCIMString(attr string, value json.RawMessage) (string, error) {
+	s := ""
+	if json.Unmarshal(value, &s) != nil {
+		return "", scimError(http.StatusBadRequest, "invalidValue", attr+" must be a string")
+	}
+	return s, nil
+}
+
+func parseSCIMFilter(filter string) (string, string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", "", nil
+	}
+
+	matches := scimFilterPattern.FindStringSubmatch(filter)
+	if matches == nil {
+		return "", "", scimError(http.StatusBadRequest, "invalidFilter", `only 'attribute eq "value"' filters are supported`)
+	}
+
+	value := strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(matches[2])
+	return matches[1], value, nil
+}
+
+func parseMemberIDs(refs []dtos.SCIMMemberRef) ([]uint, error) {
+	userIDs := make([]uint, 0, len(refs))
+	for _, ref := range refs {
+		userID, err := strconv.ParseUint(ref.Value, 10, 64)
+		if err != nil {
+			return nil, scimError(http.StatusBadRequest, "invalidValue", "invalid member "+ref.Value)
+		}
+		userIDs = append(userIDs, uint(userID))
+	}
+	return userIDs, nil
+}
+
+func memberUserIDs(members []entities.OrganizationMember) []uint {
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	return userIDs
+}
+
+// scimPage converts startIndex and count to an offset and limit
+func scimPage(req dtos.SCIMListRequest) (int, int, int) {
+	startIndex := req.StartIndex
+	if startIndex < 1 {
+		startIndex = 1
+	}
+
+	limit := constants.SCIMDefaultPageSize
+	if req.Count != nil {
+		limit = *req.Count
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	if limit > constants.SCIMMaxPageSize {
+		limit = constants.SCIMMaxPageSize
+	}
+
+	return startIndex, startIndex - 1, limit
+}
+
+// scimQueryLimit a count of 0 only asks for totalResults, the query still needs a limit
+func scimQueryLimit(limit int) int {
+	if limit == 0 {
+		return 1
+	}
+	return limit
+}
+
+func scimListResponse(total int64, startIndex int, resources interface{}) dtos.SCIMListResponse {
+	itemsPerPage := 0
+	switch r := resources.(type) {
+	case []dtos.SCIMUser:
+		itemsPerPage = len(r)
+	case []dtos.SCIMGroup:
+		itemsPerPage = len(r)
+	}
+
+	return dtos.SCIMListResponse{
+		Schemas:      []string{constants.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: itemsPerPage,
+		Resources:    resources,
+	}
+}
+
+func scimError(status int, scimType string, detail string) *dtos.SCIMError {
+	return &dtos.SCIMError{
+		Schemas:  []string{constants.SCIMSchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+func scimBaseURL() string {
+	return auth.Issuer() + "/scim/v2"
+}
@@ -0,0 +1,694 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/utils"
+)
+
+type fakePersonalAccessTokenRepo struct {
+	interfaces.PersonalAccessTokenRepository
+	revokedUserIDs []uint
+}
+
+func (r *fakePersonalAccessTokenRepo) RevokeTokens(userID uint) error {
+	r.revokedUserIDs = append(r.revokedUserIDs, userID)
+	return nil
+}
+
+type fakeOrganizationGroupRepo struct {
+	interfaces.OrganizationGroupRepository
+	groups  memTable[entities.OrganizationGroup]
+	members memTable[entities.OrganizationGroupMember]
+}
+
+func (r *fakeOrganizationGroupRepo) CreateGroup(group entities.OrganizationGroup) (entities.OrganizationGroup, error) {
+	return r.groups.insert(group), nil
+}
+
+func (r *fakeOrganizationGroupRepo) TakeByConditions(conditions map[string]interface{}) (entities.OrganizationGroup, error) {
+	return r.groups.take(conditions)
+}
+
+func (r *fakeOrganizationGroupRepo) UpdateGroup(group entities.OrganizationGroup, data map[string]interface{}) error {
+	r.groups.update(map[string]interface{}{"id": group.ID}, data)
+	return nil
+}
+
+func (r *fakeOrganizationGroupRepo) FindMemberIDs(groupID uint) ([]uint, error) {
+	userIDs := []uint{}
+	for _, member := range r.members.find(map[string]interface{}{"group_id": groupID}) {
+		userIDs = append(userIDs, member.UserID)
+	}
+	return userIDs, nil
+}
+
+func (r *fakeOrganizationGroupRepo) AddMembers(groupID uint, userIDs []uint) error {
+	for _, userID := range userIDs {
+		if _, err := r.members.take(map[string]interface{}{"group_id": groupID, "user_id": userID}); err != nil {
+			r.members.insert(entities.OrganizationGroupMember{GroupID: groupID, UserID: userID})
+		}
+	}
+	return nil
+}
+
+func (r *fakeOrganizationGroupRepo) RemoveMembers(groupID uint, userIDs []uint) error {
+	r.members.delete(map[string]interface{}{"group_id": groupID, "user_id": userIDs})
+	return nil
+}
+
+func (r *fakeOrganizationGroupRepo) ReplaceMembers(groupID uint, userIDs []uint) error {
+	r.members.delete(map[string]interface{}{"group_id": groupID})
+	return r.AddMembers(groupID, userIDs)
+}
+
+type scimFixture struct {
+	usecase  *SCIMUsecase
+	users    *fakeUserRepo
+	orgs     *fakeOrganizationRepo
+	sessions *fakeSessionRepo
+	tokens   *fakePersonalAccessTokenRepo
+	org      entities.Organization
+	otherOrg entities.Organization
+}
+
+func newSCIMFixture(t *testing.T) scimFixture {
+	t.Helper()
+	t.Setenv("OIDC_ISSUER", testIssuer)
+
+	f := scimFixture{
+		users:    &fakeUserRepo{},
+		orgs:     &fakeOrganizationRepo{},
+		sessions: &fakeSessionRepo{},
+		tokens:   &fakePersonalAccessTokenRepo{},
+	}
+	f.org = f.orgs.orgs.insert(entities.Organization{Name: "Acme", Slug: "acme"})
+	f.otherOrg = f.orgs.orgs.insert(entities.Organization{Name: "Globex", Slug: "globex"})
+	f.usecase = &SCIMUsecase{
+		UserRepo:    f.users,
+		SessionRepo: f.sessions,
+		TokenRepo:   f.tokens,
+		OrgRepo:     f.orgs,
+		GroupRepo:   &fakeOrganizationGroupRepo{},
+	}
+	return f
+}
+
+// provision creates a user through the directory of f.org
+func (f scimFixture) provision(t *testing.T, email string) dtos.SCIMUser {
+	t.Helper()
+
+	user, err := f.usecase.CreateUser(f.org.ID, dtos.SCIMUser{UserName: email, DisplayName: email})
+	if err != nil {
+		t.Fatalf("CreateUser(%q) error = %v", email, err)
+	}
+	return user
+}
+
+// addAccount inserts an account that signed up by itself, optionally as a member of orgID
+func (f scimFixture) addAccount(email string, orgID uint) entities.User {
+	user := f.users.users.insert(entities.User{Email: email, Username: email, IsActive: true, Role: constants.RoleUser})
+	if orgID != 0 {
+		f.orgs.members.insert(entities.OrganizationMember{OrganizationID: orgID, UserID: user.ID, Role: constants.OrganizationRoleMember})
+	}
+	return user
+}
+
+func (f scimFixture) user(t *testing.T, id uint) entities.User {
+	t.Helper()
+
+	user, err := f.users.TakeByConditions(map[string]interface{}{"id": id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func scimOp(op string, path string, value string) dtos.SCIMPatchOperation {
+	return dtos.SCIMPatchOperation{Op: op, Path: path, Value: json.RawMessage(value)}
+}
+
+func scimUserID(t *testing.T, user dtos.SCIMUser) uint {
+	t.Helper()
+
+	id, err := strconv.ParseUint(user.ID, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uint(id)
+}
+
+// checkSCIMError fails unless err is a SCIM error of the given scimType
+func checkSCIMError(t *testing.T, err error, scimType string) {
+	t.Helper()
+
+	var scimErr *dtos.SCIMError
+	if !errors.As(err, &scimErr) {
+		t.Fatalf("error = %v, want a SCIM error %q", err, scimType)
+	}
+	if scimErr.ScimType != scimType {
+		t.Fatalf("scimType = %q, want %q", scimErr.ScimType, scimType)
+	}
+}
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		filter    string
+		wantAttr  string
+		wantValue string
+		wantErr   bool
+	}{
+		{filter: ""},
+		{filter: "   "},
+		{filter: `userName eq "jane@acme.test"`, wantAttr: "userName", wantValue: "jane@acme.test"},
+		{filter: `USERNAME EQ "jane@acme.test"`, wantAttr: "USERNAME", wantValue: "jane@acme.test"},
+		{filter: `  externalId   eq   "42"  `, wantAttr: "externalId", wantValue: "42"},
+		{filter: `emails.value eq "jane@acme.test"`, wantAttr: "emails.value", wantValue: "jane@acme.test"},
+		{filter: `displayName eq ""`, wantAttr: "displayName"},
+		{filter: `displayName eq "say \"hi\""`, wantAttr: "displayName", wantValue: `say "hi"`},
+		{filter: `displayName eq "back\\slash"`, wantAttr: "displayName", wantValue: `back\slash`},
+		{filter: `userName co "jane"`, wantErr: true},
+		{filter: `userName eq jane`, wantErr: true},
+		{filter: `userName eq "a" and active eq "true"`, wantErr: true},
+		{filter: `userName eq "a" or userName eq "b"`, wantErr: true},
+		{filter: `userName eq "unterminated`, wantErr: true},
+		{filter: `eq "jane"`, wantErr: true},
+		{filter: `1name eq "jane"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			attr, value, err := parseSCIMFilter(tt.filter)
+			if tt.wantErr {
+				checkSCIMError(t, err, "invalidFilter")
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSCIMFilter() error = %v", err)
+			}
+			if attr != tt.wantAttr || value != tt.wantValue {
+				t.Errorf("parseSCIMFilter() = %q, %q, want %q, %q", attr, value, tt.wantAttr, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestSCIMMemberFilterPattern(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{path: `members[value eq "12"]`, want: "12", ok: true},
+		{path: `Members[ value EQ "12" ]`, want: "12", ok: true},
+		{path: `members[value eq ""]`, want: "", ok: true},
+		{path: `members`},
+		{path: `members[display eq "jane"]`},
+		{path: `members[value eq "12"].display`},
+		{path: `members[value eq 12]`},
+		{path: `groups[value eq "12"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			matches := scimMemberFilterPattern.FindStringSubmatch(tt.path)
+			if (matches != nil) != tt.ok {
+				t.Fatalf("match = %v, want %v", matches != nil, tt.ok)
+			}
+			if tt.ok && matches[1] != tt.want {
+				t.Errorf("value = %q, want %q", matches[1], tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		ops      []dtos.SCIMPatchOperation
+		check    func(t *testing.T, res dtos.SCIMUser, user entities.User)
+		scimType string
+	}{
+		{
+			name: "replace active",
+			ops:  []dtos.SCIMPatchOperation{scimOp("replace", "active", `false`)},
+			check: func(t *testing.T, res dtos.SCIMUser, user entities.User) {
+				if *res.Active || user.DeprovisionedAt == nil {
+					t.Errorf("user was not deprovisioned")
+				}
+				if !user.IsActive {
+					t.Errorf("deprovisioning changed the email verification state")
+				}
+			},
+		},
+		{
+			name: "replace active as a string",
+			ops:  []dtos.SCIMPatchOperation{scimOp("Replace", "active", `"False"`)},
+			check: func(t *testing.T, res dtos.SCIMUser, user entities.User) {
+				if *res.Active {
+					t.Errorf("active = true, want false")
+				}
+			},
+		},
+		{
+			name: "replace userName",
+			ops:  []dtos.SCIMPatchOperation{scimOp("replace", "userName", `" Jane.Doe@Acme.test "`)},
+			check: func(t *testing.T, res dtos.SCIMUser, user entities.User) {
+				if user.Email != "jane.doe@acme.test" {
+					t.Errorf("email = %q", user.Email)
+				}
+			},
+		},
+		{
+			name: "add displayName",
+			ops:  []dtos.SCIMPatchOperation{scimOp("add", "displayName", `"Jane Doe"`)},
+			check: func(t *testing.T, res dtos.SCIMUser, user entities.User) {
+				if user.Username != "Jane Doe" {
+					t.Errorf("username = %q", user.Username)
+				}
+			},
+		},
+		{
+			name: "replace name",
+			ops:  []dtos.SCIMPatchOperation{scimOp("replace", "name", `{"givenName":"Jane","familyName":"Doe"}`)},
+			check: func(t *testing.T, res dtos.SCIMUser, user entities.User) {
+				if user.Username != "Jane Doe" {
+					t.Errorf("username = %q", user.Username)
+				}
+			},
+		},
+		{
+			name: "replace emails",
+			ops: []dtos.SCIMPatchOperation{scimOp("replace", "emails", `[
+				{"value":"home@acme.test","type":"home"},
+				{"value":"work@acme.test","type":"work","primary":true}
+			]`)},
+			check: func(t *testing.T, res dtos.SCIMUser, user entities.User) {
+				if user.Email != "work@acme.test" {
+					t.Errorf("email = %q, want the primary one", user.Email)
+				}
+			},
+		},
+		{
+			name: "replace externalId",
+			ops:  []dtos.SCIMPatchOperation{scimOp("replace", "externalId", `"ext-2"`)},
+			check: func(t *testing.T, res dtos.SCIMUser, user entities.User) {
+				if res.ExternalID != "ext-2" {
+					t.Errorf("externalId = %q", res.ExternalID)
+				}
+			},
+		},
+		{
+			name: "remove externalId",
+			ops:  []dtos.SCIMPatchOperation{scimOp("remove", "externalId", ``)},
+			check: func(t *testing.T, res dtos.SCIMUser, user entities.User) {
+				if res.ExternalID != "" {
+					t.Errorf("externalId = %q", res.ExternalID)
+				}
+			},
+		},
+		{
+			name: "replace without path",
+			ops:  []dtos.SCIMPatchOperation{scimOp("replace", "", `{"active":false,"displayName":"Jane Doe","title":"ignored"}`)},
+			check: func(t *testing.T, res dtos.SCIMUser, user entities.User) {
+				if *res.Active || user.Username != "Jane Doe" {
+					t.Errorf("active = %v, username = %q", *res.Active, user.Username)
+				}
+			},
+		},
+		{
+			name:     "replace without path needs an object",
+			ops:      []dtos.SCIMPatchOperation{scimOp("replace", "", `"Jane"`)},
+			scimType: "invalidValue",
+		},
+		{
+			name:     "remove userName",
+			ops:      []dtos.SCIMPatchOperation{scimOp("remove", "userName", ``)},
+			scimType: "mutability",
+		},
+		{
+			name:     "replace active with a word",
+			ops:      []dtos.SCIMPatchOperation{scimOp("replace", "active", `"maybe"`)},
+			scimType: "invalidValue",
+		},
+		{
+			name:     "replace userName with a name",
+			ops:      []dtos.SCIMPatchOperation{scimOp("replace", "userName", `"jane"`)},
+			scimType: "invalidValue",
+		},
+		{
+			name:     "unknown op",
+			ops:      []dtos.SCIMPatchOperation{scimOp("move", "active", `false`)},
+			scimType: "invalidSyntax",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSCIMFixture(t)
+			created, err := f.usecase.CreateUser(f.org.ID, dtos.SCIMUser{UserName: "jane@acme.test", ExternalID: "ext-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := f.usecase.PatchUser(f.org.ID, created.ID, dtos.SCIMPatchRequest{Operations: tt.ops})
+			if tt.scimType != "" {
+				checkSCIMError(t, err, tt.scimType)
+				return
+			}
+			if err != nil {
+				t.Fatalf("PatchUser() error = %v", err)
+			}
+			tt.check(t, res, f.user(t, scimUserID(t, created)))
+		})
+	}
+}
+
+func TestPatchGroup(t *testing.T) {
+	tests := []struct {
+		name        string
+		ops         func(ids []string) []dtos.SCIMPatchOperation
+		wantMembers []int
+		check       func(t *testing.T, res dtos.SCIMGroup)
+		scimType    string
+	}{
+		{
+			name: "add members",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("add", "members", `[{"value":"`+ids[2]+`"}]`)}
+			},
+			wantMembers: []int{0, 1, 2},
+		},
+		{
+			name: "replace members",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("replace", "members", `[{"value":"`+ids[2]+`"}]`)}
+			},
+			wantMembers: []int{2},
+		},
+		{
+			name: "remove members",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("remove", "members", `[{"value":"`+ids[0]+`"}]`)}
+			},
+			wantMembers: []int{1},
+		},
+		{
+			name: "remove member filter",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("remove", `members[value eq "`+ids[1]+`"]`, ``)}
+			},
+			wantMembers: []int{0},
+		},
+		{
+			name: "remove every member",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("remove", "members", ``)}
+			},
+			wantMembers: []int{},
+		},
+		{
+			name: "replace displayName",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("replace", "displayName", `"Admins"`)}
+			},
+			wantMembers: []int{0, 1},
+			check: func(t *testing.T, res dtos.SCIMGroup) {
+				if res.DisplayName != "Admins" {
+					t.Errorf("displayName = %q", res.DisplayName)
+				}
+			},
+		},
+		{
+			name: "remove externalId",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("remove", "externalId", ``)}
+			},
+			wantMembers: []int{0, 1},
+			check: func(t *testing.T, res dtos.SCIMGroup) {
+				if res.ExternalID != "" {
+					t.Errorf("externalId = %q", res.ExternalID)
+				}
+			},
+		},
+		{
+			name: "replace without path",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("replace", "", `{"displayName":"Admins","members":[{"value":"`+ids[1]+`"}]}`)}
+			},
+			wantMembers: []int{1},
+			check: func(t *testing.T, res dtos.SCIMGroup) {
+				if res.DisplayName != "Admins" {
+					t.Errorf("displayName = %q", res.DisplayName)
+				}
+			},
+		},
+		{
+			name: "add to a member filter",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("add", `members[value eq "`+ids[2]+`"]`, ``)}
+			},
+			scimType: "invalidPath",
+		},
+		{
+			name: "add a user of another organization",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("add", "members", `[{"value":"`+ids[3]+`"}]`)}
+			},
+			scimType: "invalidValue",
+		},
+		{
+			name: "remove without path",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("remove", "", ``)}
+			},
+			scimType: "noTarget",
+		},
+		{
+			name: "empty displayName",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("replace", "displayName", `""`)}
+			},
+			scimType: "invalidValue",
+		},
+		{
+			name: "unknown path",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("replace", "owner", `"jane"`)}
+			},
+			scimType: "invalidPath",
+		},
+		{
+			name: "unknown op",
+			ops: func(ids []string) []dtos.SCIMPatchOperation {
+				return []dtos.SCIMPatchOperation{scimOp("copy", "displayName", `"Admins"`)}
+			},
+			scimType: "invalidSyntax",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSCIMFixture(t)
+			ids := []string{
+				f.provision(t, "a@acme.test").ID,
+				f.provision(t, "b@acme.test").ID,
+				f.provision(t, "c@acme.test").ID,
+				strconv.FormatUint(uint64(f.addAccount("d@globex.test", f.otherOrg.ID).ID), 10),
+			}
+
+			group, err := f.usecase.CreateGroup(f.org.ID, dtos.SCIMGroup{
+				DisplayName: "Engineering",
+				ExternalID:  "ext-group",
+				Members:     []dtos.SCIMMemberRef{{Value: ids[0]}, {Value: ids[1]}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := f.usecase.PatchGroup(f.org.ID, group.ID, dtos.SCIMPatchRequest{Operations: tt.ops(ids)})
+			if tt.scimType != "" {
+				checkSCIMError(t, err, tt.scimType)
+				return
+			}
+			if err != nil {
+				t.Fatalf("PatchGroup() error = %v", err)
+			}
+
+			members := map[string]bool{}
+			for _, member := range res.Members {
+				members[member.Value] = true
+			}
+			if len(members) != len(tt.wantMembers) {
+				t.Errorf("members = %v, want users %v", res.Members, tt.wantMembers)
+			}
+			for _, i := range tt.wantMembers {
+				if !members[ids[i]] {
+					t.Errorf("members = %v, want users %v", res.Members, tt.wantMembers)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, res)
+			}
+		})
+	}
+}
+
+func TestCreateUserExistingAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, f scimFixture) string
+		wantLink bool
+	}{
+		{
+			name: "account that signed up by itself",
+			setup: func(t *testing.T, f scimFixture) string {
+				return f.addAccount("jane@acme.test", 0).Email
+			},
+		},
+		{
+			name: "member of another organization",
+			setup: func(t *testing.T, f scimFixture) string {
+				return f.addAccount("jane@acme.test", f.otherOrg.ID).Email
+			},
+		},
+		{
+			name: "member the directory did not claim yet",
+			setup: func(t *testing.T, f scimFixture) string {
+				return f.addAccount("jane@acme.test", f.org.ID).Email
+			},
+			wantLink: true,
+		},
+		{
+			name: "member the directory already provisioned",
+			setup: func(t *testing.T, f scimFixture) string {
+				return f.provision(t, "jane@acme.test").UserName
+			},
+		},
+		{
+			name: "account the directory deleted before",
+			setup: func(t *testing.T, f scimFixture) string {
+				user := f.provision(t, "jane@acme.test")
+				if err := f.usecase.DeleteUser(f.org.ID, user.ID); err != nil {
+					t.Fatal(err)
+				}
+				return user.UserName
+			},
+			wantLink: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSCIMFixture(t)
+			email := tt.setup(t, f)
+
+			res, err := f.usecase.CreateUser(f.org.ID, dtos.SCIMUser{UserName: email, ExternalID: "ext-1"})
+			if !tt.wantLink {
+				checkSCIMError(t, err, "uniqueness")
+				if len(f.users.users.rows) != 1 {
+					t.Errorf("a second account was created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateUser() error = %v", err)
+			}
+			if res.ExternalID != "ext-1" || !*res.Active {
+				t.Errorf("CreateUser() externalId = %q, active = %v", res.ExternalID, *res.Active)
+			}
+			if user := f.user(t, scimUserID(t, res)); user.DeprovisionedAt != nil {
+				t.Errorf("linked account is still deprovisioned")
+			}
+		})
+	}
+}
+
+func TestSCIMSharedAccount(t *testing.T) {
+	f := newSCIMFixture(t)
+	user := f.addAccount("jane@acme.test", f.org.ID)
+	f.orgs.members.insert(entities.OrganizationMember{OrganizationID: f.otherOrg.ID, UserID: user.ID})
+	id := strconv.FormatUint(uint64(user.ID), 10)
+
+	_, err := f.usecase.PatchUser(f.org.ID, id, dtos.SCIMPatchRequest{Operations: []dtos.SCIMPatchOperation{
+		scimOp("replace", "userName", `"attacker@acme.test"`),
+	}})
+	checkSCIMError(t, err, "mutability")
+
+	res, err := f.usecase.PatchUser(f.org.ID, id, dtos.SCIMPatchRequest{Operations: []dtos.SCIMPatchOperation{
+		scimOp("replace", "", `{"active":false,"displayName":"Renamed"}`),
+	}})
+	if err != nil {
+		t.Fatalf("PatchUser() error = %v", err)
+	}
+	if *res.Active {
+		t.Errorf("membership is still active")
+	}
+
+	err = f.usecase.DeleteUser(f.org.ID, id)
+	if err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	account := f.user(t, user.ID)
+	if account.Email != user.Email || account.Username != user.Username || account.DeprovisionedAt != nil || !account.IsActive {
+		t.Errorf("the directory changed an account it did not create: %+v", account)
+	}
+	if len(f.tokens.revokedUserIDs) != 0 {
+		t.Errorf("the directory revoked the tokens of an account it did not create")
+	}
+	if _, err := f.orgs.TakeMember(map[string]interface{}{"organization_id": f.otherOrg.ID, "user_id": user.ID}); err != nil {
+		t.Errorf("membership of the other organization was removed")
+	}
+	if _, err := f.usecase.GetUser(f.org.ID, id); err == nil {
+		t.Errorf("user is still a member after DeleteUser")
+	}
+	if _, err := f.usecase.GetUser(f.otherOrg.ID, id); err != nil {
+		t.Errorf("GetUser() of the other organization error = %v", err)
+	}
+}
+
+func TestDeprovisionedUserCannotSignIn(t *testing.T) {
+	f := newSCIMFixture(t)
+	created := f.provision(t, "jane@acme.test")
+	userID := scimUserID(t, created)
+
+	hashPassword, err := utils.HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.users.users.update(map[string]interface{}{"id": userID}, map[string]interface{}{"password": hashPassword})
+	f.sessions.sessions.insert(entities.Session{UserID: userID})
+
+	err = f.usecase.DeleteUser(f.org.ID, created.ID)
+	if err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	if session, _ := f.sessions.sessions.take(map[string]interface{}{"user_id": userID}); session.RevokedAt == nil {
+		t.Errorf("session of the deprovisioned user was not revoked")
+	}
+	if len(f.tokens.revokedUserIDs) != 1 || f.tokens.revokedUserIDs[0] != userID {
+		t.Errorf("revoked tokens of users %v, want [%d]", f.tokens.revokedUserIDs, userID)
+	}
+
+	au := &AuthUsecase{UserRepo: f.users, Authenticator: NewLocalAuthenticator(f.users)}
+
+	// the verification link must not bring the account back
+	if err := au.activeUser(userID); err == nil {
+		t.Errorf("activeUser() re-enabled a deprovisioned account")
+	}
+
+	_, err = au.authenticate(dtos.SignInRequest{Email: "jane@acme.test", Password: "correct horse battery staple"})
+	var authErr *dtos.AuthError
+	if !errors.As(err, &authErr) || authErr.Code != constants.AuthErrorUserDeprovisioned {
+		t.Fatalf("authenticate() error = %v, want code %d", err, constants.AuthErrorUserDeprovisioned)
+	}
+}
@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"errors"
+	"time"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/entities"
+)
+
+type SessionUsecase struct {
+	SessionRepo interfaces.SessionRepository
+}
+
+func NewSessionUsecase(sr interfaces.SessionRepository) interfaces.SessionUsecase {
+	return &SessionUsecase{
+		SessionRepo: sr,
+	}
+}
+
+func (su *SessionUsecase) ListSessions(userID uint) ([]entities.Session, error) {
+	sessions, err := su.SessionRepo.FindActiveByUserID(userID)
+
+	return sessions, err
+}
+
+func (su *SessionUsecase) RevokeSession(userID uint, sessionID uint) error {
+	session, err := su.SessionRepo.TakeByConditions(map[string]interface{}{
+		"id":      sessionID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if session.RevokedAt != nil {
+		return errors.New("session already revoked")
+	}
+
+	return su.SessionRepo.UpdateSession(session, map[string]interface{}{
+		"revoked_at": time.Now(),
+	})
+}
@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+)
+
+// ErrInvalidMetadata is returned when a metadata patch does not match the
+// attributes declared by the organizations of the user
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// profileChanges returns the columns to update for req. The metadata patch is
+// checked against the attributes declared by the organizations of the user.
+func profileChanges(attrRepo interfaces.OrganizationAttributeRepository, user entities.User, req dtos.UpdateProfileRequest) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	if req.DisplayName != nil {
+		data["display_name"] = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.AvatarURL != nil {
+		data["avatar_url"] = *req.AvatarURL
+	}
+	if req.Locale != nil {
+		data["locale"] = *req.Locale
+	}
+	if req.Timezone != nil {
+		data["timezone"] = *req.Timezone
+	}
+
+	if req.Metadata != nil {
+		metadata, err := mergeUserMetadata(attrRepo, user, req.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		data["metadata"] = metadata
+	}
+
+	return data, nil
+}
+
+// mergeUserMetadata applies patch to the metadata of user, a null value
+// removes the attribute. Every problem is reported at once.
+func mergeUserMetadata(attrRepo interfaces.OrganizationAttributeRepository, user entities.User, patch map[string]interface{}) (entities.JSONMap, error) {
+	attrs, err := attrRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// two organizations may declare the same name, the value must suit both
+	declared := map[string][]entities.OrganizationAttribute{}
+	for _, attr := range attrs {
+		declared[attr.Name] = append(declared[attr.Name], attr)
+	}
+
+	metadata := entities.JSONMap{}
+	for name, value := range user.Metadata {
+		metadata[name] = value
+	}
+
+	names := make([]string, 0, len(patch))
+	for name := range patch {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		value := patch[name]
+		if _, ok := declared[name]; !ok {
+			problems = append(problems, name+": unknown attribute")
+			continue
+		}
+
+		if value == nil {
+			delete(metadata, name)
+			continue
+		}
+
+		for _, attr := range declared[name] {
+			if err := checkAttributeValue(attr, value); err != nil {
+				problems = append(problems, name+": "+err.Error())
+				break
+			}
+		}
+		metadata[name] = value
+	}
+
+	missing := map[string]bool{}
+	for _, attr := range attrs {
+		if attr.Required && metadata[attr.Name] == nil && !missing[attr.Name] {
+			missing[attr.Name] = true
+			problems = append(problems, attr.Name+": is required")
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMetadata, strings.Join(problems, "; "))
+	}
+
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	if len(encoded) > constants.UserMetadataMaxBytes {
+		return nil, fmt.Errorf("%w: must not exceed %d bytes", ErrInvalidMetadata, constants.UserMetadataMaxBytes)
+	}
+
+	return metadata, nil
+}
+
+// checkAttributeValue checks a decoded JSON value against its declaration
+func checkAttributeValue(attr entities.OrganizationAttribute, value interface{}) error {
+	switch attr.Type {
+	case constants.AttributeTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		if attr.MaxLength > 0 && utf8.RuneCountInString(s) > attr.MaxLength {
+			return fmt.Errorf("must be at most %d characters", attr.MaxLength)
+		}
+	case constants.AttributeTypeNumber:
+		if _, ok := value.(float64); !ok {
+			return errors.New("must be a number")
+		}
+	case constants.AttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return errors.New("must be a boolean")
+		}
+	case constants.AttributeTypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		for _, option := range attr.Options {
+			if s == option {
+				return nil
+			}
+		}
+		return errors.New("must be one of " + strings.Join(attr.Options, ", "))
+	default:
+		return errors.New("has an unknown type " + attr.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package usecases
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/mailer"
+	"engine/pkg/shared/utils"
+)
+
+// ErrEmailExists is returned when the email is already used by another account
+var ErrEmailExists = errors.New("email already exists")
+
+// sortableUserColumns whitelist of columns users can be sorted by
+var sortableUserColumns = map[string]bool{
+	"id":         true,
+	"username":   true,
+	"email":      true,
+	"role":       true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+type UserUsecase struct {
+	UserRepo         interfaces.UserRepository
+	SessionRepo      interfaces.SessionRepository
+	RefreshTokenRepo interfaces.OAuthRefreshTokenRepository
+	AttributeRepo    interfaces.OrganizationAttributeRepository
+	Mailer           mailer.Mailer
+}
+
+func NewUserUsecase(
+	ur interfaces.UserRepository,
+	sr interfaces.SessionRepository,
+	rtr interfaces.OAuthRefreshTokenRepository,
+	oar interfaces.OrganizationAttributeRepository,
+	m mailer.Mailer,
+) interfaces.UserUsecase {
+	return &UserUsecase{
+		UserRepo:         ur,
+		SessionRepo:      sr,
+		RefreshTokenRepo: rtr,
+		AttributeRepo:    oar,
+		Mailer:           m,
+	}
+}
+
+func (uu *UserUsecase) ListUsers(req dtos.ListUsersRequest) ([]entities.User, dtos.PaginationResponse, error) {
+	page := req.Page
+	if page == 0 {
+		page = 1
+	}
+
+	pageSize := req.PageSize
+	if pageSize == 0 {
+		pageSize = constants.DefaultPageSize
+	}
+	if pageSize > constants.MaxPageSize {
+		pageSize = constants.MaxPageSize
+	}
+
+	order, err := parseUserSort(req.Sort)
+	if err != nil {
+		return nil, dtos.PaginationResponse{}, err
+	}
+
+	conditions := map[string]interface{}{}
+	if req.Role != "" {
+		conditions["role"] = req.Role
+	}
+	if req.IsActive != nil {
+		conditions["is_active"] = *req.IsActive
+	}
+	if req.IsSuspended != nil {
+		conditions["is_suspended"] = *req.IsSuspended
+	}
+
+	users, total, err := uu.UserRepo.PaginateByConditions(conditions, req.Keyword, order, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, dtos.PaginationResponse{}, err
+	}
+
+	return users, dtos.PaginationResponse{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+func (uu *UserUsecase) GetUser(userID uint) (entities.User, error) {
+	user, err := uu.UserRepo.TakeByConditions(map[string]interface{}{
+		"id": userID,
+	})
+
+	return user, err
+}
+
+func (uu *UserUsecase) CreateUser(req dtos.AdminCreateUserRequest) (entities.User, error) {
+	_, err := uu.UserRepo.TakeByConditions(map[string]interface{}{
+		"email": req.Email,
+	})
+	if err == nil {
+		return entities.User{}, ErrEmailExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.User{}, err
+	}
+
+	hashPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = constants.RoleUser
+	}
+
+	user := entities.User{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: hashPassword,
+		IsActive: true,
+		Role:     role,
+	}
+
+	return uu.UserRepo.CreateUser(user)
+}
+
+func (uu *UserUsecase) UpdateUser(userID uint, req dtos.AdminUpdateUserRequest) (entities.User, error) {
+	user, err := uu.GetUser(userID)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	data, err := profileChanges(uu.AttributeRepo, user, req.UpdateProfileRequest)
+	if err != nil {
+		return entities.User{}, err
+	}
+	if req.Username != nil {
+		data["username"] = *req.Username
+	}
+	if req.Email != nil && *req.Email != user.Email {
+		_, err := uu.UserRepo.TakeByConditions(map[string]interface{}{
+			"email": *req.Email,
+		})
+		if err == nil {
+			return entities.User{}, ErrEmailExists
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.User{}, err
+		}
+		data["email"] = *req.Email
+		data["email_bounced"] = false
+	}
+	// the role is a claim of the access token, a changed role or a
+	// deactivation needs a new sign in
+	signOut := false
+	if req.Role != nil {
+		data["role"] = *req.Role
+		signOut = *req.Role != user.Role
+	}
+	if req.IsActive != nil {
+		data["is_active"] = *req.IsActive
+		signOut = signOut || (user.IsActive && !*req.IsActive)
+	}
+
+	if len(data) == 0 {
+		return user, nil
+	}
+
+	err = uu.UserRepo.UpdateUser(user, data)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	if signOut {
+		err = uu.signOut(user.ID)
+		if err != nil {
+			return entities.User{}, err
+		}
+	}
+
+	return uu.GetUser(userID)
+}
+
+func (uu *UserUsecase) SuspendUser(userID uint) error {
+	user, err := uu.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsSuspended {
+		return errors.New("user already suspended")
+	}
+
+	err = uu.UserRepo.UpdateUser(user, map[string]interface{}{
+		"is_suspended": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return uu.signOut(user.ID)
+}
+
+func (uu *UserUsecase) ReactivateUser(userID uint) error {
+	user, err := uu.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsSuspended {
+		return errors.New("user is not suspended")
+	}
+
+	return uu.UserRepo.UpdateUser(user, map[string]interface{}{
+		"is_suspended": false,
+	})
+}
+
+func (uu *UserUsecase) ForcePasswordReset(userID uint) error {
+	user, err := uu.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	err = uu.UserRepo.UpdateUser(user, map[string]interface{}{
+		"must_reset_password": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = uu.signOut(user.ID)
+	if err != nil {
+		return err
+	}
+
+	// the locale of the admin says nothing about the user
+	return sendMailResetPassword(uu.Mailer, user, "")
+}
+
+func (uu *UserUsecase) DeleteUser(userID uint) error {
+	user, err := uu.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	err = uu.UserRepo.DeleteUser(user)
+	if err != nil {
+		return err
+	}
+
+	return uu.signOut(user.ID)
+}
+
+// signOut revokes the sessions of the user and the refresh tokens that could
+// start new ones. Personal access tokens check the user on every request.
+func (uu *UserUsecase) signOut(userID uint) error {
+	err := uu.SessionRepo.RevokeSessions(userID, 0)
+	if err != nil {
+		return err
+	}
+
+	return uu.RefreshTokenRepo.RevokeByUserID(userID)
+}
+
+// parseUserSort converts "field" or "-field" to an ORDER BY clause
+func parseUserSort(sort string) (string, error) {
+	if sort == "" {
+		return "id asc", nil
+	}
+
+	direction := "asc"
+	if strings.HasPrefix(sort, "-") {
+		direction = "desc"
+		sort = strings.TrimPrefix(sort, "-")
+	}
+
+	if !sortableUserColumns[sort] {
+		return "", errors.New("invalid sort field: " + sort)
+	}
+
+	return sort + " " + direction, nil
+}
@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"testing"
+
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/constants"
+)
+
+func TestAdminActionsSignOut(t *testing.T) {
+	admin, user := constants.RoleAdmin, constants.RoleUser
+	inactive, active := false, true
+	username := "Jane Admin"
+
+	tests := []struct {
+		name        string
+		action      func(uu *UserUsecase, userID uint) error
+		wantSignOut bool
+	}{
+		{name: "suspend", wantSignOut: true, action: func(uu *UserUsecase, userID uint) error {
+			return uu.SuspendUser(userID)
+		}},
+		{name: "delete", wantSignOut: true, action: func(uu *UserUsecase, userID uint) error {
+			return uu.DeleteUser(userID)
+		}},
+		{name: "demote", wantSignOut: true, action: func(uu *UserUsecase, userID uint) error {
+			_, err := uu.UpdateUser(userID, dtos.AdminUpdateUserRequest{Role: &user})
+			return err
+		}},
+		{name: "deactivate", wantSignOut: true, action: func(uu *UserUsecase, userID uint) error {
+			_, err := uu.UpdateUser(userID, dtos.AdminUpdateUserRequest{IsActive: &inactive})
+			return err
+		}},
+		{name: "same role", action: func(uu *UserUsecase, userID uint) error {
+			_, err := uu.UpdateUser(userID, dtos.AdminUpdateUserRequest{Role: &admin, IsActive: &active})
+			return err
+		}},
+		{name: "rename", action: func(uu *UserUsecase, userID uint) error {
+			_, err := uu.UpdateUser(userID, dtos.AdminUpdateUserRequest{Username: &username})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{}
+			jane := users.users.insert(entities.User{Email: "jane@acme.test", IsActive: true, Role: constants.RoleAdmin})
+			sessions := &fakeSessionRepo{}
+			sessions.sessions.insert(entities.Session{UserID: jane.ID})
+			refreshTokens := &fakeRefreshTokenRepo{}
+			refreshTokens.tokens.insert(entities.OAuthRefreshToken{UserID: jane.ID, SessionID: 1})
+			uu := &UserUsecase{UserRepo: users, SessionRepo: sessions, RefreshTokenRepo: refreshTokens}
+
+			if err := tt.action(uu, jane.ID); err != nil {
+				t.Fatalf("action error = %v", err)
+			}
+
+			sessionRevoked := sessions.sessions.rows[0].RevokedAt != nil
+			tokenRevoked := refreshTokens.tokens.rows[0].RevokedAt != nil
+			if sessionRevoked != tt.wantSignOut || tokenRevoked != tt.wantSignOut {
+				t.Errorf("session revoked = %v, refresh token revoked = %v, want %v", sessionRevoked, tokenRevoked, tt.wantSignOut)
+			}
+		})
+	}
+}
@@ -7,8 +7,12 @@ import (
 
 	"engine/config"
 	"engine/internal/app/myapi/router"
+	"engine/internal/pkg/repositories"
+	"engine/internal/pkg/usecases"
+	"engine/pkg/shared/constants"
 	"engine/pkg/shared/database"
 	sharedLogger "engine/pkg/shared/logger"
+	"engine/pkg/shared/mailer"
 )
 
 func main() {
@@ -23,6 +27,12 @@ func main() {
 		DBConn: config.LoadDB(logger),
 	}
 
+	// emails queued in email_outbox are delivered in the background, except
+	// to the addresses on the suppression list
+	outboxMailer := mailer.NewSuppressingMailer(mailer.Default(), repositories.NewEmailSuppressionRepository(router.DBConn))
+	outboxUsecase := usecases.NewEmailOutboxUsecase(repositories.NewEmailOutboxRepository(router.DBConn), outboxMailer)
+	go outboxUsecase.RunWorker(constants.EmailOutboxPollInterval, nil)
+
 	defer database.CloseDB(config.LoadDB(logger), logger)
 
 	router.InitializeRouter(logger)
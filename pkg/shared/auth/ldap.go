@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ErrLDAPInvalidCredentials is returned when the directory refuses the bind
+var ErrLDAPInvalidCredentials = errors.New("password is incorrect")
+
+// LDAPConfig is read from the LDAP_* env variables
+type LDAPConfig struct {
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	// BindDN and BindPassword are the service account used to search, an
+	// empty BindDN searches anonymously
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter finds the entry of a login, %s is the escaped login
+	UserFilter string
+	// UserDNTemplate skips the search and binds directly, %s is the escaped login
+	UserDNTemplate    string
+	EmailAttribute    string
+	UsernameAttribute string
+	GroupAttribute    string
+	// AdminGroups are the group DNs mapped to the admin role
+	AdminGroups     []string
+	JITProvisioning bool
+}
+
+// LDAPEntry is what the directory knows about a user after a successful bind
+type LDAPEntry struct {
+	DN       string
+	Email    string
+	Username string
+	Groups   []string
+}
+
+func LoadLDAPConfig() LDAPConfig {
+	config := LDAPConfig{
+		URL:                os.Getenv("LDAP_URL"),
+		StartTLS:           os.Getenv("LDAP_START_TLS") == "true",
+		InsecureSkipVerify: os.Getenv("LDAP_INSECURE_SKIP_VERIFY") == "true",
+		BindDN:             os.Getenv("LDAP_BIND_DN"),
+		BindPassword:       os.Getenv("LDAP_BIND_PASSWORD"),
+		BaseDN:             os.Getenv("LDAP_BASE_DN"),
+		UserFilter:         os.Getenv("LDAP_USER_FILTER"),
+		UserDNTemplate:     os.Getenv("LDAP_USER_DN_TEMPLATE"),
+		EmailAttribute:     os.Getenv("LDAP_EMAIL_ATTRIBUTE"),
+		UsernameAttribute:  os.Getenv("LDAP_USERNAME_ATTRIBUTE"),
+		GroupAttribute:     os.Getenv("LDAP_GROUP_ATTRIBUTE"),
+		JITProvisioning:    os.Getenv("LDAP_JIT_PROVISIONING") != "false",
+	}
+
+	if config.UserFilter == "" {
+		config.UserFilter = "(mail=%s)"
+	}
+	if config.EmailAttribute == "" {
+		config.EmailAttribute = "mail"
+	}
+	if config.UsernameAttribute == "" {
+		config.UsernameAttribute = "cn"
+	}
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = "memberOf"
+	}
+
+	// group DNs contain commas, so the list is separated by semicolons
+	for _, group := range strings.Split(os.Getenv("LDAP_ADMIN_GROUPS"), ";") {
+		if group = strings.TrimSpace(group); group != "" {
+			config.AdminGroups = append(config.AdminGroups, group)
+		}
+	}
+
+	return config
+}
+
+// LDAPBind checks the password of a login against the directory. With a
+// UserDNTemplate the user is bound directly, otherwise the entry is searched
+// with the service account first and then bound with the user's password.
+func LDAPBind(config LDAPConfig, login string, password string) (LDAPEntry, error) {
+	// an empty password is an unauthenticated bind, which most servers accept
+	if login == "" || password == "" {
+		return LDAPEntry{}, ErrLDAPInvalidCredentials
+	}
+
+	conn, err := dialLDAP(config)
+	if err != nil {
+		return LDAPEntry{}, err
+	}
+	defer conn.Close()
+
+	attributes := []string{config.EmailAttribute, config.UsernameAttribute, config.GroupAttribute}
+
+	var entry *ldap.Entry
+	if config.UserDNTemplate != "" {
+		dn := fmt.Sprintf(config.UserDNTemplate, ldap.EscapeDN(login))
+		err = bindLDAP(conn, dn, password)
+		if err != nil {
+			return LDAPEntry{}, err
+		}
+
+		// read the entry as the user, it can always see itself
+		entry, err = searchLDAP(conn, ldap.NewSearchRequest(
+			dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false,
+			"(objectClass=*)", attributes, nil,
+		))
+		if err != nil {
+			return LDAPEntry{}, err
+		}
+	} else {
+		if config.BindDN != "" {
+			err = conn.Bind(config.BindDN, config.BindPassword)
+			if err != nil {
+				return LDAPEntry{}, fmt.Errorf("ldap service bind: %w", err)
+			}
+		}
+
+		// a size limit of 2 is enough to detect an ambiguous filter
+		entry, err = searchLDAP(conn, ldap.NewSearchRequest(
+			config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+			fmt.Sprintf(config.UserFilter, ldap.EscapeFilter(login)), attributes, nil,
+		))
+		if err != nil {
+			return LDAPEntry{}, err
+		}
+
+		err = bindLDAP(conn, entry.DN, password)
+		if err != nil {
+			return LDAPEntry{}, err
+		}
+	}
+
+	res := LDAPEntry{
+		DN:       entry.DN,
+		Email:    strings.ToLower(entry.GetAttributeValue(config.EmailAttribute)),
+		Username: entry.GetAttributeValue(config.UsernameAttribute),
+		Groups:   entry.GetAttributeValues(config.GroupAttribute),
+	}
+	if res.Email == "" && strings.Contains(login, "@") {
+		res.Email = strings.ToLower(login)
+	}
+	if res.Username == "" {
+		res.Username = login
+	}
+
+	return res, nil
+}
+
+// IsLDAPAdmin tells whether one of the groups of the entry is an admin group
+func IsLDAPAdmin(config LDAPConfig, entry LDAPEntry) bool {
+	for _, group := range entry.Groups {
+		for _, adminGroup := range config.AdminGroups {
+			if strings.EqualFold(group, adminGroup) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func dialLDAP(config LDAPConfig) (*ldap.Conn, error) {
+	if config.URL == "" {
+		return nil, errors.New("LDAP_URL is not set")
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	conn, err := ldap.DialURL(config.URL, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.StartTLS {
+		err = conn.StartTLS(tlsConfig)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func bindLDAP(conn *ldap.Conn, dn string, password string) error {
+	err := conn.Bind(dn, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return ErrLDAPInvalidCredentials
+	}
+	return err
+}
+
+// searchLDAP returns the single entry of the search, no match is reported as
+// invalid credentials so that unknown logins can not be told apart
+func searchLDAP(conn *ldap.Conn, req *ldap.SearchRequest) (*ldap.Entry, error) {
+	res, err := conn.Search(req)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, ErrLDAPInvalidCredentials
+	}
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, err
+	}
+
+	if res == nil || len(res.Entries) == 0 {
+		return nil, ErrLDAPInvalidCredentials
+	}
+	if len(res.Entries) > 1 {
+		return nil, errors.New("ldap user filter matches more than one entry")
+	}
+
+	return res.Entries[0], nil
+}
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"engine/pkg/shared/auth/ldaptest"
+)
+
+const (
+	testLDAPServiceDN = "cn=service,dc=acme,dc=test"
+	testLDAPUserDN    = "uid=jane,ou=people,dc=acme,dc=test"
+	testLDAPAdmins    = "cn=admins,ou=groups,dc=acme,dc=test"
+)
+
+func newTestDirectory(t *testing.T) *ldaptest.Server {
+	t.Helper()
+
+	server := ldaptest.NewServer(
+		ldaptest.Entry{DN: testLDAPServiceDN, Password: "service-secret"},
+		ldaptest.Entry{
+			DN:       testLDAPUserDN,
+			Password: "jane-secret",
+			Attributes: map[string][]string{
+				"mail":     {"Jane@Acme.test"},
+				"cn":       {"Jane Doe"},
+				"memberOf": {testLDAPAdmins, "cn=staff,ou=groups,dc=acme,dc=test"},
+			},
+		},
+		ldaptest.Entry{
+			DN:         "uid=john,ou=people,dc=acme,dc=test",
+			Password:   "john-secret",
+			Attributes: map[string][]string{"mail": {"john@acme.test"}},
+		},
+	)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// searchConfig finds the entry with the service account, templateConfig binds directly
+func searchConfig(server *ldaptest.Server) LDAPConfig {
+	return LDAPConfig{
+		URL:               server.URL,
+		BindDN:            testLDAPServiceDN,
+		BindPassword:      "service-secret",
+		BaseDN:            "ou=people,dc=acme,dc=test",
+		UserFilter:        "(mail=%s)",
+		EmailAttribute:    "mail",
+		UsernameAttribute: "cn",
+		GroupAttribute:    "memberOf",
+	}
+}
+
+func templateConfig(server *ldaptest.Server) LDAPConfig {
+	config := searchConfig(server)
+	config.UserDNTemplate = "uid=%s,ou=people,dc=acme,dc=test"
+	return config
+}
+
+func TestLDAPBind(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   func(server *ldaptest.Server) LDAPConfig
+		login    string
+		password string
+		wantErr  error
+	}{
+		{name: "search and bind", config: searchConfig, login: "jane@acme.test", password: "jane-secret"},
+		{name: "bind with a DN template", config: templateConfig, login: "jane", password: "jane-secret"},
+		{name: "search wrong password", config: searchConfig, login: "jane@acme.test", password: "wrong", wantErr: ErrLDAPInvalidCredentials},
+		{name: "template wrong password", config: templateConfig, login: "jane", password: "wrong", wantErr: ErrLDAPInvalidCredentials},
+		{name: "search empty password", config: searchConfig, login: "jane@acme.test", password: "", wantErr: ErrLDAPInvalidCredentials},
+		{name: "template empty password", config: templateConfig, login: "jane", password: "", wantErr: ErrLDAPInvalidCredentials},
+		{name: "unknown login", config: searchConfig, login: "nobody@acme.test", password: "jane-secret", wantErr: ErrLDAPInvalidCredentials},
+		{name: "unknown DN", config: templateConfig, login: "nobody", password: "jane-secret", wantErr: ErrLDAPInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestDirectory(t)
+
+			entry, err := LDAPBind(tt.config(server), tt.login, tt.password)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("LDAPBind() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LDAPBind() error = %v", err)
+			}
+
+			if entry.DN != testLDAPUserDN || entry.Email != "jane@acme.test" || entry.Username != "Jane Doe" {
+				t.Errorf("LDAPBind() = %+v", entry)
+			}
+			if len(entry.Groups) != 2 || entry.Groups[0] != testLDAPAdmins {
+				t.Errorf("LDAPBind() groups = %v", entry.Groups)
+			}
+		})
+	}
+}
+
+func TestLDAPBindEmptyPasswordNeverReachesTheDirectory(t *testing.T) {
+	server := newTestDirectory(t)
+
+	for _, config := range []LDAPConfig{searchConfig(server), templateConfig(server)} {
+		_, err := LDAPBind(config, "jane", "")
+		if !errors.Is(err, ErrLDAPInvalidCredentials) {
+			t.Fatalf("LDAPBind() error = %v, want %v", err, ErrLDAPInvalidCredentials)
+		}
+	}
+
+	// the stand-in accepts an unauthenticated bind, like real servers do
+	if binds := server.Binds(); len(binds) != 0 {
+		t.Errorf("directory received binds %v", binds)
+	}
+}
+
+func TestLDAPBindEscapesTheLogin(t *testing.T) {
+	t.Run("filter", func(t *testing.T) {
+		tests := []struct {
+			login      string
+			wantFilter string
+		}{
+			{login: "*", wantFilter: `(mail=\2a)`},
+			{login: "jane@acme.test)(mail=*", wantFilter: `(mail=jane@acme.test\29\28mail=\2a)`},
+			{login: `*)(|(mail=*`, wantFilter: `(mail=\2a\29\28|\28mail=\2a)`},
+			{login: `jane\2a`, wantFilter: `(mail=jane\5c2a)`},
+		}
+
+		for _, tt := range tests {
+			server := newTestDirectory(t)
+
+			_, err := LDAPBind(searchConfig(server), tt.login, "jane-secret")
+			if !errors.Is(err, ErrLDAPInvalidCredentials) {
+				t.Errorf("LDAPBind(%q) error = %v, want %v", tt.login, err, ErrLDAPInvalidCredentials)
+			}
+
+			filters := server.Filters()
+			if len(filters) != 1 || filters[0] != tt.wantFilter {
+				t.Errorf("LDAPBind(%q) searched %v, want %q", tt.login, filters, tt.wantFilter)
+			}
+		}
+	})
+
+	t.Run("DN", func(t *testing.T) {
+		tests := []struct {
+			login  string
+			wantDN string
+		}{
+			{login: "jane,ou=people", wantDN: `uid=jane\,ou=people,ou=people,dc=acme,dc=test`},
+			{login: "jane+cn=admin", wantDN: `uid=jane\+cn=admin,ou=people,dc=acme,dc=test`},
+			{login: " jane", wantDN: `uid=\ jane,ou=people,dc=acme,dc=test`},
+		}
+
+		for _, tt := range tests {
+			server := newTestDirectory(t)
+
+			_, err := LDAPBind(templateConfig(server), tt.login, "jane-secret")
+			if !errors.Is(err, ErrLDAPInvalidCredentials) {
+				t.Errorf("LDAPBind(%q) error = %v, want %v", tt.login, err, ErrLDAPInvalidCredentials)
+			}
+
+			binds := server.Binds()
+			if len(binds) != 1 || binds[0] != tt.wantDN {
+				t.Errorf("LDAPBind(%q) bound %v, want %q", tt.login, binds, tt.wantDN)
+			}
+		}
+	})
+}
+
+func TestLDAPBindAmbiguousFilter(t *testing.T) {
+	server := newTestDirectory(t)
+	config := searchConfig(server)
+	config.UserFilter = "(|(mail=%s)(mail=john@acme.test))"
+
+	_, err := LDAPBind(config, "jane@acme.test", "jane-secret")
+	if err == nil || errors.Is(err, ErrLDAPInvalidCredentials) {
+		t.Fatalf("LDAPBind() error = %v, want an ambiguous filter error", err)
+	}
+}
+
+func TestLDAPBindServiceAccount(t *testing.T) {
+	server := newTestDirectory(t)
+	config := searchConfig(server)
+	config.BindPassword = "wrong"
+
+	_, err := LDAPBind(config, "jane@acme.test", "jane-secret")
+	if err == nil || errors.Is(err, ErrLDAPInvalidCredentials) {
+		t.Fatalf("LDAPBind() error = %v, a broken service account is not the user's fault", err)
+	}
+}
+
+func TestIsLDAPAdmin(t *testing.T) {
+	tests := []struct {
+		name        string
+		adminGroups []string
+		groups      []string
+		want        bool
+	}{
+		{name: "member of an admin group", adminGroups: []string{testLDAPAdmins}, groups: []string{"cn=staff,dc=acme,dc=test", testLDAPAdmins}, want: true},
+		{name: "DN case differs", adminGroups: []string{testLDAPAdmins}, groups: []string{"CN=Admins,OU=Groups,DC=acme,DC=test"}, want: true},
+		{name: "not a member", adminGroups: []string{testLDAPAdmins}, groups: []string{"cn=staff,dc=acme,dc=test"}},
+		{name: "no groups", adminGroups: []string{testLDAPAdmins}},
+		{name: "no admin groups", groups: []string{testLDAPAdmins}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IsLDAPAdmin(LDAPConfig{AdminGroups: tt.adminGroups}, LDAPEntry{Groups: tt.groups})
+			if got != tt.want {
+				t.Errorf("IsLDAPAdmin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	signingKey     *rsa.PrivateKey
+	signingKeyID   string
+	signingKeyErr  error
+	signingKeyOnce sync.Once
+)
+
+// Issuer is the public base URL of the engine from OIDC_ISSUER, without a
+// trailing slash. It is the iss of every token and the audience of the engine API.
+func Issuer() string {
+	return strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/")
+}
+
+// JSONWebKey is the public part of the ID token signing key
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// CheckOIDCConfig is called on startup, every replica has to share the
+// issuer and the signing key or tokens stop validating between them
+func CheckOIDCConfig() error {
+	if Issuer() == "" {
+		return errors.New("OIDC_ISSUER is required")
+	}
+
+	_, _, err := loadSigningKey()
+	return err
+}
+
+// loadSigningKey reads the PEM key at OIDC_PRIVATE_KEY_PATH
+func loadSigningKey() (*rsa.PrivateKey, string, error) {
+	signingKeyOnce.Do(func() {
+		path := os.Getenv("OIDC_PRIVATE_KEY_PATH")
+		if path == "" {
+			signingKeyErr = errors.New("OIDC_PRIVATE_KEY_PATH is required")
+			return
+		}
+
+		signingKey, signingKeyErr = readRSAPrivateKey(path)
+		if signingKeyErr != nil {
+			return
+		}
+
+		sum := sha256.Sum256(signingKey.PublicKey.N.Bytes())
+		signingKeyID = base64.RawURLEncoding.EncodeToString(sum[:8])
+	})
+
+	return signingKey, signingKeyID, signingKeyErr
+}
+
+func readRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("OIDC_PRIVATE_KEY_PATH is not a PEM file")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("OIDC_PRIVATE_KEY_PATH is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// Generate RS256 JWT token, used for ID tokens that relying parties verify with the JWKS
+func GenerateRS256JWT(payload map[string]interface{}) (string, error) {
+	key, kid, err := loadSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{}
+	for k, val := range payload {
+		claims[k] = val
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
+}
+
+// ParseRS256JWT verifies the signature and the expiry of a token issued by GenerateRS256JWT
+func ParseRS256JWT(tokenString string) (jwt.MapClaims, error) {
+	key, _, err := loadSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return &key.PublicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("token is invalid")
+	}
+
+	return claims, nil
+}
+
+// PublicJWKS returns the keys published at the jwks_uri
+func PublicJWKS() ([]JSONWebKey, error) {
+	key, kid, err := loadSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return []JSONWebKey{{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+	}}, nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+)
+
+var (
+	samlKey     *rsa.PrivateKey
+	samlCert    *x509.Certificate
+	samlKeyErr  error
+	samlKeyOnce sync.Once
+)
+
+// LoadSAMLKeyPair reads the service provider key pair from SAML_SP_KEY_PATH and
+// SAML_SP_CERT_PATH. Both are optional, without them AuthnRequests are not
+// signed and encrypted assertions can not be read.
+func LoadSAMLKeyPair() (*rsa.PrivateKey, *x509.Certificate, error) {
+	samlKeyOnce.Do(func() {
+		keyPath := os.Getenv("SAML_SP_KEY_PATH")
+		certPath := os.Getenv("SAML_SP_CERT_PATH")
+		if keyPath == "" || certPath == "" {
+			return
+		}
+
+		pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			samlKeyErr = err
+			return
+		}
+
+		key, ok := pair.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			samlKeyErr = errors.New("SAML_SP_KEY_PATH is not an RSA key")
+			return
+		}
+
+		samlKey = key
+		samlCert, samlKeyErr = x509.ParseCertificate(pair.Certificate[0])
+	})
+
+	return samlKey, samlCert, samlKeyErr
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"os"
+)
+
+// GenerateOpaqueToken returns a random token starting with prefix and its hash.
+// Only the hash should be stored.
+func GenerateOpaqueToken(prefix string) (string, string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := prefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashOpaqueToken(token), nil
+}
+
+// HashOpaqueToken hashes a high entropy token, bcrypt is not needed for those
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateNumericCode returns a random code of the given number of digits,
+// short enough to be typed from an SMS
+func GenerateNumericCode(digits int) (string, error) {
+	code := make([]byte, digits)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+
+	return string(code), nil
+}
+
+// HashNumericCode keys the hash of a short code with JWT_SECRET_KEY and binds
+// it to subject, a plain hash of a few digits is reversed by trying them all
+func HashNumericCode(code string, subject string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET_KEY")))
+	mac.Write([]byte(subject + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,193 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"strconv"
+
+	"golang.org/x/image/draw"
+
+	// decoders of the accepted types, registered for image.Decode
+	_ "image/gif"
+
+	_ "golang.org/x/image/webp"
+)
+
+// Sizes are the square renditions made of every upload, the first one is the
+// avatar itself and the others its thumbnails
+var Sizes = []int{512, 128, 64}
+
+// ContentTypes are the accepted uploads, sniffed from the content
+var ContentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// MaxPixels bounds the decoded size of an upload, a small file can expand to
+// a huge image
+const MaxPixels = 40_000_000
+
+var (
+	// ErrUnsupportedType is returned for a file that is not an accepted image
+	ErrUnsupportedType = errors.New("unsupported image type, use JPEG, PNG, GIF or WebP")
+	// ErrInvalidImage is returned for a file that can not be decoded
+	ErrInvalidImage = errors.New("invalid image")
+	// ErrTooManyPixels is returned for an image larger than MaxPixels
+	ErrTooManyPixels = errors.New("image dimensions are too large")
+)
+
+// Rendition is one encoded size of an avatar
+type Rendition struct {
+	Size        int
+	Data        []byte
+	ContentType string
+	// Ext is the file extension, with the dot
+	Ext string
+}
+
+// Process turns an upload into the renditions of Sizes. The image is turned
+// upright following its EXIF orientation, cropped to a centered square and
+// encoded again, which drops the EXIF data and any other metadata. Opaque
+// images are encoded as JPEG, the others as PNG to keep transparency.
+func Process(data []byte) ([]Rendition, error) {
+	if !supported(http.DetectContentType(data)) {
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	// a centered square crop and scaling commute with the orientation, so it
+	// is applied last on the small renditions only
+	orientation := jpegOrientation(data)
+	img = cropSquare(img)
+	opaque := isOpaque(img)
+
+	renditions := make([]Rendition, 0, len(Sizes))
+	for _, size := range Sizes {
+		scaled := image.NewNRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+		dst := orient(scaled, orientation)
+
+		var buf bytes.Buffer
+		rendition := Rendition{Size: size}
+		if opaque {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+			rendition.ContentType, rendition.Ext = "image/jpeg", ".jpg"
+		} else {
+			err = png.Encode(&buf, dst)
+			rendition.ContentType, rendition.Ext = "image/png", ".png"
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rendition.Data = buf.Bytes()
+		renditions = append(renditions, rendition)
+	}
+
+	return renditions, nil
+}
+
+// Key is the blob key of the given size of an avatar, dir is unique per upload
+func Key(dir string, size int, ext string) string {
+	return dir + "/" + strconv.Itoa(size) + ext
+}
+
+// SizeKey returns the key of another size of the avatar stored at key
+func SizeKey(key string, size int) string {
+	return Key(path.Dir(key), size, path.Ext(key))
+}
+
+func supported(contentType string) bool {
+	for _, accepted := range ContentTypes {
+		if contentType == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+func cropSquare(img image.Image) image.Image {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	dst := image.NewNRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), img, image.Pt(x, y), draw.Src)
+
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// orient applies an EXIF orientation, 1 to 8, so that the image is upright
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		for dx := 0; dx < dw; dx++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-dx, dy
+			case 3:
+				sx, sy = w-1-dx, h-1-dy
+			case 4:
+				sx, sy = dx, h-1-dy
+			case 5:
+				sx, sy = dy, dx
+			case 6:
+				sx, sy = dy, h-1-dx
+			case 7:
+				sx, sy = w-1-dy, h-1-dx
+			case 8:
+				sx, sy = w-1-dy, dx
+			}
+			dst.Set(dx, dy, color.NRGBAModel.Convert(img.At(b.Min.X+sx, b.Min.Y+sy)))
+		}
+	}
+
+	return dst
+}
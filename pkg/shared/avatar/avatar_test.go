@@ -0,0 +1,197 @@
+package avatar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifSegment is an APP1 segment holding a little endian TIFF header with
+// the orientation tag only
+func exifSegment(orientation int) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// halvesJPEG is a square JPEG, red on the left half and blue on the right,
+// with the EXIF orientation inserted after the start of image
+func halvesJPEG(t *testing.T, orientation int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if x < 32 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if orientation == 0 {
+		return data
+	}
+
+	return append(append(append([]byte{}, data[:2]...), exifSegment(orientation)...), data[2:]...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	for orientation := 1; orientation <= 8; orientation++ {
+		if got := jpegOrientation(halvesJPEG(t, orientation)); got != orientation {
+			t.Errorf("jpegOrientation() = %d, want %d", got, orientation)
+		}
+	}
+
+	if got := jpegOrientation(halvesJPEG(t, 0)); got != 0 {
+		t.Errorf("jpegOrientation() without EXIF = %d, want 0", got)
+	}
+
+	// broken files have no orientation rather than a panic
+	truncated := halvesJPEG(t, 6)[:20]
+	for _, data := range [][]byte{nil, {0xFF, 0xD8}, truncated, []byte("\x89PNG\r\n\x1a\n")} {
+		if got := jpegOrientation(data); got != 0 {
+			t.Errorf("jpegOrientation(%q) = %d, want 0", data, got)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// a 3x2 image with its top left pixel marked, the orientations 5 to 8
+	// swap the axes
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(0, 0, color.NRGBA{R: 255, A: 255})
+
+	tests := []struct {
+		orientation int
+		width       int
+		height      int
+		marked      image.Point
+	}{
+		{orientation: 1, width: 3, height: 2, marked: image.Pt(0, 0)},
+		{orientation: 2, width: 3, height: 2, marked: image.Pt(2, 0)},
+		{orientation: 3, width: 3, height: 2, marked: image.Pt(2, 1)},
+		{orientation: 4, width: 3, height: 2, marked: image.Pt(0, 1)},
+		{orientation: 5, width: 2, height: 3, marked: image.Pt(0, 0)},
+		{orientation: 6, width: 2, height: 3, marked: image.Pt(1, 0)},
+		{orientation: 7, width: 2, height: 3, marked: image.Pt(1, 2)},
+		{orientation: 8, width: 2, height: 3, marked: image.Pt(0, 2)},
+		{orientation: 9, width: 3, height: 2, marked: image.Pt(0, 0)},
+	}
+
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation)
+		if b := dst.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("orient(%d) is %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.width, tt.height)
+			continue
+		}
+		if _, _, _, a := dst.At(tt.marked.X, tt.marked.Y).RGBA(); a == 0 {
+			t.Errorf("orient(%d) moved the top left pixel away from %v", tt.orientation, tt.marked)
+		}
+	}
+}
+
+func TestProcessOrientsAndStripsEXIF(t *testing.T) {
+	red := func(c color.Color) bool {
+		r, _, b, _ := c.RGBA()
+		return r > b
+	}
+
+	tests := []struct {
+		orientation int
+		// where the red half of the file ends up once upright
+		topLeftRed    bool
+		bottomLeftRed bool
+		topRightRed   bool
+	}{
+		{orientation: 0, topLeftRed: true, bottomLeftRed: true, topRightRed: false},
+		{orientation: 1, topLeftRed: true, bottomLeftRed: true, topRightRed: false},
+		{orientation: 3, topLeftRed: false, bottomLeftRed: false, topRightRed: true},
+		{orientation: 6, topLeftRed: true, bottomLeftRed: false, topRightRed: true},
+		{orientation: 8, topLeftRed: false, bottomLeftRed: true, topRightRed: false},
+	}
+
+	for _, tt := range tests {
+		renditions, err := Process(halvesJPEG(t, tt.orientation))
+		if err != nil {
+			t.Fatalf("Process(%d) error = %v", tt.orientation, err)
+		}
+		if len(renditions) != len(Sizes) {
+			t.Fatalf("Process(%d) made %d renditions", tt.orientation, len(renditions))
+		}
+
+		for _, rendition := range renditions {
+			if rendition.ContentType != "image/jpeg" || jpegOrientation(rendition.Data) != 0 || bytes.Contains(rendition.Data, []byte("Exif")) {
+				t.Errorf("Process(%d) kept the EXIF data of the %d rendition", tt.orientation, rendition.Size)
+			}
+
+			img, err := jpeg.Decode(bytes.NewReader(rendition.Data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			size := rendition.Size
+			if b := img.Bounds(); b.Dx() != size || b.Dy() != size {
+				t.Errorf("rendition %d is %dx%d", size, b.Dx(), b.Dy())
+			}
+			if red(img.At(2, 2)) != tt.topLeftRed || red(img.At(2, size-3)) != tt.bottomLeftRed || red(img.At(size-3, 2)) != tt.topRightRed {
+				t.Errorf("Process(%d) is not upright in the %d rendition", tt.orientation, size)
+			}
+		}
+	}
+}
+
+func TestProcessMaxPixels(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+
+	// the header of a small file can claim any size, it is checked before
+	// anything is decoded
+	withSize := func(width, height uint32) []byte {
+		data := append([]byte{}, buf.Bytes()...)
+		binary.BigEndian.PutUint32(data[16:20], width)
+		binary.BigEndian.PutUint32(data[20:24], height)
+		binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+		return data
+	}
+
+	if _, err := Process(withSize(8000, 5001)); err != ErrTooManyPixels {
+		t.Errorf("Process() of %d pixels error = %v, want %v", 8000*5001, err, ErrTooManyPixels)
+	}
+	if _, err := Process(withSize(100000, 100000)); err != ErrTooManyPixels {
+		t.Errorf("Process() of a huge image error = %v, want %v", err, ErrTooManyPixels)
+	}
+	if _, err := Process(withSize(8, 8)); err != nil {
+		t.Errorf("Process() of a small image error = %v", err)
+	}
+}
+
+func TestProcessRejectsOtherFiles(t *testing.T) {
+	if _, err := Process([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>")); err != ErrUnsupportedType {
+		t.Errorf("Process() of an SVG error = %v, want %v", err, ErrUnsupportedType)
+	}
+	if _, err := Process(halvesJPEG(t, 0)[:200]); err != ErrInvalidImage {
+		t.Errorf("Process() of a truncated JPEG error = %v, want %v", err, ErrInvalidImage)
+	}
+}
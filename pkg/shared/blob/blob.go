@@ -0,0 +1,114 @@
+package blob
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned for a key without blob
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey is returned for a key that is empty, absolute or climbs out
+// of the store with ".."
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// ErrNoSigningKey is returned by a local store without BLOB_SIGNING_KEY
+var ErrNoSigningKey = errors.New("BLOB_SIGNING_KEY is required")
+
+// BlobStore keeps files by key, such as avatars/12/3f9c/512.jpg. Blobs are
+// private, they are only served through signed URLs that expire.
+// Implementations must be safe for concurrent use.
+type BlobStore interface {
+	Put(key string, data []byte, contentType string) error
+	// Delete does nothing when the key does not exist
+	Delete(key string) error
+	// SignedURL returns a URL that serves the blob until ttl has elapsed
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+var (
+	defaultStore BlobStore
+	defaultOnce  sync.Once
+)
+
+// Default returns the store selected by BLOB_DRIVER
+func Default() BlobStore {
+	defaultOnce.Do(func() {
+		defaultStore = NewFromEnv()
+	})
+
+	return defaultStore
+}
+
+// NewFromEnv builds the store of BLOB_DRIVER: local (default) or s3
+func NewFromEnv() BlobStore {
+	switch driver := os.Getenv("BLOB_DRIVER"); driver {
+	case "", "local":
+		return NewLocalStore(LoadLocalConfig())
+	case "s3":
+		return NewS3Store(LoadS3Config())
+	default:
+		return &unknownStore{driver: driver}
+	}
+}
+
+// CheckConfig reports a BLOB_DRIVER that cannot work. The local store signs
+// its URLs with its own BLOB_SIGNING_KEY, never with the secret of the JWTs.
+func CheckConfig() error {
+	switch driver := os.Getenv("BLOB_DRIVER"); driver {
+	case "", "local":
+		key := os.Getenv("BLOB_SIGNING_KEY")
+		if key == "" {
+			return ErrNoSigningKey
+		}
+		if key == os.Getenv("JWT_SECRET_KEY") {
+			return errors.New("BLOB_SIGNING_KEY must differ from JWT_SECRET_KEY")
+		}
+	case "s3":
+		if os.Getenv("S3_BUCKET") == "" {
+			return errors.New("S3_BUCKET is required")
+		}
+	default:
+		return errors.New("unknown BLOB_DRIVER " + driver)
+	}
+
+	return nil
+}
+
+// checkKey rejects keys that could escape the store or the bucket prefix
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+
+	return nil
+}
+
+// unknownStore stands for an unsupported BLOB_DRIVER, so that a typo fails loudly
+type unknownStore struct {
+	driver string
+}
+
+func (us *unknownStore) Put(key string, data []byte, contentType string) error {
+	return us.err()
+}
+
+func (us *unknownStore) Delete(key string) error {
+	return us.err()
+}
+
+func (us *unknownStore) SignedURL(key string, ttl time.Duration) (string, error) {
+	return "", us.err()
+}
+
+func (us *unknownStore) err() error {
+	return errors.New("unknown BLOB_DRIVER " + us.driver)
+}
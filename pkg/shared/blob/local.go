@@ -0,0 +1,171 @@
+package blob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalConfig configures LocalStore. URL is where ServeHTTP is mounted, for
+// example https://api.example.com/api/blobs/
+type LocalConfig struct {
+	Dir        string
+	URL        string
+	SigningKey string
+}
+
+// LoadLocalConfig reads BLOB_LOCAL_DIR, BLOB_LOCAL_URL and BLOB_SIGNING_KEY
+func LoadLocalConfig() LocalConfig {
+	config := LocalConfig{
+		Dir:        os.Getenv("BLOB_LOCAL_DIR"),
+		URL:        os.Getenv("BLOB_LOCAL_URL"),
+		SigningKey: os.Getenv("BLOB_SIGNING_KEY"),
+	}
+	if config.Dir == "" {
+		config.Dir = "storage/blobs"
+	}
+	if config.URL == "" {
+		config.URL = "/api/blobs/"
+	}
+
+	return config
+}
+
+// LocalStore keeps blobs on the filesystem. It is also the http.Handler of
+// its signed URLs, mounted under Config.URL with the prefix stripped.
+type LocalStore struct {
+	Config LocalConfig
+}
+
+func NewLocalStore(config LocalConfig) *LocalStore {
+	return &LocalStore{
+		Config: config,
+	}
+}
+
+func (ls *LocalStore) Put(key string, data []byte, contentType string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// written aside then renamed, a reader never sees a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (ls *LocalStore) Delete(key string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// drops the directory of the key once it is empty, it fails otherwise
+	_ = os.Remove(filepath.Dir(path))
+
+	return nil
+}
+
+func (ls *LocalStore) SignedURL(key string, ttl time.Duration) (string, error) {
+	err := checkKey(key)
+	if err != nil {
+		return "", err
+	}
+	if ls.Config.SigningKey == "" {
+		return "", ErrNoSigningKey
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {ls.sign(key, expires)},
+	}
+
+	return strings.TrimSuffix(ls.Config.URL, "/") + "/" + key + "?" + query.Encode(), nil
+}
+
+// ServeHTTP serves the blob of the request path when the signature of the
+// URL is valid and has not expired
+func (ls *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	expires := r.URL.Query().Get("expires")
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt || ls.Config.SigningKey == "" ||
+		!hmac.Equal([]byte(ls.sign(key, expires)), []byte(r.URL.Query().Get("signature"))) {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	path, err := ls.path(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// cached by the browser at most until the URL expires
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(expiresAt-time.Now().Unix(), 10))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+func (ls *LocalStore) path(key string) (string, error) {
+	err := checkKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(ls.Config.Dir, filepath.FromSlash(key)), nil
+}
+
+func (ls *LocalStore) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, []byte(ls.Config.SigningKey))
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
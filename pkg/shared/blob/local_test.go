@@ -0,0 +1,160 @@
+package blob
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStore(t *testing.T) *LocalStore {
+	t.Helper()
+
+	ls := NewLocalStore(LocalConfig{Dir: t.TempDir(), URL: "/api/blobs/", SigningKey: "blob-secret"})
+	if err := ls.Put("avatars/1/abc/512.jpg", []byte("avatar"), "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	return ls
+}
+
+// serve requests a URL of SignedURL the way the router mounts the store,
+// with the URL prefix stripped
+func serve(ls *LocalStore, rawURL string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, rawURL, nil)
+	rec := httptest.NewRecorder()
+	http.StripPrefix("/api/blobs", ls).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSignedURLServesTheBlob(t *testing.T) {
+	ls := newTestLocalStore(t)
+
+	signedURL, err := ls.SignedURL("avatars/1/abc/512.jpg", time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL() error = %v", err)
+	}
+
+	rec := serve(ls, signedURL)
+	if rec.Code != http.StatusOK || rec.Body.String() != "avatar" {
+		t.Fatalf("ServeHTTP() = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServeHTTPRejectsBadSignatures(t *testing.T) {
+	ls := newTestLocalStore(t)
+	signedURL, err := ls.SignedURL("avatars/1/abc/512.jpg", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := ls.SignedURL("avatars/1/abc/512.jpg", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withQuery := func(name, value string) string {
+		u, _ := url.Parse(signedURL)
+		query := u.Query()
+		query.Set(name, value)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	otherKey := NewLocalStore(LocalConfig{Dir: ls.Config.Dir, URL: "/api/blobs/", SigningKey: "another-secret"})
+	forged, err := otherKey.SignedURL("avatars/1/abc/512.jpg", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "expired", url: expired},
+		{name: "later expiry", url: withQuery("expires", "99999999999")},
+		{name: "tampered signature", url: withQuery("signature", strings.Repeat("0", 64))},
+		{name: "no signature", url: withQuery("signature", "")},
+		{name: "other blob", url: strings.Replace(signedURL, "512.jpg", "128.jpg", 1)},
+		{name: "signed with another key", url: forged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serve(ls, tt.url); rec.Code != http.StatusForbidden {
+				t.Errorf("ServeHTTP() = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestServeHTTPWithoutSigningKey(t *testing.T) {
+	ls := newTestLocalStore(t)
+	ls.Config.SigningKey = ""
+
+	if _, err := ls.SignedURL("avatars/1/abc/512.jpg", time.Minute); err != ErrNoSigningKey {
+		t.Errorf("SignedURL() error = %v, want %v", err, ErrNoSigningKey)
+	}
+
+	// an URL signed with an empty key must not be accepted either
+	unsigned := NewLocalStore(LocalConfig{URL: "/api/blobs/"})
+	expires := "99999999999"
+	rawURL := "/api/blobs/avatars/1/abc/512.jpg?expires=" + expires + "&signature=" + unsigned.sign("avatars/1/abc/512.jpg", expires)
+	if rec := serve(ls, rawURL); rec.Code != http.StatusForbidden {
+		t.Errorf("ServeHTTP() = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestTraversalKeys(t *testing.T) {
+	ls := newTestLocalStore(t)
+	keys := []string{"", "../secret", "avatars/../../secret", "/etc/passwd", "avatars\\..\\secret", "avatars//512.jpg", "avatars/./512.jpg", "avatars/.."}
+
+	for _, key := range keys {
+		if _, err := ls.SignedURL(key, time.Minute); err != ErrInvalidKey {
+			t.Errorf("SignedURL(%q) error = %v, want %v", key, err, ErrInvalidKey)
+		}
+		if err := ls.Put(key, []byte("x"), "text/plain"); err != ErrInvalidKey {
+			t.Errorf("Put(%q) error = %v, want %v", key, err, ErrInvalidKey)
+		}
+		if err := ls.Delete(key); err != ErrInvalidKey {
+			t.Errorf("Delete(%q) error = %v, want %v", key, err, ErrInvalidKey)
+		}
+
+		// a valid signature of a traversal key still serves nothing
+		expires := "99999999999"
+		rawURL := "/api/blobs/" + key + "?expires=" + expires + "&signature=" + ls.sign(key, expires)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL, _ = url.Parse(rawURL)
+		rec := httptest.NewRecorder()
+		http.StripPrefix("/api/blobs", ls).ServeHTTP(rec, req)
+		if rec.Code == http.StatusOK {
+			t.Errorf("ServeHTTP(%q) = %d", key, rec.Code)
+		}
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "local", env: map[string]string{"BLOB_DRIVER": "local", "BLOB_SIGNING_KEY": "blob-secret", "JWT_SECRET_KEY": "jwt-secret"}},
+		{name: "default without signing key", env: map[string]string{"BLOB_DRIVER": "", "BLOB_SIGNING_KEY": "", "JWT_SECRET_KEY": "jwt-secret"}, wantErr: true},
+		{name: "signing key of the JWTs", env: map[string]string{"BLOB_DRIVER": "local", "BLOB_SIGNING_KEY": "jwt-secret", "JWT_SECRET_KEY": "jwt-secret"}, wantErr: true},
+		{name: "s3", env: map[string]string{"BLOB_DRIVER": "s3", "S3_BUCKET": "avatars", "BLOB_SIGNING_KEY": ""}},
+		{name: "s3 without bucket", env: map[string]string{"BLOB_DRIVER": "s3", "S3_BUCKET": ""}, wantErr: true},
+		{name: "unknown driver", env: map[string]string{"BLOB_DRIVER": "ftp"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if err := CheckConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("CheckConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package blob
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedBody    = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+	s3DateFormat      = "20060102"
+	s3MaxSignedURLTTL = 7 * 24 * time.Hour
+)
+
+// S3Config configures S3Store. Endpoint is the S3 API of the provider, such
+// as https://s3.eu-west-1.amazonaws.com or http://localhost:9000 for MinIO.
+// PathStyle puts the bucket in the path instead of the host name.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool
+}
+
+// LoadS3Config reads the S3_* variables
+func LoadS3Config() S3Config {
+	config := S3Config{
+		Endpoint:        os.Getenv("S3_ENDPOINT"),
+		Region:          os.Getenv("S3_REGION"),
+		Bucket:          os.Getenv("S3_BUCKET"),
+		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+	}
+	config.PathStyle, _ = strconv.ParseBool(os.Getenv("S3_PATH_STYLE"))
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+
+	return config
+}
+
+// S3Store keeps blobs in a bucket of any S3-compatible service. Requests are
+// signed with AWS Signature Version 4, signed URLs are presigned GET requests.
+type S3Store struct {
+	Config S3Config
+	Client *http.Client
+}
+
+func NewS3Store(config S3Config) *S3Store {
+	return &S3Store{
+		Config: config,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (ss *S3Store) Put(key string, data []byte, contentType string) error {
+	return ss.do(http.MethodPut, key, data, map[string]string{
+		"Content-Type": contentType,
+	})
+}
+
+func (ss *S3Store) Delete(key string) error {
+	// S3 answers 204 for a missing key too
+	return ss.do(http.MethodDelete, key, nil, nil)
+}
+
+func (ss *S3Store) SignedURL(key string, ttl time.Duration) (string, error) {
+	u, err := ss.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	if ttl > s3MaxSignedURLTTL {
+		ttl = s3MaxSignedURLTTL
+	}
+
+	now := time.Now().UTC()
+	query := url.Values{
+		"X-Amz-Algorithm":     {s3Algorithm},
+		"X-Amz-Credential":    {ss.Config.AccessKeyID + "/" + ss.scope(now)},
+		"X-Amz-Date":          {now.Format(s3TimeFormat)},
+		"X-Amz-Expires":       {strconv.Itoa(int(ttl / time.Second))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	u.RawQuery = canonicalQuery(query)
+
+	signature := ss.signature(now, http.MethodGet, u, map[string]string{"host": u.Host}, s3UnsignedBody)
+	u.RawQuery += "&X-Amz-Signature=" + signature
+
+	return u.String(), nil
+}
+
+func (ss *S3Store) do(method string, key string, body []byte, headers map[string]string) error {
+	u, err := ss.objectURL(key)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	signed := map[string]string{
+		"host":                 u.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           now.Format(s3TimeFormat),
+	}
+	for name, value := range headers {
+		signed[strings.ToLower(name)] = value
+	}
+	for name, value := range signed {
+		if name != "host" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	signature := ss.signature(now, method, u, signed, payloadHash)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, ss.Config.AccessKeyID, ss.scope(now), signedHeaderNames(signed), signature))
+
+	res, err := ss.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("s3 %s %s: %s %s", method, key, res.Status, strings.TrimSpace(string(detail)))
+	}
+
+	return nil
+}
+
+func (ss *S3Store) objectURL(key string) (*url.URL, error) {
+	err := checkKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(ss.Config.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if ss.Config.PathStyle {
+		u.Path += "/" + ss.Config.Bucket + "/" + key
+	} else {
+		u.Host = ss.Config.Bucket + "." + u.Host
+		u.Path += "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+
+	return u, nil
+}
+
+func (ss *S3Store) scope(t time.Time) string {
+	return t.Format(s3DateFormat) + "/" + ss.Config.Region + "/s3/aws4_request"
+}
+
+// signature computes the SigV4 signature of a request, headers holds the
+// signed headers with lowercase names
+func (ss *S3Store) signature(t time.Time, method string, u *url.URL, headers map[string]string, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	names := strings.Split(signedHeaderNames(headers), ";")
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		u.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(names, ";"),
+		payloadHash,
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format(s3TimeFormat),
+		ss.scope(t),
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+ss.Config.SecretAccessKey), t.Format(s3DateFormat))
+	key = hmacSHA256(key, ss.Config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func signedHeaderNames(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ";")
+}
+
+// canonicalQuery encodes values sorted by name, with %20 for spaces as SigV4 wants
+func canonicalQuery(values url.Values) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range values[name] {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// uriEncode escapes everything but the unreserved characters of RFC 3986,
+// slashes are kept unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -1,7 +1,248 @@
 package constants
 
+import "time"
+
 const (
 	DateFormat        = "2006-01-02"
 	DateTimeFormat    = "2006-01-02 15:04:05"
 	OauthGoogleUrlAPI = "https://www.googleapis.com/oauth2/v2/userinfo?access_token="
+
+	// SignInProviderGoogle is the provider of the Google sign in in the audit log
+	SignInProviderGoogle = "google"
+)
+
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Sign in backends, listed in AUTH_BACKENDS and combined with AUTH_CHAIN_MODE
+const (
+	AuthBackendLocal = "local"
+	AuthBackendLDAP  = "ldap"
+	AuthBackendHTTP  = "http"
+
+	AuthChainFirstSuccess = "first_success"
+	AuthChainRequireAll   = "require_all"
+
+	AuthHTTPHookTimeout = 5 * time.Second
+
+	// AuthBackendMetrics is the expvar map of the per backend sign in counters
+	AuthBackendMetrics = "auth_backends"
+)
+
+// Sign in error codes, returned as error_code whatever backend failed
+const (
+	AuthErrorInvalidCredentials    = 1001
+	AuthErrorPasswordResetRequired = 1002
+	AuthErrorUserSuspended         = 1003
+	AuthErrorUserNotActive         = 1004
+	AuthErrorUserNotProvisioned    = 1005
+	AuthErrorBackendUnavailable    = 1006
+	AuthErrorSMSCodeRequired       = 1007
+	AuthErrorUserDeprovisioned     = 1008
+)
+
+// Keys of values stored in gin.Context by the middleware
+const (
+	ContextClaimsKey    = "claims"
+	ContextUserIDKey    = "user_id"
+	ContextSessionIDKey = "session_id"
+	ContextScopesKey    = "scopes"
+	ContextClientIDKey  = "client_id"
+	ContextOrgIDKey     = "organization_id"
+)
+
+// Personal access tokens
+const (
+	PersonalAccessTokenPrefix      = "egn_pat_"
+	DefaultPersonalAccessTokenDays = 90
+	ScopeRead                      = "read"
+	ScopeWrite                     = "write"
+	ScopeAdmin                     = "admin"
+)
+
+// Session settings
+const (
+	AccessTokenTTL       = time.Hour * 24
+	SessionTouchInterval = time.Minute
+	NotMeLinkTTL         = time.Hour * 24 * 7
+	EmailChangeLinkTTL   = time.Hour
+	EmailChangeUndoTTL   = time.Hour * 24 * 7
+	VerifyEmailLinkTTL   = time.Minute * 15
+	ResetPasswordLinkTTL = time.Minute * 15
+	// a session younger than this counts as a step-up instead of the current password
+	StepUpWindow = time.Minute * 5
+)
+
+// Values of the "typ" claim, only access tokens are accepted by the middleware
+const (
+	TokenTypeAccess   = "access"
+	TokenPurposeNotMe = "not_me"
+
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+
+	TokenPurposeEmailChange     = "email_change"
+	TokenPurposeEmailChangeUndo = "email_change_undo"
+	TokenPurposeSMSSignIn       = "sms_sign_in"
+)
+
+// OAuth2 authorization server
+const (
+	OAuthClientIDPrefix     = "egn_client_"
+	OAuthClientSecretPrefix = "egn_cs_"
+	OAuthRefreshTokenPrefix = "egn_rt_"
+
+	OAuthCodeTTL         = 5 * time.Minute
+	OAuthRefreshTokenTTL = 30 * 24 * time.Hour
+
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// ClientSubjectPrefix starts the sub of tokens issued to a client, not a user
+	ClientSubjectPrefix       = "client:"
+	ClientCredentialsTokenTTL = time.Hour
+
+	PKCEMethodS256  = "S256"
+	TokenTypeBearer = "Bearer"
+
+	// OpenID Connect scopes
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+
+	IDTokenTTL = time.Hour
+
+	// Device authorization grant, the user code alphabet has no vowels so no words are formed
+	DeviceCodeTTL          = 10 * time.Minute
+	DeviceCodePollInterval = 5
+	DeviceUserCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	DeviceUserCodeLength   = 8
+)
+
+// SAML service provider
+const (
+	SAMLBindingRedirect = "redirect"
+	SAMLBindingPost     = "post"
+	SAMLRequestTTL      = 10 * time.Minute
+
+	OrganizationRoleMember = "member"
+)
+
+// SCIM 2.0 provisioning
+const (
+	SCIMTokenPrefix = "egn_scim_"
+
+	SCIMSchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	SCIMContentType     = "application/scim+json"
+	SCIMDefaultPageSize = 100
+	SCIMMaxPageSize     = 200
+)
+
+// Audit event actions
+const (
+	AuditActionSignUp         = "auth.signup"
+	AuditActionSignIn         = "auth.signin"
+	AuditActionAuthenticate   = "auth.authenticate"
+	AuditActionForgotPassword = "auth.forgot_password"
+	AuditActionActivateUser   = "auth.activate_user"
+	AuditActionResetPassword  = "auth.reset_password"
+	AuditActionNotMe          = "auth.not_me"
+	AuditActionSAMLSignIn     = "auth.saml_signin"
+	AuditActionSMSSignIn      = "auth.sms_signin"
+
+	AuditActionPasswordChanged      = "account.password_changed"
+	AuditActionTokenCreated         = "account.token_created"
+	AuditActionTokenRevoked         = "account.token_revoked"
+	AuditActionEmailChangeRequested = "account.email_change_requested"
+	AuditActionEmailChanged         = "account.email_changed"
+	AuditActionEmailChangeUndone    = "account.email_change_undone"
+	AuditActionPhoneChangeRequested = "account.phone_change_requested"
+	AuditActionPhoneVerified        = "account.phone_verified"
+	AuditActionSMSTwoFactorChanged  = "account.sms_two_factor_changed"
+	AuditActionProfileUpdated       = "account.profile_updated"
+	AuditActionAvatarUploaded       = "account.avatar_uploaded"
+	AuditActionAvatarDeleted        = "account.avatar_deleted"
+)
+
+// Audit event outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// Pagination defaults
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Email templates, see pkg/shared/template/emails
+const (
+	EmailTemplateVerifyEmail       = "verify_email"
+	EmailTemplateForgotPassword    = "forgot_password"
+	EmailTemplatePasswordChanged   = "password_changed"
+	EmailTemplateChangeEmail       = "change_email"
+	EmailTemplateEmailChangeNotice = "email_change_notice"
+	EmailTemplateNewDevice         = "new_device"
+)
+
+// Email outbox
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSent    = "sent"
+	EmailStatusDead    = "dead"
+
+	EmailOutboxPollInterval = 10 * time.Second
+	EmailOutboxBatchSize    = 50
+	EmailOutboxLockTTL      = 5 * time.Minute
+	EmailOutboxMaxAttempts  = 8
+	EmailOutboxBaseBackoff  = 30 * time.Second
+	EmailOutboxMaxBackoff   = 6 * time.Hour
+)
+
+// Types of the custom user attributes declared by organizations
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeEnum    = "enum"
+)
+
+// UserMetadataMaxBytes caps the encoded metadata of a user
+const UserMetadataMaxBytes = 16 << 10
+
+// Avatar uploads, the signed URLs of the renditions are made per response
+const (
+	AvatarMaxBytes = 5 << 20
+	AvatarURLTTL   = time.Hour
+)
+
+// SMS one-time codes, the hourly limit applies per user and per number
+const (
+	PhoneOTPPurposeVerify = "verify_phone"
+	PhoneOTPPurposeSignIn = "sign_in"
+
+	PhoneOTPDigits         = 6
+	PhoneOTPTTL            = 5 * time.Minute
+	PhoneOTPMaxAttempts    = 5
+	PhoneOTPResendInterval = time.Minute
+	PhoneOTPMaxPerHour     = 5
+)
+
+// Bounce and complaint webhook, the event type is also the suppression reason
+const (
+	EmailEventBounce    = "bounce"
+	EmailEventComplaint = "complaint"
+	EmailBounceHard     = "hard"
+	EmailBounceSoft     = "soft"
 )
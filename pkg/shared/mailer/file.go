@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileMailer is a local sink for development. When Path is a directory every
+// message is written to its own .eml file, otherwise messages are appended
+// to Path in mbox format.
+type FileMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func NewFileMailer(path string) Mailer {
+	return &FileMailer{
+		Path: path,
+	}
+}
+
+func (fm *FileMailer) Send(msg Message) error {
+	err := msg.validate()
+	if err != nil {
+		return err
+	}
+
+	if fm.Path == "" {
+		return errors.New("MAIL_FILE_PATH is not set")
+	}
+
+	var body bytes.Buffer
+	_, err = msg.WriteTo(&body)
+	if err != nil {
+		return err
+	}
+
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if info, err := os.Stat(fm.Path); err == nil && info.IsDir() {
+		name := fmt.Sprintf("%d.eml", time.Now().UnixNano())
+		return os.WriteFile(filepath.Join(fm.Path, name), body.Bytes(), 0o600)
+	}
+
+	return fm.appendMbox(msg, body.Bytes())
+}
+
+// appendMbox writes the mboxrd From_ line and quotes body lines starting with From
+func (fm *FileMailer) appendMbox(msg Message, body []byte) error {
+	file, err := os.OpenFile(fm.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "From %s %s\n", msg.sender(), time.Now().UTC().Format(time.ANSIC))
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), len(body)+1)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			line = ">" + line
+		}
+		fmt.Fprintln(w, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+
+	return w.Flush()
+}
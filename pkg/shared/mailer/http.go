@@ -0,0 +1,99 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPMailer sends through a SendGrid v3 compatible mail/send API
+type HTTPMailer struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+type httpMailAddress struct {
+	Email string `json:"email"`
+}
+
+type httpMailContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type httpMailPersonalization struct {
+	To []httpMailAddress `json:"to"`
+}
+
+type httpMailRequest struct {
+	Personalizations []httpMailPersonalization `json:"personalizations"`
+	From             httpMailAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []httpMailContent         `json:"content"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+func NewHTTPMailer(url string, apiKey string) Mailer {
+	if url == "" {
+		url = "https://api.sendgrid.com/v3/mail/send"
+	}
+
+	return &HTTPMailer{
+		URL:    url,
+		APIKey: apiKey,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (hm *HTTPMailer) Send(msg Message) error {
+	err := msg.validate()
+	if err != nil {
+		return err
+	}
+
+	// text/plain must come first in the content list
+	content := []httpMailContent{}
+	if msg.TextBody != "" {
+		content = append(content, httpMailContent{Type: "text/plain", Value: msg.TextBody})
+	}
+	if msg.HTMLBody != "" {
+		content = append(content, httpMailContent{Type: "text/html", Value: msg.HTMLBody})
+	}
+
+	body, err := json.Marshal(httpMailRequest{
+		Personalizations: []httpMailPersonalization{{
+			To: []httpMailAddress{{Email: msg.To}},
+		}},
+		From:    httpMailAddress{Email: msg.sender()},
+		Subject: msg.Subject,
+		Content: content,
+		Headers: msg.Headers,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, hm.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+hm.APIKey)
+
+	resp, err := hm.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("mail api answered %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+
+	return nil
+}
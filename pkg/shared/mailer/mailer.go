@@ -0,0 +1,114 @@
+package mailer
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Message is one email, TextBody is the text/plain alternative of HTMLBody
+type Message struct {
+	From     string
+	To       string
+	Subject  string
+	HTMLBody string
+	TextBody string
+	Headers  map[string]string
+}
+
+// Mailer delivers messages, implementations must be safe for concurrent use
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var (
+	defaultMailer Mailer
+	defaultOnce   sync.Once
+)
+
+// Default returns the mailer selected by MAIL_DRIVER. It is shared by all
+// usecases so that SMTP connections are pooled across requests.
+func Default() Mailer {
+	defaultOnce.Do(func() {
+		defaultMailer = NewFromEnv()
+	})
+
+	return defaultMailer
+}
+
+// NewFromEnv builds the mailer of MAIL_DRIVER: smtp (default), http, file or memory
+func NewFromEnv() Mailer {
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "", "smtp":
+		return NewSMTPMailer(LoadSMTPConfig())
+	case "http":
+		return NewHTTPMailer(os.Getenv("MAIL_HTTP_URL"), os.Getenv("MAIL_HTTP_API_KEY"))
+	case "file":
+		return NewFileMailer(os.Getenv("MAIL_FILE_PATH"))
+	case "memory":
+		return NewMemoryMailer()
+	default:
+		return &unknownMailer{driver: driver}
+	}
+}
+
+// DefaultFrom is the sender used when a message has none
+func DefaultFrom() string {
+	if from := os.Getenv("MAIL_FROM"); from != "" {
+		return from
+	}
+	return os.Getenv("ENGINE_EMAIL")
+}
+
+// WriteTo writes the message in MIME format, with a multipart/alternative
+// body when both parts are set
+func (msg Message) WriteTo(w io.Writer) (int64, error) {
+	m := gomail.NewMessage()
+	m.SetHeader("From", msg.sender())
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	for key, value := range msg.Headers {
+		m.SetHeader(key, value)
+	}
+
+	switch {
+	case msg.TextBody != "" && msg.HTMLBody != "":
+		m.SetBody("text/plain", msg.TextBody)
+		m.AddAlternative("text/html", msg.HTMLBody)
+	case msg.HTMLBody != "":
+		m.SetBody("text/html", msg.HTMLBody)
+	default:
+		m.SetBody("text/plain", msg.TextBody)
+	}
+
+	return m.WriteTo(w)
+}
+
+func (msg Message) sender() string {
+	if msg.From != "" {
+		return msg.From
+	}
+	return DefaultFrom()
+}
+
+func (msg Message) validate() error {
+	if msg.To == "" {
+		return errors.New("email has no recipient")
+	}
+	if msg.sender() == "" {
+		return errors.New("email has no sender, set MAIL_FROM")
+	}
+	return nil
+}
+
+// unknownMailer stands for an unsupported MAIL_DRIVER, so that a typo fails loudly
+type unknownMailer struct {
+	driver string
+}
+
+func (um *unknownMailer) Send(msg Message) error {
+	return errors.New("unknown MAIL_DRIVER " + um.driver)
+}
@@ -0,0 +1,41 @@
+package mailer
+
+import "sync"
+
+// MemoryMailer keeps the messages it is given, for tests and local runs
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (mm *MemoryMailer) Send(msg Message) error {
+	err := msg.validate()
+	if err != nil {
+		return err
+	}
+
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	mm.messages = append(mm.messages, msg)
+	return nil
+}
+
+// Messages returns a copy of the messages sent so far
+func (mm *MemoryMailer) Messages() []Message {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	return append([]Message(nil), mm.messages...)
+}
+
+func (mm *MemoryMailer) Reset() {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	mm.messages = nil
+}
@@ -0,0 +1,205 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"time"
+)
+
+// SMTP TLS modes
+const (
+	SMTPTLSModeStartTLS = "starttls"
+	SMTPTLSModeImplicit = "tls"
+	SMTPTLSModeNone     = "none"
+)
+
+// SMTPConfig is read from the MAIL_SMTP_* env variables
+type SMTPConfig struct {
+	Host               string
+	Port               int
+	Username           string
+	Password           string
+	TLSMode            string
+	InsecureSkipVerify bool
+	PoolSize           int
+	Timeout            time.Duration
+}
+
+func LoadSMTPConfig() SMTPConfig {
+	config := SMTPConfig{
+		Host:               os.Getenv("MAIL_SMTP_HOST"),
+		Username:           os.Getenv("MAIL_SMTP_USERNAME"),
+		Password:           os.Getenv("MAIL_SMTP_PASSWORD"),
+		TLSMode:            os.Getenv("MAIL_SMTP_TLS_MODE"),
+		InsecureSkipVerify: os.Getenv("MAIL_SMTP_INSECURE_SKIP_VERIFY") == "true",
+		Timeout:            10 * time.Second,
+	}
+
+	// ENGINE_EMAIL and ENGINE_APP_PASS are the former Gmail only settings
+	if config.Host == "" {
+		config.Host = "smtp.gmail.com"
+	}
+	if config.Username == "" {
+		config.Username = os.Getenv("ENGINE_EMAIL")
+	}
+	if config.Password == "" {
+		config.Password = os.Getenv("ENGINE_APP_PASS")
+	}
+	if config.TLSMode == "" {
+		config.TLSMode = SMTPTLSModeStartTLS
+	}
+
+	port, err := strconv.Atoi(os.Getenv("MAIL_SMTP_PORT"))
+	if err != nil || port <= 0 {
+		port = 587
+		if config.TLSMode == SMTPTLSModeImplicit {
+			port = 465
+		}
+	}
+	config.Port = port
+
+	poolSize, err := strconv.Atoi(os.Getenv("MAIL_SMTP_POOL_SIZE"))
+	if err != nil || poolSize < 0 {
+		poolSize = 2
+	}
+	config.PoolSize = poolSize
+
+	return config
+}
+
+// SMTPMailer keeps up to PoolSize idle connections open and reuses them,
+// a connection that fails is dropped and a new one is dialed next time
+type SMTPMailer struct {
+	Config SMTPConfig
+	pool   chan *smtp.Client
+}
+
+func NewSMTPMailer(config SMTPConfig) Mailer {
+	return &SMTPMailer{
+		Config: config,
+		pool:   make(chan *smtp.Client, config.PoolSize),
+	}
+}
+
+func (sm *SMTPMailer) Send(msg Message) error {
+	err := msg.validate()
+	if err != nil {
+		return err
+	}
+
+	client, err := sm.take()
+	if err != nil {
+		return err
+	}
+
+	err = sm.send(client, msg)
+	if err != nil {
+		client.Close()
+		return err
+	}
+
+	sm.put(client)
+	return nil
+}
+
+func (sm *SMTPMailer) send(client *smtp.Client, msg Message) error {
+	err := client.Mail(msg.sender())
+	if err != nil {
+		return err
+	}
+
+	err = client.Rcpt(msg.To)
+	if err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = msg.WriteTo(w)
+	if err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+// take returns a pooled connection that still answers, or dials a new one
+func (sm *SMTPMailer) take() (*smtp.Client, error) {
+	for {
+		select {
+		case client := <-sm.pool:
+			if client.Noop() == nil {
+				return client, nil
+			}
+			client.Close()
+		default:
+			return sm.dial()
+		}
+	}
+}
+
+func (sm *SMTPMailer) put(client *smtp.Client) {
+	select {
+	case sm.pool <- client:
+	default:
+		client.Quit()
+	}
+}
+
+func (sm *SMTPMailer) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(sm.Config.Host, strconv.Itoa(sm.Config.Port))
+	tlsConfig := &tls.Config{
+		ServerName:         sm.Config.Host,
+		InsecureSkipVerify: sm.Config.InsecureSkipVerify,
+	}
+
+	dialer := &net.Dialer{Timeout: sm.Config.Timeout}
+	var conn net.Conn
+	var err error
+	if sm.Config.TLSMode == SMTPTLSModeImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, sm.Config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if sm.Config.TLSMode == SMTPTLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+
+		err = client.StartTLS(tlsConfig)
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	if sm.Config.Username != "" {
+		// net/smtp refuses PLAIN auth without TLS, except to localhost
+		err = client.Auth(smtp.PlainAuth("", sm.Config.Username, sm.Config.Password, sm.Config.Host))
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrSuppressed is returned for a recipient on the suppression list, sending
+// again will not help
+var ErrSuppressed = errors.New("recipient is on the suppression list")
+
+// NormalizeAddress is the form of an address on the suppression list,
+// providers report bounces in whatever case the address was sent to
+func NormalizeAddress(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// SuppressionList tells whether an address bounced or complained before
+type SuppressionList interface {
+	IsSuppressed(email string) (bool, error)
+}
+
+// SuppressingMailer checks the suppression list before handing a message to
+// the next mailer
+type SuppressingMailer struct {
+	next Mailer
+	list SuppressionList
+}
+
+func NewSuppressingMailer(next Mailer, list SuppressionList) Mailer {
+	return &SuppressingMailer{
+		next: next,
+		list: list,
+	}
+}
+
+func (sm *SuppressingMailer) Send(msg Message) error {
+	suppressed, err := sm.list.IsSuppressed(msg.To)
+	if err != nil {
+		return err
+	}
+	if suppressed {
+		return ErrSuppressed
+	}
+
+	return sm.next.Send(msg)
+}
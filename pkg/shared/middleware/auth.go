@@ -3,13 +3,22 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"engine/internal/pkg/domains/interfaces"
 	jwt "engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
 )
 
-func CheckAuthentication() gin.HandlerFunc {
+// CheckAuthentication accepts a session JWT or a personal access token
+func CheckAuthentication(
+	sessionRepo interfaces.SessionRepository,
+	tokenUsecase interfaces.PersonalAccessTokenUsecase,
+	clientRepo interfaces.OAuthClientRepository,
+	revokedTokenRepo interfaces.OAuthRevokedTokenRepository,
+) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authorization := c.Request.Header.Get("Authorization")
 		if authorization == "" {
@@ -18,6 +27,11 @@ func CheckAuthentication() gin.HandlerFunc {
 			return
 		}
 		tokenReq := strings.Replace(authorization, "Bearer ", "", -1)
+		if strings.HasPrefix(tokenReq, constants.PersonalAccessTokenPrefix) {
+			checkPersonalAccessToken(c, tokenUsecase, tokenReq)
+			return
+		}
+
 		tokenReqParts := strings.Split(tokenReq, ".")
 		if len(tokenReqParts) != 3 {
 			c.JSON(http.StatusUnauthorized,
@@ -25,13 +39,220 @@ func CheckAuthentication() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		if !jwt.VerifyJWT(tokenReq) {
+		claims, err := jwt.ParseJWT(tokenReq)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized,
 				gin.H{"Message": "Token is invalid"})
 			c.Abort()
 			return
 		}
 
+		if subject, ok := claims["sub"].(string); ok && claims["typ"] == constants.TokenTypeAccess &&
+			strings.HasPrefix(subject, constants.ClientSubjectPrefix) {
+			checkClientToken(c, clientRepo, revokedTokenRepo, map[string]interface{}(claims))
+			return
+		}
+
+		sub, okSub := claims["sub"].(float64)
+		sid, okSid := claims["sid"].(float64)
+		if claims["typ"] != constants.TokenTypeAccess || !okSub || !okSid {
+			c.JSON(http.StatusUnauthorized,
+				gin.H{"Message": "Token is not an access token"})
+			c.Abort()
+			return
+		}
+
+		session, err := sessionRepo.TakeByConditions(map[string]interface{}{
+			"id":      uint(sid),
+			"user_id": uint(sub),
+		})
+		if err != nil || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+			c.JSON(http.StatusUnauthorized,
+				gin.H{"Message": "Session is revoked"})
+			c.Abort()
+			return
+		}
+
+		if time.Since(session.LastSeenAt) > constants.SessionTouchInterval {
+			_ = sessionRepo.UpdateSession(session, map[string]interface{}{
+				"last_seen_at": time.Now(),
+				"ip_address":   c.ClientIP(),
+			})
+		}
+
+		// tokens issued to OAuth clients carry the granted scope
+		if scope, ok := claims["scope"].(string); ok {
+			c.Set(constants.ContextScopesKey, strings.Fields(scope))
+		}
+
+		c.Set(constants.ContextClaimsKey, map[string]interface{}(claims))
+		c.Set(constants.ContextUserIDKey, uint(sub))
+		c.Set(constants.ContextSessionIDKey, uint(sid))
+
 		c.Next()
 	}
 }
+
+func checkPersonalAccessToken(c *gin.Context, tokenUsecase interfaces.PersonalAccessTokenUsecase, tokenReq string) {
+	token, user, err := tokenUsecase.AuthenticateToken(tokenReq)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized,
+			gin.H{"Message": err.Error()})
+		c.Abort()
+		return
+	}
+
+	c.Set(constants.ContextClaimsKey, map[string]interface{}{
+		"sub":   float64(user.ID),
+		"email": user.Email,
+		"role":  user.Role,
+		"scope": token.Scopes,
+	})
+	c.Set(constants.ContextUserIDKey, user.ID)
+	c.Set(constants.ContextScopesKey, strings.Fields(token.Scopes))
+
+	c.Next()
+}
+
+// checkClientToken accepts client_credentials tokens issued for the engine API
+// to a client that is still registered. There is no user behind those tokens.
+func checkClientToken(
+	c *gin.Context,
+	clientRepo interfaces.OAuthClientRepository,
+	revokedTokenRepo interfaces.OAuthRevokedTokenRepository,
+	claims map[string]interface{},
+) {
+	// an empty issuer would accept tokens without an audience
+	issuer := jwt.Issuer()
+	if aud, _ := claims["aud"].(string); issuer == "" || aud != issuer {
+		c.JSON(http.StatusUnauthorized,
+			gin.H{"Message": "Token audience is invalid"})
+		c.Abort()
+		return
+	}
+
+	jti, _ := claims["jti"].(string)
+	revoked, err := revokedTokenRepo.IsRevoked(jti)
+	if err != nil || revoked {
+		c.JSON(http.StatusUnauthorized,
+			gin.H{"Message": "Token is revoked"})
+		c.Abort()
+		return
+	}
+
+	subject, _ := claims["sub"].(string)
+	clientID := strings.TrimPrefix(subject, constants.ClientSubjectPrefix)
+	_, err = clientRepo.TakeByConditions(map[string]interface{}{
+		"client_id": clientID,
+	})
+	if err != nil {
+		c.JSON(http.StatusUnauthorized,
+			gin.H{"Message": "Client is not registered"})
+		c.Abort()
+		return
+	}
+
+	scope, _ := claims["scope"].(string)
+
+	c.Set(constants.ContextClaimsKey, claims)
+	c.Set(constants.ContextClientIDKey, clientID)
+	c.Set(constants.ContextScopesKey, strings.Fields(scope))
+
+	c.Next()
+}
+
+// RequireUser must be used after CheckAuthentication, it rejects client tokens
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetUint(constants.ContextUserIDKey) == 0 {
+			c.JSON(http.StatusForbidden,
+				gin.H{"Message": "A user token is required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession must be used after CheckAuthentication, it only lets through
+// first-party session tokens. Personal access tokens and tokens issued to
+// OAuth clients are rejected.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, scoped := c.Get(constants.ContextScopesKey)
+		if c.GetUint(constants.ContextSessionIDKey) == 0 || scoped {
+			c.JSON(http.StatusForbidden,
+				gin.H{"Message": "A session token is required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CheckRole must be used after CheckAuthentication, scoped tokens also need the admin scope
+func CheckRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if scopes, ok := c.Get(constants.ContextScopesKey); ok && !hasScope(scopes.([]string), constants.ScopeAdmin) {
+			c.JSON(http.StatusForbidden,
+				gin.H{"Message": "Permission denied"})
+			c.Abort()
+			return
+		}
+
+		claims := c.GetStringMap(constants.ContextClaimsKey)
+		role, _ := claims["role"].(string)
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden,
+			gin.H{"Message": "Permission denied"})
+		c.Abort()
+	}
+}
+
+// CheckScopes must be used after CheckAuthentication on the API routes, scoped
+// tokens need the read scope for safe methods and the write scope otherwise.
+// Tokens without a scope are first-party sessions and are not limited.
+func CheckScopes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get(constants.ContextScopesKey)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		scopes := value.([]string)
+		required := constants.ScopeWrite
+		if isSafeMethod(c.Request.Method) {
+			required = constants.ScopeRead
+		}
+		if !hasScope(scopes, required) && !hasScope(scopes, constants.ScopeAdmin) {
+			c.JSON(http.StatusForbidden,
+				gin.H{"Message": "Token scope does not allow this request"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"engine/internal/pkg/domains/interfaces"
+	"engine/internal/pkg/domains/models/dtos"
+	jwt "engine/pkg/shared/auth"
+	"engine/pkg/shared/constants"
+)
+
+// CheckSCIMToken accepts the provisioning token of an organization, the
+// organization it belongs to is the tenant of the SCIM request
+func CheckSCIMToken(scimTokenRepo interfaces.SCIMTokenRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenReq := strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+		if !strings.HasPrefix(tokenReq, constants.SCIMTokenPrefix) {
+			abortSCIM(c, http.StatusUnauthorized, "missing SCIM token")
+			return
+		}
+
+		token, err := scimTokenRepo.TakeByConditions(map[string]interface{}{
+			"token_hash": jwt.HashOpaqueToken(tokenReq),
+		})
+		if err != nil || token.RevokedAt != nil {
+			abortSCIM(c, http.StatusUnauthorized, "SCIM token is invalid")
+			return
+		}
+
+		if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > constants.SessionTouchInterval {
+			_ = scimTokenRepo.UpdateToken(token, map[string]interface{}{
+				"last_used_at": time.Now(),
+			})
+		}
+
+		c.Set(constants.ContextOrgIDKey, token.OrganizationID)
+
+		c.Next()
+	}
+}
+
+func abortSCIM(c *gin.Context, status int, detail string) {
+	c.Header("Content-Type", constants.SCIMContentType)
+	c.JSON(status, dtos.SCIMError{
+		Schemas: []string{constants.SCIMSchemaError},
+		Status:  strconv.Itoa(status),
+		Detail:  detail,
+	})
+	c.Abort()
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"engine/internal/pkg/domains/models/dtos"
+)
+
+// CheckWebhookSecret accepts requests carrying secret as bearer token. An
+// empty secret turns the webhook off.
+func CheckWebhookSecret(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenReq := strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+		if secret == "" || subtle.ConstantTimeCompare([]byte(tokenReq), []byte(secret)) != 1 {
+			c.JSON(http.StatusUnauthorized, dtos.BaseResponse{
+				Status: "failed",
+				Error: &dtos.ErrorResponse{
+					ErrorMessage: "invalid webhook secret",
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
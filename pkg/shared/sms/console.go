@@ -0,0 +1,28 @@
+package sms
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ConsoleSender prints messages instead of sending them
+type ConsoleSender struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+func NewConsoleSender(w io.Writer) SMSSender {
+	return &ConsoleSender{
+		w: w,
+	}
+}
+
+func (cs *ConsoleSender) Send(to string, body string) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	_, err := fmt.Fprintf(cs.w, "[SMS] %s to %s: %s\n", time.Now().Format(time.RFC3339), to, body)
+	return err
+}
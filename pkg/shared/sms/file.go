@@ -0,0 +1,54 @@
+package sms
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileSender appends every message to Path as a JSON line
+type FileSender struct {
+	Path string
+	mu   sync.Mutex
+}
+
+type fileMessage struct {
+	SentAt time.Time `json:"sent_at"`
+	To     string    `json:"to"`
+	Body   string    `json:"body"`
+}
+
+func NewFileSender(path string) SMSSender {
+	return &FileSender{
+		Path: path,
+	}
+}
+
+func (fs *FileSender) Send(to string, body string) error {
+	if fs.Path == "" {
+		return errors.New("SMS_FILE_PATH is not set")
+	}
+
+	line, err := json.Marshal(fileMessage{
+		SentAt: time.Now(),
+		To:     to,
+		Body:   body,
+	})
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	file, err := os.OpenFile(fs.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,72 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSender posts {"from","to","body"} to a provider API or to a relay that
+// translates it for the provider
+type HTTPSender struct {
+	URL    string
+	APIKey string
+	From   string
+	Client *http.Client
+}
+
+type httpSMSRequest struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+func NewHTTPSender(url string, apiKey string, from string) SMSSender {
+	return &HTTPSender{
+		URL:    url,
+		APIKey: apiKey,
+		From:   from,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (hs *HTTPSender) Send(to string, body string) error {
+	if hs.URL == "" {
+		return errors.New("SMS_HTTP_URL is not set")
+	}
+
+	payload, err := json.Marshal(httpSMSRequest{
+		From: hs.From,
+		To:   to,
+		Body: body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, hs.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hs.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+hs.APIKey)
+	}
+
+	resp, err := hs.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms api answered %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package sms
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+// SMSSender delivers text messages to E.164 numbers, implementations must be
+// safe for concurrent use
+type SMSSender interface {
+	Send(to string, body string) error
+}
+
+// ErrDisabled is returned by the sender of an empty SMS_DRIVER, phone
+// verification and the SMS second factor are then turned off
+var ErrDisabled = errors.New("SMS is not configured")
+
+var (
+	defaultSender SMSSender
+	defaultOnce   sync.Once
+)
+
+// Default returns the sender selected by SMS_DRIVER
+func Default() SMSSender {
+	defaultOnce.Do(func() {
+		defaultSender = NewFromEnv()
+	})
+
+	return defaultSender
+}
+
+// NewFromEnv builds the sender of SMS_DRIVER: console, file or http. console
+// and file are stand-ins for development, they never reach a phone. Without
+// SMS_DRIVER every send fails with ErrDisabled.
+func NewFromEnv() SMSSender {
+	switch driver := os.Getenv("SMS_DRIVER"); driver {
+	case "":
+		return disabledSender{}
+	case "console":
+		return NewConsoleSender(os.Stdout)
+	case "file":
+		return NewFileSender(os.Getenv("SMS_FILE_PATH"))
+	case "http":
+		return NewHTTPSender(os.Getenv("SMS_HTTP_URL"), os.Getenv("SMS_HTTP_API_KEY"), os.Getenv("SMS_FROM"))
+	default:
+		return &unknownSender{driver: driver}
+	}
+}
+
+// unknownSender stands for an unsupported SMS_DRIVER, so that a typo fails loudly
+type unknownSender struct {
+	driver string
+}
+
+func (us *unknownSender) Send(to string, body string) error {
+	return errors.New("unknown SMS_DRIVER " + us.driver)
+}
+
+type disabledSender struct{}
+
+func (disabledSender) Send(to string, body string) error {
+	return ErrDisabled
+}
+
+// Enabled tells whether sender can reach a phone, the features built on SMS
+// refuse to start without it rather than fail on the first code
+func Enabled(sender SMSSender) bool {
+	_, disabled := sender.(disabledSender)
+	return sender != nil && !disabled
+}
+
+// CheckConfig is called on startup. SMS is optional, but once SMS_DRIVER is
+// set it must work: a stand-in in production would silently drop every sign
+// in code, so console and file are only allowed when ENV is dev or local.
+func CheckConfig() error {
+	switch driver := os.Getenv("SMS_DRIVER"); driver {
+	case "":
+		return nil
+	case "console", "file":
+		if env := os.Getenv("ENV"); env != "dev" && env != "local" {
+			return errors.New("SMS_DRIVER " + driver + " is only allowed when ENV is dev or local")
+		}
+	case "http":
+		if os.Getenv("SMS_HTTP_URL") == "" {
+			return errors.New("SMS_HTTP_URL is required")
+		}
+	default:
+		return errors.New("unknown SMS_DRIVER " + driver)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package sms
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "SMS turned off", env: map[string]string{}},
+		{name: "http provider", env: map[string]string{"SMS_DRIVER": "http", "SMS_HTTP_URL": "https://sms.acme.test"}},
+		{name: "http without URL", env: map[string]string{"SMS_DRIVER": "http"}, wantErr: true},
+		{name: "console in development", env: map[string]string{"SMS_DRIVER": "console", "ENV": "dev"}},
+		{name: "console in production", env: map[string]string{"SMS_DRIVER": "console", "ENV": "production"}, wantErr: true},
+		{name: "file without ENV", env: map[string]string{"SMS_DRIVER": "file"}, wantErr: true},
+		{name: "unknown driver", env: map[string]string{"SMS_DRIVER": "carrier-pigeon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"SMS_DRIVER", "SMS_HTTP_URL", "ENV"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			err := CheckConfig()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDisabledSender(t *testing.T) {
+	t.Setenv("SMS_DRIVER", "")
+
+	sender := NewFromEnv()
+	if Enabled(sender) {
+		t.Errorf("Enabled() = true without SMS_DRIVER")
+	}
+	if err := sender.Send("+15550100", "123456"); !errors.Is(err, ErrDisabled) {
+		t.Errorf("Send() error = %v, want %v", err, ErrDisabled)
+	}
+	if !Enabled(NewConsoleSender(io.Discard)) {
+		t.Errorf("Enabled() = false for a console sender")
+	}
+}
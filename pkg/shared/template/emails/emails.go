@@ -0,0 +1,217 @@
+package emails
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"engine/pkg/shared/mailer"
+)
+
+// files holds the templates. Every email is made of locales/<locale>/<name>.html
+// and .txt, rendered inside layouts/base.* with the partials and the shared
+// strings of locales/<locale>/common.*
+//
+//go:embed layouts partials locales
+var files embed.FS
+
+// DefaultLocale is used when neither the user nor the request asks for a supported one
+const DefaultLocale = "en"
+
+// Locales are the translations shipped with the templates
+var Locales = []string{"en", "vi"}
+
+// ErrTemplateNotFound is returned for a name without template
+var ErrTemplateNotFound = errors.New("email template not found")
+
+var matcher = language.NewMatcher(localeTags())
+
+// Data is what the templates can use. Locale, AppName, BaseURL and SupportURL
+// are filled by Render.
+type Data struct {
+	Username  string
+	Email     string
+	Url       string
+	ExpiresIn time.Duration
+	Extra     map[string]string
+
+	Locale     string
+	AppName    string
+	BaseURL    string
+	SupportURL string
+}
+
+type compiled struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var (
+	cache   = map[string]compiled{}
+	cacheMu sync.Mutex
+)
+
+// Render executes the template name in locale into a message without
+// recipient. The subject comes from the "subject" block of the text template.
+func Render(name string, locale string, data Data) (mailer.Message, error) {
+	locale = ResolveLocale(locale)
+
+	t, err := load(name, locale)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	data.Locale = locale
+	if data.AppName == "" {
+		data.AppName = appName()
+	}
+	if data.BaseURL == "" {
+		data.BaseURL = os.Getenv("BASE_URL")
+	}
+	if data.SupportURL == "" {
+		data.SupportURL = os.Getenv("SUPPORT_URL")
+	}
+
+	var subject, text, html bytes.Buffer
+	err = t.text.ExecuteTemplate(&subject, "subject", data)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("email template %s/%s: %w", locale, name, err)
+	}
+	err = t.text.ExecuteTemplate(&text, "base.txt", data)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("email template %s/%s: %w", locale, name, err)
+	}
+	err = t.html.ExecuteTemplate(&html, "base.html", data)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("email template %s/%s: %w", locale, name, err)
+	}
+
+	return mailer.Message{
+		Subject:  strings.TrimSpace(subject.String()),
+		HTMLBody: html.String(),
+		TextBody: strings.TrimSpace(strings.ReplaceAll(text.String(), "\r\n", "\n")) + "\n",
+	}, nil
+}
+
+// MatchLocale returns the supported locale preferred by an Accept-Language
+// header, or "" when it asks for none of them
+func MatchLocale(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return ""
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return ""
+	}
+
+	return Locales[index]
+}
+
+// ResolveLocale returns the first supported of the candidates, for example
+// the preference of the user and then the locale of the request
+func ResolveLocale(candidates ...string) string {
+	for _, candidate := range candidates {
+		if locale := MatchLocale(candidate); locale != "" {
+			return locale
+		}
+	}
+
+	return DefaultLocale
+}
+
+// load parses the templates of name once per locale, a locale missing the
+// template falls back to DefaultLocale
+func load(name string, locale string) (compiled, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	key := locale + "/" + name
+	if t, ok := cache[key]; ok {
+		return t, nil
+	}
+
+	if name == "" || name == "common" || strings.ContainsAny(name, "/.") {
+		return compiled{}, ErrTemplateNotFound
+	}
+	if _, err := fs.Stat(files, "locales/"+locale+"/"+name+".html"); err != nil {
+		if locale == DefaultLocale {
+			return compiled{}, ErrTemplateNotFound
+		}
+		locale = DefaultLocale
+	}
+
+	dir := "locales/" + locale + "/"
+	funcs := map[string]interface{}{
+		"duration": func(d time.Duration) string {
+			return formatDuration(locale, d)
+		},
+	}
+
+	html, err := htmltemplate.New("").Funcs(funcs).Option("missingkey=error").ParseFS(files,
+		"layouts/base.html", "partials/*.html", dir+"common.html", dir+name+".html")
+	if err != nil {
+		return compiled{}, err
+	}
+
+	text, err := texttemplate.New("").Funcs(funcs).Option("missingkey=error").ParseFS(files,
+		"layouts/base.txt", dir+"common.txt", dir+name+".txt")
+	if err != nil {
+		return compiled{}, err
+	}
+
+	t := compiled{html: html, text: text}
+	cache[key] = t
+
+	return t, nil
+}
+
+// durationUnits are the singular and plural of day, hour and minute per locale
+var durationUnits = map[string][3][2]string{
+	"en": {{"day", "days"}, {"hour", "hours"}, {"minute", "minutes"}},
+	"vi": {{"ngày", "ngày"}, {"giờ", "giờ"}, {"phút", "phút"}},
+}
+
+// formatDuration writes d in its largest whole unit, e.g. "7 days" or "15 minutes"
+func formatDuration(locale string, d time.Duration) string {
+	units := durationUnits[locale]
+
+	count, unit := int(d/time.Minute), units[2]
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		count, unit = int(d/(24*time.Hour)), units[0]
+	case d >= time.Hour && d%time.Hour == 0:
+		count, unit = int(d/time.Hour), units[1]
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, unit[0])
+	}
+	return fmt.Sprintf("%d %s", count, unit[1])
+}
+
+func localeTags() []language.Tag {
+	tags := make([]language.Tag, 0, len(Locales))
+	for _, locale := range Locales {
+		tags = append(tags, language.MustParse(locale))
+	}
+	return tags
+}
+
+func appName() string {
+	if name := os.Getenv("APP_NAME"); name != "" {
+		return name
+	}
+	return "Engine"
+}
@@ -0,0 +1,129 @@
+package emails
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/mailer"
+)
+
+// fixtures are the sample data of every template, used by the admin preview
+// and by cmd/email-templates. Extra must hold every key a template reads.
+var fixtures = map[string]Data{
+	constants.EmailTemplateVerifyEmail: {
+		Username:  "jane",
+		Email:     "jane@example.com",
+		Url:       "https://example.com/auth/verify-email/amFuZUBleGFtcGxlLmNvbQ==/token",
+		ExpiresIn: constants.VerifyEmailLinkTTL,
+	},
+	constants.EmailTemplateForgotPassword: {
+		Username:  "jane",
+		Email:     "jane@example.com",
+		Url:       "https://example.com/auth/reset-password/amFuZUBleGFtcGxlLmNvbQ==/token",
+		ExpiresIn: constants.ResetPasswordLinkTTL,
+	},
+	constants.EmailTemplatePasswordChanged: {
+		Username: "jane",
+		Email:    "jane@example.com",
+		Url:      "https://example.com/auth/forgot-password",
+	},
+	constants.EmailTemplateChangeEmail: {
+		Username:  "jane",
+		Email:     "jane.doe@example.com",
+		Url:       "https://example.com/auth/confirm-email-change/token",
+		ExpiresIn: constants.EmailChangeLinkTTL,
+	},
+	constants.EmailTemplateEmailChangeNotice: {
+		Username:  "jane",
+		Email:     "jane@example.com",
+		Url:       "https://example.com/auth/undo-email-change/token",
+		ExpiresIn: constants.EmailChangeUndoTTL,
+		Extra: map[string]string{
+			"NewEmail": "jane.doe@example.com",
+		},
+	},
+	constants.EmailTemplateNewDevice: {
+		Username:  "jane",
+		Email:     "jane@example.com",
+		Url:       "https://example.com/auth/not-me/token",
+		ExpiresIn: constants.NotMeLinkTTL,
+		Extra: map[string]string{
+			"Time":      time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC).Format(constants.DateTimeFormat) + " UTC",
+			"UserAgent": "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36",
+			"Network":   "203.0.113.0/24",
+		},
+	},
+}
+
+// Names lists the templates of DefaultLocale
+func Names() []string {
+	paths, _ := fs.Glob(files, "locales/"+DefaultLocale+"/*.html")
+
+	names := make([]string, 0, len(paths))
+	for _, p := range paths {
+		name := strings.TrimSuffix(path.Base(p), ".html")
+		if name != "common" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Preview renders name in locale with its fixture data
+func Preview(name string, locale string) (mailer.Message, error) {
+	data, ok := fixtures[name]
+	if !ok {
+		return mailer.Message{}, ErrTemplateNotFound
+	}
+
+	// the support footer is optional, the preview always shows it
+	if data.SupportURL = os.Getenv("SUPPORT_URL"); data.SupportURL == "" {
+		data.SupportURL = "https://example.com/support"
+	}
+
+	msg, err := Render(name, locale, data)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+	msg.To = data.Email
+
+	return msg, nil
+}
+
+// Check renders every template in every locale with its fixture and reports
+// all problems: missing translations, templates without fixture, unknown
+// variables and missing Extra keys
+func Check() error {
+	var errs []error
+	for _, name := range Names() {
+		if _, ok := fixtures[name]; !ok {
+			errs = append(errs, fmt.Errorf("%s: no fixture data", name))
+			continue
+		}
+
+		for _, locale := range Locales {
+			for _, ext := range []string{".html", ".txt"} {
+				_, err := fs.Stat(files, "locales/"+locale+"/"+name+ext)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s/%s%s: missing translation", locale, name, ext))
+				}
+			}
+
+			_, err := Preview(name, locale)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s/%s: %w", locale, name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strconv"
+
+	"engine/internal/pkg/domains/models/entities"
+	"engine/pkg/shared/avatar"
+	"engine/pkg/shared/blob"
+	"engine/pkg/shared/constants"
+)
+
+// AvatarURLs returns the signed URLs of the uploaded avatar of user and of its
+// thumbnails by size. Without upload it is the avatar URL of the profile.
+func AvatarURLs(user entities.User) (string, map[string]string) {
+	if user.AvatarKey == "" {
+		return user.AvatarURL, nil
+	}
+
+	store := blob.Default()
+	url, err := store.SignedURL(user.AvatarKey, constants.AvatarURLTTL)
+	if err != nil {
+		return user.AvatarURL, nil
+	}
+
+	thumbnails := map[string]string{}
+	for _, size := range avatar.Sizes[1:] {
+		thumbnail, err := store.SignedURL(avatar.SizeKey(user.AvatarKey, size), constants.AvatarURLTTL)
+		if err == nil {
+			thumbnails[strconv.Itoa(size)] = thumbnail
+		}
+	}
+
+	return url, thumbnails
+}
@@ -1,16 +1,257 @@
 package utils
 
 import (
+	"encoding/json"
+	"strings"
+
 	"engine/internal/pkg/domains/models/dtos"
 	"engine/internal/pkg/domains/models/entities"
 )
 
 // convertUserEntityToUserResponse func
 func ConvertUserEntityToUserResponse(user entities.User) dtos.UserResponse {
+	avatarURL, avatarThumbnails := AvatarURLs(user)
+
 	return dtos.UserResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		IsActive: user.IsActive,
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		IsActive:     user.IsActive,
+		Role:         user.Role,
+		IsSuspended:  user.IsSuspended,
+		Locale:       user.Locale,
+		EmailBounced: user.EmailBounced,
+
+		Phone:         user.Phone,
+		PhoneVerified: user.PhoneVerified,
+		SMSTwoFactor:  user.SMSTwoFactor,
+
+		DisplayName:      user.DisplayName,
+		AvatarURL:        avatarURL,
+		AvatarThumbnails: avatarThumbnails,
+		Timezone:         user.Timezone,
+		Metadata:         user.Metadata,
+	}
+}
+
+// ConvertUserEntitiesToUserResponses func
+func ConvertUserEntitiesToUserResponses(users []entities.User) []dtos.UserResponse {
+	res := make([]dtos.UserResponse, 0, len(users))
+	for _, user := range users {
+		res = append(res, ConvertUserEntityToUserResponse(user))
+	}
+	return res
+}
+
+// ConvertAuditEventEntityToResponse func
+func ConvertAuditEventEntityToResponse(event entities.AuditEvent) dtos.AuditEventResponse {
+	res := dtos.AuditEventResponse{
+		ID:        event.ID,
+		CreatedAt: event.CreatedAt,
+		ActorID:   event.ActorID,
+		TargetID:  event.TargetID,
+		Action:    event.Action,
+		IPAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+		Outcome:   event.Outcome,
+	}
+
+	if event.Metadata != "" {
+		_ = json.Unmarshal([]byte(event.Metadata), &res.Metadata)
+	}
+
+	return res
+}
+
+// ConvertAuditEventEntitiesToResponses func
+func ConvertAuditEventEntitiesToResponses(events []entities.AuditEvent) []dtos.AuditEventResponse {
+	res := make([]dtos.AuditEventResponse, 0, len(events))
+	for _, event := range events {
+		res = append(res, ConvertAuditEventEntityToResponse(event))
+	}
+	return res
+}
+
+// ConvertSessionEntitiesToResponses func, currentID marks the session of the caller
+func ConvertSessionEntitiesToResponses(sessions []entities.Session, currentID uint) []dtos.SessionResponse {
+	res := make([]dtos.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, dtos.SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentID,
+		})
+	}
+	return res
+}
+
+// ConvertPersonalAccessTokenEntityToResponse func
+func ConvertPersonalAccessTokenEntityToResponse(token entities.PersonalAccessToken) dtos.PersonalAccessTokenResponse {
+	return dtos.PersonalAccessTokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      strings.Fields(token.Scopes),
+		CreatedAt:   token.CreatedAt,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+	}
+}
+
+// ConvertPersonalAccessTokenEntitiesToResponses func
+func ConvertPersonalAccessTokenEntitiesToResponses(tokens []entities.PersonalAccessToken) []dtos.PersonalAccessTokenResponse {
+	res := make([]dtos.PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		res = append(res, ConvertPersonalAccessTokenEntityToResponse(token))
+	}
+	return res
+}
+
+// ConvertOAuthClientEntityToResponse func
+func ConvertOAuthClientEntityToResponse(client entities.OAuthClient) dtos.OAuthClientResponse {
+	return dtos.OAuthClientResponse{
+		ID:                     client.ID,
+		ClientID:               client.ClientID,
+		Name:                   client.Name,
+		RedirectURIs:           strings.Fields(client.RedirectURIs),
+		PostLogoutRedirectURIs: strings.Fields(client.PostLogoutRedirectURIs),
+		Audiences:              strings.Fields(client.Audiences),
+		Scopes:                 strings.Fields(client.Scopes),
+		IsConfidential:         client.IsConfidential,
+		CreatedAt:              client.CreatedAt,
+	}
+}
+
+// ConvertOAuthClientEntitiesToResponses func
+func ConvertOAuthClientEntitiesToResponses(clients []entities.OAuthClient) []dtos.OAuthClientResponse {
+	res := make([]dtos.OAuthClientResponse, 0, len(clients))
+	for _, client := range clients {
+		res = append(res, ConvertOAuthClientEntityToResponse(client))
+	}
+	return res
+}
+
+// ConvertOrganizationEntityToResponse func
+func ConvertOrganizationEntityToResponse(org entities.Organization) dtos.OrganizationResponse {
+	return dtos.OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		Slug:      org.Slug,
+		CreatedAt: org.CreatedAt,
+	}
+}
+
+// ConvertOrganizationEntitiesToResponses func
+func ConvertOrganizationEntitiesToResponses(orgs []entities.Organization) []dtos.OrganizationResponse {
+	res := make([]dtos.OrganizationResponse, 0, len(orgs))
+	for _, org := range orgs {
+		res = append(res, ConvertOrganizationEntityToResponse(org))
+	}
+	return res
+}
+
+// ConvertOrganizationAttributeEntityToResponse func
+func ConvertOrganizationAttributeEntityToResponse(attr entities.OrganizationAttribute) dtos.OrganizationAttributeResponse {
+	return dtos.OrganizationAttributeResponse{
+		ID:        attr.ID,
+		Name:      attr.Name,
+		Type:      attr.Type,
+		Required:  attr.Required,
+		Options:   attr.Options,
+		MaxLength: attr.MaxLength,
+		CreatedAt: attr.CreatedAt,
+	}
+}
+
+// ConvertOrganizationAttributeEntitiesToResponses func
+func ConvertOrganizationAttributeEntitiesToResponses(attrs []entities.OrganizationAttribute) []dtos.OrganizationAttributeResponse {
+	res := make([]dtos.OrganizationAttributeResponse, 0, len(attrs))
+	for _, attr := range attrs {
+		res = append(res, ConvertOrganizationAttributeEntityToResponse(attr))
+	}
+	return res
+}
+
+// ConvertSAMLConnectionEntityToResponse func
+func ConvertSAMLConnectionEntityToResponse(conn entities.SAMLConnection) dtos.SAMLConnectionResponse {
+	return dtos.SAMLConnectionResponse{
+		OrganizationID:    conn.OrganizationID,
+		SSOBinding:        conn.SSOBinding,
+		EmailAttribute:    conn.EmailAttribute,
+		UsernameAttribute: conn.UsernameAttribute,
+		JITProvisioning:   conn.JITProvisioning,
+		DefaultRole:       conn.DefaultRole,
+		IsEnabled:         conn.IsEnabled,
+	}
+}
+
+// ConvertSCIMTokenEntityToResponse func
+func ConvertSCIMTokenEntityToResponse(token entities.SCIMToken) dtos.SCIMTokenResponse {
+	return dtos.SCIMTokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		CreatedAt:   token.CreatedAt,
+		LastUsedAt:  token.LastUsedAt,
+	}
+}
+
+// ConvertSCIMTokenEntitiesToResponses func
+func ConvertSCIMTokenEntitiesToResponses(tokens []entities.SCIMToken) []dtos.SCIMTokenResponse {
+	res := make([]dtos.SCIMTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		res = append(res, ConvertSCIMTokenEntityToResponse(token))
+	}
+	return res
+}
+
+// ConvertEmailOutboxEntityToResponse func
+func ConvertEmailOutboxEntityToResponse(email entities.EmailOutbox) dtos.EmailOutboxResponse {
+	return dtos.EmailOutboxResponse{
+		ID:            email.ID,
+		Recipient:     email.Recipient,
+		Subject:       email.Subject,
+		Status:        email.Status,
+		Attempts:      email.Attempts,
+		NextAttemptAt: email.NextAttemptAt,
+		LastError:     email.LastError,
+		SentAt:        email.SentAt,
+		CreatedAt:     email.CreatedAt,
+	}
+}
+
+// ConvertEmailSuppressionEntityToResponse func
+func ConvertEmailSuppressionEntityToResponse(suppression entities.EmailSuppression) dtos.EmailSuppressionResponse {
+	return dtos.EmailSuppressionResponse{
+		ID:        suppression.ID,
+		Email:     suppression.Email,
+		Reason:    suppression.Reason,
+		Detail:    suppression.Detail,
+		Source:    suppression.Source,
+		CreatedAt: suppression.CreatedAt,
+		UpdatedAt: suppression.UpdatedAt,
+	}
+}
+
+// ConvertEmailSuppressionEntitiesToResponses func
+func ConvertEmailSuppressionEntitiesToResponses(suppressions []entities.EmailSuppression) []dtos.EmailSuppressionResponse {
+	res := make([]dtos.EmailSuppressionResponse, 0, len(suppressions))
+	for _, suppression := range suppressions {
+		res = append(res, ConvertEmailSuppressionEntityToResponse(suppression))
+	}
+	return res
+}
+
+// ConvertEmailOutboxEntitiesToResponses func
+func ConvertEmailOutboxEntitiesToResponses(emails []entities.EmailOutbox) []dtos.EmailOutboxResponse {
+	res := make([]dtos.EmailOutboxResponse, 0, len(emails))
+	for _, email := range emails {
+		res = append(res, ConvertEmailOutboxEntityToResponse(email))
 	}
+	return res
 }
@@ -1,57 +1,48 @@
 package utils
 
 import (
-	"bytes"
-	"crypto/tls"
-	"html/template"
-	"os"
+	"time"
 
-	"gopkg.in/gomail.v2"
+	"engine/pkg/shared/mailer"
+	"engine/pkg/shared/template/emails"
 )
 
 type TemplateData struct {
-	Path    string
-	Name    string
-	To      string
-	Subject string
-	Url     string
+	// Template is the name of the email in pkg/shared/template/emails
+	Template  string
+	Locale    string
+	To        string
+	Username  string
+	Email     string
+	Url       string
+	ExpiresIn time.Duration
+	Extra     map[string]string
 }
 
-func SendTemplateEMail(templateData TemplateData) error {
-	var body bytes.Buffer
-	t, err := template.ParseFiles(templateData.Path)
-	// t, err := template.ParseFiles("pkg/shared/template/1.html")
-
+// RenderTemplateEmail renders the HTML and text parts of templateData into a message
+func RenderTemplateEmail(templateData TemplateData) (mailer.Message, error) {
+	msg, err := emails.Render(templateData.Template, templateData.Locale, emails.Data{
+		Username:  templateData.Username,
+		Email:     templateData.Email,
+		Url:       templateData.Url,
+		ExpiresIn: templateData.ExpiresIn,
+		Extra:     templateData.Extra,
+	})
 	if err != nil {
-		return err
+		return mailer.Message{}, err
 	}
 
-	t.Execute(&body, TemplateData{
-		Url: templateData.Url,
-	})
-
-	m := gomail.NewMessage()
-	// Set E-Mail sender
-	m.SetHeader("From", os.Getenv("ENGINE_EMAIL"))
-
-	// Set E-Mail receivers
-	m.SetHeader("To", templateData.To)
-
-	// Set E-Mail subject
-	m.SetHeader("Subject", templateData.Subject)
+	msg.To = templateData.To
 
-	// Set E-Mail body. You can set plain text or html with text/html
-	m.SetBody("text/html", body.String())
-
-	// Settings for SMTP server
-	d := gomail.NewDialer("smtp.gmail.com", 587, os.Getenv("ENGINE_EMAIL"), os.Getenv("ENGINE_APP_PASS"))
-
-	// This is only needed when SSL/TLS certificate is not valid on server.
-	// In production this should be set to false.
-	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	return msg, nil
+}
 
-	// Now send E-Mail
-	err = d.DialAndSend(m)
+// SendTemplateEmail renders templateData and hands it to the mailer
+func SendTemplateEmail(m mailer.Mailer, templateData TemplateData) error {
+	msg, err := RenderTemplateEmail(templateData)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return m.Send(msg)
 }
@@ -1,6 +1,76 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordPolicy is read from the PASSWORD_* env variables
+type PasswordPolicy struct {
+	MinLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSpecial bool
+}
+
+func LoadPasswordPolicy() PasswordPolicy {
+	minLength, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH"))
+	if err != nil || minLength <= 0 {
+		minLength = 8
+	}
+
+	return PasswordPolicy{
+		MinLength:      minLength,
+		RequireUpper:   os.Getenv("PASSWORD_REQUIRE_UPPER") == "true",
+		RequireLower:   os.Getenv("PASSWORD_REQUIRE_LOWER") == "true",
+		RequireDigit:   os.Getenv("PASSWORD_REQUIRE_DIGIT") == "true",
+		RequireSpecial: os.Getenv("PASSWORD_REQUIRE_SPECIAL") == "true",
+	}
+}
+
+// ValidatePassword checks the password against the configured policy
+func ValidatePassword(password string) error {
+	policy := LoadPasswordPolicy()
+
+	if len([]rune(password)) < policy.MinLength {
+		return fmt.Errorf("password must be at least %d characters", policy.MinLength)
+	}
+
+	hasUpper, hasLower, hasDigit, hasSpecial := false, false, false, false
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+
+	if policy.RequireUpper && !hasUpper {
+		return errors.New("password must contain an uppercase letter")
+	}
+	if policy.RequireLower && !hasLower {
+		return errors.New("password must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		return errors.New("password must contain a digit")
+	}
+	if policy.RequireSpecial && !hasSpecial {
+		return errors.New("password must contain a special character")
+	}
+
+	return nil
+}
 
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
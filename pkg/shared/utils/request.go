@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"engine/internal/pkg/domains/models/dtos"
+	"engine/pkg/shared/constants"
+	"engine/pkg/shared/template/emails"
+)
+
+// GetClientInfo collects request metadata used for auditing
+func GetClientInfo(c *gin.Context) dtos.ClientInfo {
+	client := dtos.ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Locale:    emails.MatchLocale(c.GetHeader("Accept-Language")),
+	}
+
+	if userID, ok := c.Get(constants.ContextUserIDKey); ok {
+		actorID := userID.(uint)
+		client.ActorID = &actorID
+	}
+
+	return client
+}